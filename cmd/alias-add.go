@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/spf13/cobra"
 	admin "google.golang.org/api/admin/directory/v1"
@@ -51,13 +50,13 @@ Requirements:
 
 func init() {
 	aliasCmd.AddCommand(aliasAddCmd)
+	requireScopes(aliasAddCmd, admin.AdminDirectoryUserAliasScope)
 }
 
 func aliasAddRunFunc(cmd *cobra.Command, args []string) error {
-	client, err := newAdminClient()
+	client, err := newDirectoryClient()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating client: %v\n", err)
-		return err
+		return fmt.Errorf("failed to create client: %w", err)
 	}
 
 	userEmail := args[0]
@@ -65,13 +64,11 @@ func aliasAddRunFunc(cmd *cobra.Command, args []string) error {
 
 	// Validate email formats
 	if err := ValidateEmail(userEmail); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Invalid user email format: %v\n", err)
-		return err
+		return invalidInputf("invalid user email format: %w", err)
 	}
 
 	if err := ValidateEmail(aliasEmail); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Invalid alias email format: %v\n", err)
-		return err
+		return invalidInputf("invalid alias email format: %w", err)
 	}
 
 	// Create the alias
@@ -79,15 +76,15 @@ func aliasAddRunFunc(cmd *cobra.Command, args []string) error {
 		Alias: aliasEmail,
 	}
 
-	result, err := client.Users.Aliases.Insert(userEmail, alias).Do()
+	result, err := client.InsertAlias(apiContext(), userEmail, alias)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error adding alias: %v\n", err)
-		fmt.Fprintf(os.Stderr, "\nCommon reasons for failure:\n")
-		fmt.Fprintf(os.Stderr, "  - User does not exist\n")
-		fmt.Fprintf(os.Stderr, "  - Alias already exists for another user or group\n")
-		fmt.Fprintf(os.Stderr, "  - Alias domain is not managed by your organization\n")
-		fmt.Fprintf(os.Stderr, "  - Insufficient permissions\n")
-		return err
+		return withHint(fmt.Errorf("failed to add alias: %w", err),
+			failureReasons(
+				"User does not exist",
+				"Alias already exists for another user or group",
+				"Alias domain is not managed by your organization",
+				"Insufficient permissions",
+			))
 	}
 
 	fmt.Printf("Successfully added alias:\n\n")
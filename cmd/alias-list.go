@@ -2,9 +2,9 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/spf13/cobra"
+	admin "google.golang.org/api/admin/directory/v1"
 )
 
 // aliasListCmd represents the alias list command
@@ -44,32 +44,31 @@ The output shows:
 
 func init() {
 	aliasCmd.AddCommand(aliasListCmd)
+	requireScopes(aliasListCmd, admin.AdminDirectoryUserAliasReadonlyScope)
 }
 
 func aliasListRunFunc(cmd *cobra.Command, args []string) error {
-	client, err := newAdminClient()
+	client, err := newDirectoryClient()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating client: %v\n", err)
-		return err
+		return fmt.Errorf("failed to create client: %w", err)
 	}
 
 	userEmail := args[0]
 
 	// Validate email format
 	if err := ValidateEmail(userEmail); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Invalid email format: %v\n", err)
-		return err
+		return invalidInputf("invalid email format: %w", err)
 	}
 
 	// List aliases for the user
-	result, err := client.Users.Aliases.List(userEmail).Do()
+	result, err := client.ListAliases(apiContext(), userEmail)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error listing aliases: %v\n", err)
-		fmt.Fprintf(os.Stderr, "\nCommon reasons for failure:\n")
-		fmt.Fprintf(os.Stderr, "  - User does not exist\n")
-		fmt.Fprintf(os.Stderr, "  - Insufficient permissions\n")
-		fmt.Fprintf(os.Stderr, "  - Invalid user email\n")
-		return err
+		return withHint(fmt.Errorf("failed to list aliases: %w", err),
+			failureReasons(
+				"User does not exist",
+				"Insufficient permissions",
+				"Invalid user email",
+			))
 	}
 
 	// Display results
@@ -2,9 +2,9 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/spf13/cobra"
+	admin "google.golang.org/api/admin/directory/v1"
 )
 
 var (
@@ -48,14 +48,14 @@ this user. Make sure this is intentional before proceeding.
 
 func init() {
 	aliasCmd.AddCommand(aliasRemoveCmd)
+	requireScopes(aliasRemoveCmd, admin.AdminDirectoryUserAliasScope)
 	aliasRemoveCmd.Flags().BoolVarP(&aliasRemoveForce, "force", "f", false, "skip confirmation prompt")
 }
 
 func aliasRemoveRunFunc(cmd *cobra.Command, args []string) error {
-	client, err := newAdminClient()
+	client, err := newDirectoryClient()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating client: %v\n", err)
-		return err
+		return fmt.Errorf("failed to create client: %w", err)
 	}
 
 	userEmail := args[0]
@@ -63,13 +63,11 @@ func aliasRemoveRunFunc(cmd *cobra.Command, args []string) error {
 
 	// Validate email formats
 	if err := ValidateEmail(userEmail); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Invalid user email format: %v\n", err)
-		return err
+		return invalidInputf("invalid user email format: %w", err)
 	}
 
 	if err := ValidateEmail(aliasEmail); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Invalid alias email format: %v\n", err)
-		return err
+		return invalidInputf("invalid alias email format: %w", err)
 	}
 
 	// Show warning and prompt for confirmation unless --force or --yes is used
@@ -79,15 +77,15 @@ func aliasRemoveRunFunc(cmd *cobra.Command, args []string) error {
 	}
 
 	// Remove the alias
-	err = client.Users.Aliases.Delete(userEmail, aliasEmail).Do()
+	err = client.DeleteAlias(apiContext(), userEmail, aliasEmail)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error removing alias: %v\n", err)
-		fmt.Fprintf(os.Stderr, "\nCommon reasons for failure:\n")
-		fmt.Fprintf(os.Stderr, "  - User does not exist\n")
-		fmt.Fprintf(os.Stderr, "  - Alias does not exist for this user\n")
-		fmt.Fprintf(os.Stderr, "  - Alias email is incorrect\n")
-		fmt.Fprintf(os.Stderr, "  - Insufficient permissions\n")
-		return err
+		return withHint(fmt.Errorf("failed to remove alias: %w", err),
+			failureReasons(
+				"User does not exist",
+				"Alias does not exist for this user",
+				"Alias email is incorrect",
+				"Insufficient permissions",
+			))
 	}
 
 	fmt.Printf("Successfully removed alias:\n")
@@ -4,10 +4,11 @@ import (
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
-	"os"
+	"iter"
 	"strings"
 	"time"
 
+	"github.com/acockrell/google-admin-client/pkg/gac"
 	"github.com/spf13/cobra"
 	reports "google.golang.org/api/admin/reports/v1"
 )
@@ -20,28 +21,10 @@ var (
 	userEmail         string
 	eventNames        []string
 	auditOutputFormat string
-	outputFile        string
 	maxResults        int64
 	actorIPAddr       string
 )
 
-// validAppTypes lists all supported application types for audit logs
-var validAppTypes = map[string]bool{
-	"admin":             true,
-	"login":             true,
-	"drive":             true,
-	"calendar":          true,
-	"groups":            true,
-	"mobile":            true,
-	"token":             true,
-	"groups_enterprise": true,
-	"saml":              true,
-	"chrome":            true,
-	"gcp":               true,
-	"chat":              true,
-	"meet":              true,
-}
-
 // auditExportCmd represents the audit export command
 var auditExportCmd = &cobra.Command{
 	Use:   "export",
@@ -49,7 +32,7 @@ var auditExportCmd = &cobra.Command{
 	Long: `Export Google Workspace audit logs for a specific application.
 
 The export command retrieves audit logs from the Google Admin Reports API and outputs them
-in JSON or CSV format. You can filter by time range, user, event type, and more.
+in JSON, NDJSON or CSV format. You can filter by time range, user, event type, and more.
 
 Application Types:
   admin              Admin console activities (user creation, settings changes, etc.)
@@ -73,6 +56,7 @@ Time Range:
 
 Output Formats:
   json               Full event details in JSON format (default)
+  ndjson             Full event details, one JSON object per line, written as pages arrive
   csv                Tabular format with key fields
 
 Examples:
@@ -90,17 +74,21 @@ Examples:
   # Export to CSV file
   gac audit export --app admin --output csv --output-file admin-audit.csv
 
+  # Stream a large export as newline-delimited JSON
+  gac audit export --app drive --output ndjson --output-file drive-audit.ndjson
+
   # Filter by specific event types
   gac audit export --app admin --event-name USER_CREATED --event-name GROUP_CREATED
 
   # Filter by IP address
   gac audit export --app login --actor-ip 192.168.1.100
 `,
-	Run: auditExportRunFunc,
+	RunE: auditExportRunFunc,
 }
 
 func init() {
 	auditCmd.AddCommand(auditExportCmd)
+	requireScopes(auditExportCmd, reports.AdminReportsAuditReadonlyScope)
 
 	// Required flags
 	auditExportCmd.Flags().StringVar(&appType, "app", "", "application type (required: admin, login, drive, calendar, groups, mobile, token, etc.)")
@@ -118,8 +106,9 @@ func init() {
 	auditExportCmd.Flags().StringVar(&actorIPAddr, "actor-ip", "", "filter by actor IP address")
 
 	// Output flags
-	auditExportCmd.Flags().StringVarP(&auditOutputFormat, "output", "o", "json", "output format (json, csv)")
-	auditExportCmd.Flags().StringVarP(&outputFile, "output-file", "f", "", "output file path (default: stdout)")
+	auditExportCmd.Flags().StringVarP(&auditOutputFormat, "output", "o", "json", "output format (json, ndjson, csv)")
+	// Shadows the global --output-file to keep the -f shorthand
+	auditExportCmd.Flags().StringVarP(&outputFileFlag, "output-file", "f", "", "output file path, gzip-compressed if it ends in .gz (default: stdout)")
 	auditExportCmd.Flags().Int64Var(&maxResults, "max-results", 0, "maximum number of results to return (default: all)")
 }
 
@@ -157,28 +146,12 @@ func parseTimeRange() (string, string, error) {
 }
 
 // writeJSONOutput writes activities to JSON format
-func writeJSONOutput(activities []*reports.Activity, filename string) error {
-	var output *os.File
-	var err error
-
-	if filename == "" {
-		output = os.Stdout
-	} else {
-		// Validate output file path to prevent directory traversal
-		if err := validateCredentialPath(filename); err != nil {
-			return fmt.Errorf("invalid output file path: %w", err)
-		}
+func writeJSONOutput(activities []*reports.Activity) error {
+	output := outputWriter()
 
-		// #nosec G304 - Path is validated by validateCredentialPath() above
-		output, err = os.Create(filename)
-		if err != nil {
-			return fmt.Errorf("failed to create output file: %w", err)
-		}
-		defer func() {
-			if cerr := output.Close(); cerr != nil && err == nil {
-				err = fmt.Errorf("failed to close output file: %w", cerr)
-			}
-		}()
+	// --fields and --query reshape activities like any other output
+	if hasOutputSelection() {
+		return formatOutputAs(output, OutputFormatJSON, activities, nil)
 	}
 
 	encoder := json.NewEncoder(output)
@@ -191,33 +164,21 @@ func writeJSONOutput(activities []*reports.Activity, filename string) error {
 	return nil
 }
 
-// writeCSVOutput writes activities to CSV format
-func writeCSVOutput(activities []*reports.Activity, filename string) error {
-	var output *os.File
-	var err error
+// writeNDJSONOutput streams activities as NDJSON, one activity per line,
+// and returns the number written
+func writeNDJSONOutput(activities iter.Seq2[*reports.Activity, error]) (int, error) {
+	return streamNDJSON(outputWriter(), activities, "activities")
+}
 
-	if filename == "" {
-		output = os.Stdout
-	} else {
-		// Validate output file path to prevent directory traversal
-		if err := validateCredentialPath(filename); err != nil {
-			return fmt.Errorf("invalid output file path: %w", err)
-		}
+// writeCSVOutput writes activities to CSV format
+func writeCSVOutput(activities []*reports.Activity) error {
+	output := outputWriter()
 
-		// #nosec G304 - Path is validated by validateCredentialPath() above
-		output, err = os.Create(filename)
-		if err != nil {
-			return fmt.Errorf("failed to create output file: %w", err)
-		}
-		defer func() {
-			if cerr := output.Close(); cerr != nil && err == nil {
-				err = fmt.Errorf("failed to close output file: %w", cerr)
-			}
-		}()
+	if hasOutputSelection() {
+		return formatOutputAs(output, OutputFormatCSV, activities, nil)
 	}
 
 	writer := csv.NewWriter(output)
-	defer writer.Flush()
 
 	// Write CSV header
 	header := []string{"Timestamp", "Actor", "Event", "IP Address", "Application"}
@@ -261,34 +222,32 @@ func writeCSVOutput(activities []*reports.Activity, filename string) error {
 		}
 	}
 
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
 	return nil
 }
 
-func auditExportRunFunc(cmd *cobra.Command, args []string) {
+func auditExportRunFunc(cmd *cobra.Command, args []string) error {
 	// Validate application type
 	appType = strings.ToLower(appType)
-	if !validAppTypes[appType] {
-		Logger.Error().
-			Str("app", appType).
-			Msg("Invalid application type")
-		Logger.Info().Msg("Valid application types: admin, login, drive, calendar, groups, mobile, token, groups_enterprise, saml, chrome, gcp, chat, meet")
-		os.Exit(1)
+	if !gac.ValidApplication(appType) {
+		return invalidInputf("invalid application type %q (valid: %s)", appType, strings.Join(gac.Applications, ", "))
 	}
 
 	// Validate output format
 	auditOutputFormat = strings.ToLower(auditOutputFormat)
-	if auditOutputFormat != "json" && auditOutputFormat != "csv" {
-		Logger.Error().
-			Str("format", auditOutputFormat).
-			Msg("Invalid output format (must be json or csv)")
-		os.Exit(1)
+	switch auditOutputFormat {
+	case "json", "ndjson", "csv":
+	default:
+		return invalidInputf("invalid output format %q (must be json, ndjson or csv)", auditOutputFormat)
 	}
 
 	// Parse and validate time range
 	startTimeStr, endTimeStr, err := parseTimeRange()
 	if err != nil {
-		Logger.Error().Err(err).Msg("Failed to parse time range")
-		os.Exit(1)
+		return invalidInputf("failed to parse time range: %w", err)
 	}
 
 	Logger.Info().
@@ -298,113 +257,101 @@ func auditExportRunFunc(cmd *cobra.Command, args []string) {
 		Msg("Exporting audit logs")
 
 	// Initialize Reports API client
-	client, err := newReportsClient()
+	client, err := newAuditClient()
 	if err != nil {
-		Logger.Fatal().Err(err).Msg("Failed to initialize reports client")
+		return fmt.Errorf("failed to initialize reports client: %w", err)
+	}
+
+	// parseTimeRange has validated both times
+	start, _ := time.Parse(time.RFC3339, startTimeStr)
+	end, _ := time.Parse(time.RFC3339, endTimeStr)
+	query := gac.ActivityQuery{
+		Application: appType,
+		User:        userEmail,
+		StartTime:   start,
+		EndTime:     end,
+		EventNames:  eventNames,
+		ActorIP:     actorIPAddr,
+		MaxResults:  maxResults,
 	}
 
-	// Build the API call
-	// For Reports API, userKey can be "all" or a specific user email
-	userKey := "all"
 	if userEmail != "" {
-		userKey = userEmail
 		Logger.Debug().Str("user", userEmail).Msg("Filtering by user")
 	}
-
-	call := client.Activities.List(userKey, appType).
-		StartTime(startTimeStr).
-		EndTime(endTimeStr)
-
-	// Apply additional filters
-
 	if actorIPAddr != "" {
-		call = call.ActorIpAddress(actorIPAddr)
 		Logger.Debug().Str("ip", actorIPAddr).Msg("Filtering by IP address")
 	}
-
 	if len(eventNames) > 0 {
-		call = call.EventName(strings.Join(eventNames, ","))
 		Logger.Debug().Strs("events", eventNames).Msg("Filtering by event names")
 	}
-
 	if maxResults > 0 {
-		call = call.MaxResults(maxResults)
 		Logger.Debug().Int64("max_results", maxResults).Msg("Limiting results")
 	}
 
-	// Fetch activities with pagination
-	var activities []*reports.Activity
-	pageToken := ""
-	pageCount := 0
-
-	for {
-		if pageToken != "" {
-			call = call.PageToken(pageToken)
-		}
-
-		Logger.Debug().
-			Int("page", pageCount+1).
-			Msg("Fetching audit log page")
-
-		resp, err := call.Do()
+	// NDJSON is written page by page instead of after the last page
+	if auditOutputFormat == "ndjson" {
+		count, err := writeNDJSONOutput(client.Audit.All(apiContext(), query))
 		if err != nil {
-			Logger.Error().
-				Err(err).
-				Int("page", pageCount+1).
-				Msg("Failed to fetch activities")
-			os.Exit(1)
-		}
-
-		if resp.Items != nil {
-			activities = append(activities, resp.Items...)
-			Logger.Debug().
-				Int("count", len(resp.Items)).
-				Int("total", len(activities)).
-				Msg("Retrieved activities")
+			return err
 		}
-
-		pageCount++
-
-		// Check if we've reached max results
-		if maxResults > 0 && int64(len(activities)) >= maxResults {
-			activities = activities[:maxResults]
-			break
+		Logger.Info().Int("total", count).Msg("Retrieved audit logs")
+		if count == 0 {
+			Logger.Warn().Msg("No audit logs found for the specified criteria")
+		} else if outputFileFlag != "" && apiContext().Err() == nil {
+			Logger.Info().
+				Str("file", outputFileFlag).
+				Str("format", auditOutputFormat).
+				Msg("Audit logs exported successfully")
 		}
+		return nil
+	}
 
-		// Check for next page
-		if resp.NextPageToken == "" {
-			break
+	// Fetch activities with pagination
+	result, err := client.Audit.Activities(apiContext(), query)
+	if err != nil {
+		// On Ctrl-C or --timeout, export what has been fetched so far
+		if _, _, cancelled := cancellation(); !cancelled || result == nil || len(result.Items) == 0 {
+			return fmt.Errorf("failed to fetch activities: %w", err)
 		}
-		pageToken = resp.NextPageToken
 	}
+	activities, pageCount := result.Items, result.Pages
 
 	Logger.Info().
 		Int("total", len(activities)).
 		Int("pages", pageCount).
 		Msg("Retrieved audit logs")
 
+	// --filter and --sort-by apply to CSV as well as JSON
+	filtered, err := filterAndSort(activities)
+	if err != nil {
+		return err
+	}
+	activities = filtered.([]*reports.Activity)
+
 	// Output results
 	if len(activities) == 0 {
 		Logger.Warn().Msg("No audit logs found for the specified criteria")
-		return
+		return nil
 	}
 
 	var outputErr error
 	if auditOutputFormat == "csv" {
-		outputErr = writeCSVOutput(activities, outputFile)
+		outputErr = writeCSVOutput(activities)
 	} else {
-		outputErr = writeJSONOutput(activities, outputFile)
+		outputErr = writeJSONOutput(activities)
 	}
 
 	if outputErr != nil {
-		Logger.Error().Err(outputErr).Msg("Failed to write output")
-		os.Exit(1)
+		return fmt.Errorf("failed to write output: %w", outputErr)
 	}
 
-	if outputFile != "" {
+	warnPartialOutput(len(activities), "activities")
+
+	if outputFileFlag != "" && apiContext().Err() == nil {
 		Logger.Info().
-			Str("file", outputFile).
+			Str("file", outputFileFlag).
 			Str("format", auditOutputFormat).
 			Msg("Audit logs exported successfully")
 	}
+	return nil
 }
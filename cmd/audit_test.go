@@ -3,6 +3,8 @@ package cmd
 import (
 	"testing"
 	"time"
+
+	"github.com/acockrell/google-admin-client/pkg/gac"
 )
 
 func TestParseTimeRange(t *testing.T) {
@@ -112,8 +114,8 @@ func TestValidAppTypes(t *testing.T) {
 	}
 
 	for _, app := range validApps {
-		if !validAppTypes[app] {
-			t.Errorf("validAppTypes missing expected app type: %s", app)
+		if !gac.ValidApplication(app) {
+			t.Errorf("gac.Applications missing expected app type: %s", app)
 		}
 	}
 }
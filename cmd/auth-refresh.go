@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/oauth2"
+)
+
+// authRefreshCmd represents the auth refresh command
+var authRefreshCmd = &cobra.Command{
+	Use:   "refresh",
+	Short: "Refresh the access token now",
+	Long: `Exchange the refresh token for a new access token, even if the current
+one has not expired, and save it to the token cache. Use it to check that
+the refresh token is still accepted.
+
+Service accounts have no cached token; a new access token is minted to check
+that the key and delegation work.
+
+Examples:
+  gac auth refresh
+  gac auth refresh --format json`,
+	Args: cobra.NoArgs,
+	RunE: authRefreshRunFunc,
+}
+
+func init() {
+	authCmd.AddCommand(authRefreshCmd)
+}
+
+type authRefreshResult struct {
+	CredentialType string `json:"credentialType"`
+	TokenFile      string `json:"tokenFile,omitempty"`
+	Expiry         string `json:"expiry"`
+	ExpiresIn      string `json:"expiresIn"`
+}
+
+func authRefreshRunFunc(cmd *cobra.Command, args []string) error {
+	creds, err := loadCredentials()
+	if err != nil {
+		return err
+	}
+
+	var tok *oauth2.Token
+	if creds.Type == credentialTypeServiceAccount {
+		tok, err = creds.JWT.TokenSource(apiContext()).Token()
+	} else {
+		tok, err = forceRefresh(creds)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to refresh token: %w", err)
+	}
+
+	result := authRefreshResult{
+		CredentialType: creds.Type,
+		TokenFile:      creds.TokenFile,
+		Expiry:         tok.Expiry.Local().Format(time.RFC3339),
+		ExpiresIn:      formatExpiresIn(time.Until(tok.Expiry)),
+	}
+	headers := []string{"CredentialType", "Expiry", "ExpiresIn", "TokenFile"}
+	if err := FormatOutput(result, headers); err != nil {
+		return fmt.Errorf("failed to format output: %w", err)
+	}
+	return nil
+}
+
+// forceRefresh exchanges the cached refresh token for a new access token and
+// saves it, holding the cache lock so concurrent gac processes don't race
+func forceRefresh(creds *storedCredentials) (*oauth2.Token, error) {
+	if creds.Token == nil {
+		return nil, errNotAuthenticated
+	}
+
+	unlock, err := lockFile(creds.TokenFile, true)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	// Re-read under the lock in case another process rotated the token
+	cached, scopes, err := readTokenFile(creds.TokenFile)
+	if err != nil {
+		return nil, err
+	}
+	if cached.RefreshToken == "" {
+		return nil, errors.New("the cached token has no refresh token (run 'gac init --force')")
+	}
+
+	// Without an access token the source always calls the token endpoint;
+	// the refresh token is kept if Google does not rotate it
+	tok, err := creds.OAuth.TokenSource(apiContext(), &oauth2.Token{RefreshToken: cached.RefreshToken}).Token()
+	if err != nil {
+		return nil, err
+	}
+	if err := writeTokenFile(creds.TokenFile, tok, scopes); err != nil {
+		return nil, fmt.Errorf("unable to save token: %w", err)
+	}
+
+	LogDebug("Persisted refreshed token", map[string]interface{}{
+		"path":   creds.TokenFile,
+		"expiry": tok.Expiry,
+	})
+	return tok, nil
+}
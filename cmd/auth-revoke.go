@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// googleRevokeURL is Google's OAuth2 token revocation endpoint; replaced in
+// tests
+var googleRevokeURL = "https://oauth2.googleapis.com/revoke"
+
+// authRevokeCmd represents the auth revoke command
+var authRevokeCmd = &cobra.Command{
+	Use:   "revoke",
+	Short: "Revoke the cached token and delete it",
+	Long: `Revoke gac's OAuth2 grant with Google and delete the token cache. The
+next command will ask you to authenticate again.
+
+Revoking the refresh token also invalidates every access token issued from
+it. A token Google no longer recognizes (already revoked or expired) is
+still deleted locally.
+
+Service account keys cannot be revoked this way; disable or delete the key
+in the Google Cloud console instead.
+
+Examples:
+  gac auth revoke
+  gac auth revoke --yes --format json`,
+	Args: cobra.NoArgs,
+	RunE: authRevokeRunFunc,
+}
+
+func init() {
+	authCmd.AddCommand(authRevokeCmd)
+}
+
+type authRevokeResult struct {
+	Revoked   bool   `json:"revoked"`
+	TokenFile string `json:"tokenFile"`
+	Removed   bool   `json:"removed"`
+}
+
+func authRevokeRunFunc(cmd *cobra.Command, args []string) error {
+	creds, err := loadCredentials()
+	if err != nil {
+		return err
+	}
+	if creds.Type == credentialTypeServiceAccount {
+		return invalidInputf("service account keys cannot be revoked by gac; disable or delete the key in the Google Cloud console")
+	}
+	if creds.Token == nil {
+		return errNotAuthenticated
+	}
+
+	message := fmt.Sprintf("WARNING: This will revoke gac's access to your Google Workspace account\nand delete %s.", creds.TokenFile)
+	if !confirmAction(message, false) {
+		QuietPrintln("Revoke cancelled.")
+		return nil
+	}
+
+	result := authRevokeResult{TokenFile: creds.TokenFile}
+
+	token := creds.Token.RefreshToken
+	if token == "" {
+		token = creds.Token.AccessToken
+	}
+	err = revokeToken(token)
+	switch {
+	case errors.Is(err, errTokenInvalid):
+		LogWarn("Google did not recognize the token; it was already revoked or expired", nil)
+	case err != nil:
+		return fmt.Errorf("failed to revoke token: %w", err)
+	default:
+		result.Revoked = true
+	}
+
+	unlock, err := lockFile(creds.TokenFile, true)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(creds.TokenFile)
+	unlock()
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("token revoked but the cache could not be deleted: %w", err)
+	}
+	result.Removed = true
+
+	headers := []string{"Revoked", "TokenFile", "Removed"}
+	if err := FormatOutput(result, headers); err != nil {
+		return fmt.Errorf("failed to format output: %w", err)
+	}
+	return nil
+}
+
+// errTokenInvalid means Google no longer recognizes the token
+var errTokenInvalid = errors.New("invalid token")
+
+// revokeToken asks Google to revoke an OAuth2 access or refresh token
+func revokeToken(token string) error {
+	form := url.Values{"token": {token}}
+	req, err := http.NewRequestWithContext(apiContext(), http.MethodPost, googleRevokeURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var body struct {
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	b, _ := io.ReadAll(resp.Body)
+	if json.Unmarshal(b, &body) == nil && body.Error == "invalid_token" {
+		return errTokenInvalid
+	}
+	if body.ErrorDescription != "" {
+		return fmt.Errorf("%s: %s", resp.Status, body.ErrorDescription)
+	}
+	return fmt.Errorf("unexpected response: %s", resp.Status)
+}
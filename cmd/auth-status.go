@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/oauth2"
+	admin "google.golang.org/api/admin/directory/v1"
+)
+
+// authStatusCmd represents the auth status command
+var authStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the state of the cached credentials",
+	Long: `Show the credential type, the authenticated admin, the access token's
+expiry and the OAuth2 scopes granted to gac.
+
+The admin is looked up with the Directory API when the token allows it; an
+expired access token is refreshed to do so.
+
+Examples:
+  gac auth status
+  gac auth status --format json`,
+	Args: cobra.NoArgs,
+	RunE: authStatusRunFunc,
+}
+
+func init() {
+	authCmd.AddCommand(authStatusCmd)
+}
+
+type authStatus struct {
+	CredentialType string   `json:"credentialType"`
+	ClientSecret   string   `json:"clientSecret"`
+	ServiceAccount string   `json:"serviceAccount,omitempty"`
+	TokenFile      string   `json:"tokenFile,omitempty"`
+	Authenticated  bool     `json:"authenticated"`
+	Admin          string   `json:"admin,omitempty"`
+	Expiry         string   `json:"expiry,omitempty"`
+	ExpiresIn      string   `json:"expiresIn,omitempty"`
+	RefreshToken   bool     `json:"refreshToken"`
+	Scopes         []string `json:"scopes,omitempty"`
+}
+
+func authStatusRunFunc(cmd *cobra.Command, args []string) error {
+	creds, err := loadCredentials()
+	if err != nil {
+		return err
+	}
+
+	status := authStatus{
+		CredentialType: creds.Type,
+		ClientSecret:   clientSecret,
+		TokenFile:      creds.TokenFile,
+		Scopes:         creds.Scopes,
+	}
+
+	switch {
+	case creds.Type == credentialTypeServiceAccount:
+		// Service account tokens are minted on demand and never cached
+		status.Authenticated = true
+		status.ServiceAccount = creds.JWT.Email
+		status.Admin = creds.JWT.Subject
+		status.Scopes = nil
+	case creds.Token != nil:
+		status.Authenticated = true
+		tok := creds.Token
+		if creds.hasScope(admin.AdminDirectoryUserReadonlyScope) {
+			status.Admin, tok = lookupAdmin(creds)
+		}
+		status.RefreshToken = tok.RefreshToken != ""
+		if !tok.Expiry.IsZero() {
+			status.Expiry = tok.Expiry.Local().Format(time.RFC3339)
+			status.ExpiresIn = formatExpiresIn(time.Until(tok.Expiry))
+		}
+	}
+
+	headers := []string{"CredentialType", "Authenticated", "Admin", "Expiry", "ExpiresIn", "TokenFile"}
+	if err := FormatOutput(status, headers); err != nil {
+		return fmt.Errorf("failed to format output: %w", err)
+	}
+	return nil
+}
+
+// lookupAdmin returns the email of the authenticated admin and the token in
+// use afterwards, which is newer if the lookup refreshed it. Failures are
+// logged and leave the admin unknown.
+func lookupAdmin(creds *storedCredentials) (string, *oauth2.Token) {
+	ts, err := creds.tokenSource()
+	if err != nil {
+		return "", creds.Token
+	}
+	u, err := currentUser(ts)
+	if err != nil {
+		LogWarn("Unable to determine the authenticated admin", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return "", creds.Token
+	}
+	if tok, err := ts.Token(); err == nil {
+		return u.PrimaryEmail, tok
+	}
+	return u.PrimaryEmail, creds.Token
+}
+
+// formatExpiresIn describes how long until a token expires
+func formatExpiresIn(d time.Duration) string {
+	if d <= 0 {
+		return "expired"
+	}
+	return d.Round(time.Second).String()
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/acockrell/google-admin-client/pkg/gac"
+	"github.com/spf13/cobra"
+	admin "google.golang.org/api/admin/directory/v1"
+)
+
+// authWhoamiCmd represents the auth whoami command
+var authWhoamiCmd = &cobra.Command{
+	Use:   "whoami",
+	Short: "Show the admin gac acts as",
+	Long: `Look up the Workspace user gac authenticates as: the account that
+completed "gac init", or the admin a service account impersonates.
+
+Examples:
+  gac auth whoami
+  gac auth whoami --format json`,
+	Args: cobra.NoArgs,
+	RunE: authWhoamiRunFunc,
+}
+
+func init() {
+	authCmd.AddCommand(authWhoamiCmd)
+}
+
+type whoami struct {
+	Email            string `json:"email"`
+	Name             string `json:"name,omitempty"`
+	ID               string `json:"id"`
+	CustomerID       string `json:"customerId,omitempty"`
+	IsAdmin          bool   `json:"isAdmin"`
+	IsDelegatedAdmin bool   `json:"isDelegatedAdmin"`
+	CredentialType   string `json:"credentialType"`
+}
+
+func authWhoamiRunFunc(cmd *cobra.Command, args []string) error {
+	creds, err := loadCredentials()
+	if err != nil {
+		return err
+	}
+	ts, err := creds.tokenSource()
+	if err != nil {
+		return err
+	}
+	if !creds.hasScope(admin.AdminDirectoryUserReadonlyScope) {
+		return withKind(errors.New("the cached token cannot read users; run 'gac init' to grant access"), gac.ErrForbidden)
+	}
+	u, err := currentUser(ts)
+	if err != nil {
+		return err
+	}
+
+	result := whoami{
+		Email:            u.PrimaryEmail,
+		ID:               u.Id,
+		CustomerID:       u.CustomerId,
+		IsAdmin:          u.IsAdmin,
+		IsDelegatedAdmin: u.IsDelegatedAdmin,
+		CredentialType:   creds.Type,
+	}
+	if u.Name != nil {
+		result.Name = u.Name.FullName
+	}
+
+	headers := []string{"Email", "Name", "ID", "CustomerID", "IsAdmin", "IsDelegatedAdmin", "CredentialType"}
+	if err := FormatOutput(result, headers); err != nil {
+		return fmt.Errorf("failed to format output: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/acockrell/google-admin-client/pkg/gac"
+	"github.com/spf13/cobra"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	"golang.org/x/oauth2/jwt"
+	admin "google.golang.org/api/admin/directory/v1"
+)
+
+// Credential types reported by the auth commands
+const (
+	credentialTypeOAuth          = "oauth"
+	credentialTypeServiceAccount = "service_account"
+)
+
+// errNotAuthenticated is returned when no OAuth2 token has been cached yet
+var errNotAuthenticated = withKind(errors.New("not authenticated (run 'gac init')"), gac.ErrForbidden)
+
+// authCmd represents the auth command
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Inspect and manage credentials",
+	Long: `Inspect and manage the credentials gac uses.
+
+Unlike other commands, auth subcommands never open the browser to ask for
+consent; use "gac init" to authenticate.
+
+Examples:
+  # Show the credential type, token expiry and granted scopes
+  gac auth status
+
+  # Show the admin gac acts as
+  gac auth whoami --format json
+
+  # Refresh the access token now
+  gac auth refresh
+
+  # Revoke the token with Google and delete it
+  gac auth revoke
+`,
+}
+
+func init() {
+	rootCmd.AddCommand(authCmd)
+	requireScopes(authCmd, admin.AdminDirectoryUserReadonlyScope)
+}
+
+// storedCredentials are the credentials gac would use for API calls, loaded
+// without prompting for consent
+type storedCredentials struct {
+	Type string
+
+	// OAuth2 client credentials and the cached token; Token is nil when
+	// gac has not been initialized
+	OAuth     *oauth2.Config
+	TokenFile string
+	Token     *oauth2.Token
+	Scopes    []string
+
+	// Service account key with the impersonated admin as Subject
+	JWT *jwt.Config
+}
+
+// loadCredentials reads the configured client secret and, for OAuth2, the
+// cached token
+func loadCredentials() (*storedCredentials, error) {
+	b, err := readClientSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if isServiceAccountKey(b) {
+		jwtConfig, err := serviceAccountConfig(b)
+		if err != nil {
+			return nil, err
+		}
+		return &storedCredentials{Type: credentialTypeServiceAccount, JWT: jwtConfig}, nil
+	}
+
+	config, err := google.ConfigFromJSON(b, activeScopes()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse client secret JSON: %w", err)
+	}
+	file, err := tokenCacheFile()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token cache file path: %w", err)
+	}
+
+	creds := &storedCredentials{Type: credentialTypeOAuth, OAuth: config, TokenFile: file}
+	tok, scopes, err := tokenFromFile(file)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return creds, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to read token cache %s: %w", file, err)
+	}
+	if len(scopes) == 0 {
+		scopes = legacyTokenScopes
+	}
+	creds.Token, creds.Scopes = tok, scopes
+	return creds, nil
+}
+
+// tokenSource returns a token source for the credentials. OAuth2 refreshes
+// are written back to the token cache.
+func (c *storedCredentials) tokenSource() (oauth2.TokenSource, error) {
+	if c.Type == credentialTypeServiceAccount {
+		return c.JWT.TokenSource(apiContext()), nil
+	}
+	if c.Token == nil {
+		return nil, errNotAuthenticated
+	}
+	return newPersistingTokenSource(apiContext(), c.OAuth, c.TokenFile, c.Token, c.Scopes), nil
+}
+
+// hasScope reports whether the credentials were granted a scope. Service
+// accounts get whatever scopes they ask for under domain-wide delegation.
+func (c *storedCredentials) hasScope(scope string) bool {
+	return c.Type == credentialTypeServiceAccount || len(missingScopes(c.Scopes, []string{scope})) == 0
+}
+
+// adminClientFromTokenSource returns a Directory client authorized by ts,
+// without asking for consent to missing scopes
+func adminClientFromTokenSource(ts oauth2.TokenSource) (*admin.Service, error) {
+	client := oauth2.NewClient(apiContext(), ts)
+	client.Transport = newAPITransport(client.Transport)
+
+	opts, err := serviceOptions("admin", client)
+	if err != nil {
+		return nil, err
+	}
+	return admin.NewService(apiContext(), opts...)
+}
+
+// currentUser resolves the admin a token source acts for
+func currentUser(ts oauth2.TokenSource) (*admin.User, error) {
+	srv, err := adminClientFromTokenSource(ts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create admin directory service: %w", err)
+	}
+
+	u, err := srv.Users.Get("me").
+		Projection("basic").
+		Fields("id", "primaryEmail", "name/fullName", "customerId", "isAdmin", "isDelegatedAdmin").
+		Context(apiContext()).
+		Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up the authenticated user: %w", err)
+	}
+	return u, nil
+}
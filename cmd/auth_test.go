@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// withCredentialFiles points gac at a client secret and token cache in a
+// temp directory for one test
+func withCredentialFiles(t *testing.T, secret string) (secretFile, tokenFile string) {
+	t.Helper()
+	dir := t.TempDir()
+	secretFile = filepath.Join(dir, "client_secret.json")
+	tokenFile = filepath.Join(dir, "gac.json")
+	if err := os.WriteFile(secretFile, []byte(secret), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	originalSecret, originalCache := clientSecret, cacheFile
+	t.Cleanup(func() {
+		clientSecret, cacheFile = originalSecret, originalCache
+	})
+	clientSecret, cacheFile = secretFile, tokenFile
+	return secretFile, tokenFile
+}
+
+const testInstalledSecret = `{"installed":{"client_id":"id.apps.googleusercontent.com","client_secret":"secret","auth_uri":"https://accounts.google.com/o/oauth2/auth","token_uri":"https://oauth2.googleapis.com/token","redirect_uris":["http://localhost"]}}`
+
+func TestLoadCredentialsOAuth(t *testing.T) {
+	_, tokenFile := withCredentialFiles(t, testInstalledSecret)
+
+	creds, err := loadCredentials()
+	if err != nil {
+		t.Fatalf("loadCredentials() error = %v", err)
+	}
+	if creds.Type != credentialTypeOAuth || creds.Token != nil {
+		t.Fatalf("loadCredentials() without token = %+v, want oauth with no token", creds)
+	}
+	if _, err := creds.tokenSource(); !errors.Is(err, errNotAuthenticated) {
+		t.Errorf("tokenSource() error = %v, want errNotAuthenticated", err)
+	}
+
+	// Tokens written before scopes were recorded are assumed to hold the
+	// legacy scope set
+	if err := saveToken(tokenFile, &oauth2.Token{AccessToken: "a", RefreshToken: "r"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	creds, err = loadCredentials()
+	if err != nil {
+		t.Fatalf("loadCredentials() error = %v", err)
+	}
+	if creds.Token == nil || len(creds.Scopes) != len(legacyTokenScopes) {
+		t.Errorf("loadCredentials() = %+v, want token with legacy scopes", creds)
+	}
+	if !creds.hasScope("https://www.googleapis.com/auth/admin.directory.user.readonly") {
+		t.Error("hasScope(user.readonly) = false, want true")
+	}
+}
+
+func TestForceRefresh(t *testing.T) {
+	_, tokenFile := withCredentialFiles(t, testInstalledSecret)
+
+	var calls int32
+	server := newRefreshServer(t, &calls)
+	defer server.Close()
+
+	scopes := []string{"https://www.googleapis.com/auth/admin.directory.group"}
+	valid := &oauth2.Token{AccessToken: "still-valid", RefreshToken: "refresh", Expiry: time.Now().Add(time.Hour)}
+	if err := saveToken(tokenFile, valid, scopes); err != nil {
+		t.Fatal(err)
+	}
+
+	creds, err := loadCredentials()
+	if err != nil {
+		t.Fatal(err)
+	}
+	creds.OAuth.Endpoint.TokenURL = server.URL
+
+	tok, err := forceRefresh(creds)
+	if err != nil {
+		t.Fatalf("forceRefresh() error = %v", err)
+	}
+	if calls != 1 || tok.AccessToken != "refreshed-access" {
+		t.Errorf("forceRefresh() = %q after %d calls, want a refresh of a still-valid token", tok.AccessToken, calls)
+	}
+
+	saved, savedScopes, err := tokenFromFile(tokenFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.RefreshToken != "rotated-refresh" {
+		t.Errorf("saved refresh token = %q, want rotated-refresh", saved.RefreshToken)
+	}
+	if len(savedScopes) != 1 || savedScopes[0] != scopes[0] {
+		t.Errorf("saved scopes = %v, want %v", savedScopes, scopes)
+	}
+
+	// A token without a refresh token cannot be refreshed
+	if err := saveToken(tokenFile, &oauth2.Token{AccessToken: "a"}, scopes); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := forceRefresh(creds); err == nil || !strings.Contains(err.Error(), "no refresh token") {
+		t.Errorf("forceRefresh() error = %v, want no refresh token", err)
+	}
+}
+
+func TestRevokeToken(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr error
+		anyErr  bool
+	}{
+		{name: "revoked", status: http.StatusOK, body: "{}"},
+		{name: "already revoked", status: http.StatusBadRequest, body: `{"error":"invalid_token","error_description":"Token expired or revoked"}`, wantErr: errTokenInvalid, anyErr: true},
+		{name: "server error", status: http.StatusInternalServerError, body: "oops", anyErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := r.ParseForm(); err != nil || r.Form.Get("token") != "refresh" {
+					t.Errorf("revoke request token = %q, want refresh", r.Form.Get("token"))
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			original := googleRevokeURL
+			googleRevokeURL = server.URL
+			defer func() { googleRevokeURL = original }()
+
+			err := revokeToken("refresh")
+			if (err != nil) != tt.anyErr {
+				t.Fatalf("revokeToken() error = %v, wantErr %v", err, tt.anyErr)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("revokeToken() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFormatExpiresIn(t *testing.T) {
+	if got := formatExpiresIn(-time.Minute); got != "expired" {
+		t.Errorf("formatExpiresIn(-1m) = %q, want expired", got)
+	}
+	if got := formatExpiresIn(90*time.Second + 400*time.Millisecond); got != "1m30s" {
+		t.Errorf("formatExpiresIn(90.4s) = %q, want 1m30s", got)
+	}
+}
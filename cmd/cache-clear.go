@@ -12,20 +12,23 @@ var (
 
 // cacheClearCmd represents the cache clear command
 var cacheClearCmd = &cobra.Command{
-	Use:   "clear [users|groups|all]",
+	Use:   "clear [users|groups|stale|all]",
 	Short: "Clear cache entries",
 	Long: `Clear cache entries for users, groups, or all cached data.
 
 This is useful when you want to force a refresh from the API,
-or if cached data has become stale.
+or if cached data has become stale. Entries written by another
+gac version are ignored and removed when next read; "stale"
+removes them all at once, e.g. after upgrading.
 
 Examples:
   gac cache clear users        # Clear user listing cache
   gac cache clear groups       # Clear group listing cache
+  gac cache clear stale        # Clear entries from other gac versions
   gac cache clear all          # Clear all caches
   gac cache clear --all        # Clear all caches (alternative)`,
-	Run:       cacheClearRunFunc,
-	ValidArgs: []string{"users", "groups", "all"},
+	RunE:      cacheClearRunFunc,
+	ValidArgs: []string{"users", "groups", "stale", "all"},
 }
 
 func init() {
@@ -33,7 +36,7 @@ func init() {
 	cacheClearCmd.Flags().BoolVar(&clearAllFlag, "all", false, "clear all cache entries")
 }
 
-func cacheClearRunFunc(cmd *cobra.Command, args []string) {
+func cacheClearRunFunc(cmd *cobra.Command, args []string) error {
 	// Determine what to clear
 	resourceType := "all"
 	if len(args) > 0 {
@@ -46,16 +49,17 @@ func cacheClearRunFunc(cmd *cobra.Command, args []string) {
 	validTypes := map[string]bool{
 		"users":  true,
 		"groups": true,
+		"stale":  true,
 		"all":    true,
 	}
 
 	if !validTypes[resourceType] {
-		exitWithError(fmt.Sprintf("Invalid resource type: %s. Valid types: users, groups, all", resourceType))
+		return invalidInputf("invalid resource type: %s (valid types: users, groups, stale, all)", resourceType)
 	}
 
 	// Clear the cache
 	if err := clearCache(resourceType); err != nil {
-		exitWithError(fmt.Sprintf("Failed to clear cache: %s", err))
+		return fmt.Errorf("failed to clear cache: %w", err)
 	}
 
 	// Success message
@@ -65,4 +69,5 @@ func cacheClearRunFunc(cmd *cobra.Command, args []string) {
 	default:
 		Logger.Info().Str("resource_type", resourceType).Msg("Cache cleared successfully")
 	}
+	return nil
 }
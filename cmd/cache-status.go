@@ -18,11 +18,12 @@ var cacheStatusCmd = &cobra.Command{
   - Age of oldest and newest entries
   - Default TTL setting
   - Cache enabled/disabled status
+  - Whether cache and token encryption is enabled
 
 Examples:
   gac cache status
   gac cache status --format json`,
-	Run: cacheStatusRunFunc,
+	RunE: cacheStatusRunFunc,
 }
 
 func init() {
@@ -38,12 +39,15 @@ type cacheStatusOutput struct {
 	OldestEntry  string `json:"oldest_entry,omitempty"`
 	NewestEntry  string `json:"newest_entry,omitempty"`
 	DefaultTTL   string `json:"default_ttl"`
+	Encryption   string `json:"encryption"`
+	KeySource    string `json:"encryption_key_source,omitempty"`
+	Encrypted    int    `json:"encrypted_entries"`
 }
 
-func cacheStatusRunFunc(cmd *cobra.Command, args []string) {
+func cacheStatusRunFunc(cmd *cobra.Command, args []string) error {
 	stats, err := getCacheStats()
 	if err != nil {
-		exitWithError(fmt.Sprintf("Failed to get cache statistics: %s", err))
+		return fmt.Errorf("failed to get cache statistics: %w", err)
 	}
 
 	// Prepare output data
@@ -54,6 +58,12 @@ func cacheStatusRunFunc(cmd *cobra.Command, args []string) {
 		TotalSizeRaw: stats.TotalSize,
 		EntryCount:   stats.EntryCount,
 		DefaultTTL:   stats.DefaultTTL.String(),
+		Encryption:   "disabled",
+		Encrypted:    stats.EncryptedEntries,
+	}
+	if stats.EncryptionEnabled {
+		output.Encryption = "enabled"
+		output.KeySource = stats.KeySource
 	}
 
 	if !stats.OldestEntry.IsZero() {
@@ -65,11 +75,11 @@ func cacheStatusRunFunc(cmd *cobra.Command, args []string) {
 	}
 
 	// Use unified formatter for structured output
-	if outputFormat == OutputFormatJSON || outputFormat == OutputFormatYAML {
+	if structuredOutput() {
 		if err := FormatOutput(output, nil); err != nil {
-			exitWithError(fmt.Sprintf("Failed to format output: %s", err))
+			return fmt.Errorf("failed to format output: %w", err)
 		}
-		return
+		return nil
 	}
 
 	// Plain text output (default)
@@ -86,11 +96,20 @@ func cacheStatusRunFunc(cmd *cobra.Command, args []string) {
 	}
 
 	fmt.Printf("Default TTL:   %s\n", stats.DefaultTTL)
+	if stats.EncryptionEnabled {
+		fmt.Printf("Encryption:    enabled (AES-256-GCM, key from %s)\n", stats.KeySource)
+	} else {
+		fmt.Println("Encryption:    disabled")
+	}
+	if stats.EncryptedEntries > 0 || stats.EncryptionEnabled {
+		fmt.Printf("Encrypted:     %d of %d entries\n", stats.EncryptedEntries, stats.EntryCount)
+	}
 
 	if !stats.CacheEnabled {
 		fmt.Println("\nNote: Caching is currently disabled")
 		fmt.Println("Enable with: cache.enabled=true in config or remove --no-cache flag")
 	}
+	return nil
 }
 
 // formatTimeAgo formats a time as a relative duration from now
@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"os/user"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -20,11 +22,28 @@ var (
 	cacheTTLFlag string
 )
 
+// cacheSchemaVersion is stored in every cache entry. Bump it whenever the
+// entry layout or the type of cached data changes, so older entries are
+// ignored instead of decoded into the wrong type.
+const cacheSchemaVersion = 2
+
+// errCacheStale reports a cache entry written by another gac version or
+// with another schema
+var errCacheStale = errors.New("cache entry from another gac version")
+
 // CacheEntry represents a cached data entry with metadata
-type CacheEntry struct {
-	Timestamp time.Time   `json:"timestamp"`
-	TTL       int64       `json:"ttl"` // TTL in seconds
-	Data      interface{} `json:"data"`
+type CacheEntry[T any] struct {
+	Schema    int       `json:"schema"`
+	Version   string    `json:"version"` // gac version that wrote the entry
+	Timestamp time.Time `json:"timestamp"`
+	TTL       int64     `json:"ttl"` // TTL in seconds
+	Data      T         `json:"data"`
+}
+
+// stale reports whether the entry was written by another gac version or
+// with another schema
+func (e *CacheEntry[T]) stale() bool {
+	return e.Schema != cacheSchemaVersion || e.Version != version
 }
 
 // CacheStats represents cache statistics
@@ -37,6 +56,11 @@ type CacheStats struct {
 	DefaultTTL    time.Duration
 	CacheEnabled  bool
 	CacheLocation string
+
+	// Encryption settings and the number of entries stored encrypted
+	EncryptionEnabled bool
+	KeySource         string
+	EncryptedEntries  int
 }
 
 // getCacheDir returns the cache directory path
@@ -77,6 +101,11 @@ func isCacheEnabled() bool {
 		return false
 	}
 
+	// Recording and replaying must exercise the API, not the cache
+	if recordDir != "" || replayDir != "" {
+		return false
+	}
+
 	// Check config file setting (default to true)
 	return viper.GetBool("cache.enabled")
 }
@@ -108,20 +137,25 @@ func getCacheTTL() time.Duration {
 	return 15 * time.Minute
 }
 
-// getCacheKey generates a cache key from resource type, domain, and filters
+// getCacheKey generates a cache key from resource type, domain, and filters.
+// Filters are sorted by name, so the same filters always give the same key.
 func getCacheKey(resourceType, domain string, filters map[string]string) string {
 	// Start with resource type and domain
 	keyParts := []string{resourceType, domain}
 
-	// Add sorted filter keys and values for consistency
 	if len(filters) > 0 {
-		// Create a deterministic string from filters
-		filterStr := ""
-		for k, v := range filters {
-			filterStr += fmt.Sprintf("%s=%s,", k, v)
+		// Create a canonical string from the sorted filters
+		names := make([]string, 0, len(filters))
+		for k := range filters {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		pairs := make([]string, 0, len(names))
+		for _, k := range names {
+			pairs = append(pairs, url.QueryEscape(k)+"="+url.QueryEscape(filters[k]))
 		}
 		// Hash the filter string to keep filename manageable
-		hash := sha256.Sum256([]byte(filterStr))
+		hash := sha256.Sum256([]byte(strings.Join(pairs, "&")))
 		filterHash := fmt.Sprintf("%x", hash[:8]) // Use first 8 bytes of hash
 		keyParts = append(keyParts, filterHash)
 	} else {
@@ -132,33 +166,49 @@ func getCacheKey(resourceType, domain string, filters map[string]string) string
 	return strings.Join(keyParts, "-") + ".json"
 }
 
-// readFromCache reads data from cache if it exists and is not expired
-func readFromCache(key string, ttl time.Duration) (interface{}, error) {
+// readFromCache reads data of type T from cache if it exists, is not
+// expired and was written by this gac version. Entries from other versions
+// are removed.
+func readFromCache[T any](key string, ttl time.Duration) (T, error) {
+	var data T
 	if !isCacheEnabled() {
-		return nil, errors.New("cache disabled")
+		return data, errors.New("cache disabled")
 	}
 
 	cacheDir, err := getCacheDir()
 	if err != nil {
-		return nil, err
+		return data, err
 	}
 
 	cachePath := filepath.Join(cacheDir, key)
 
-	// Check if cache file exists
-	if _, err := os.Stat(cachePath); os.IsNotExist(err) {
-		return nil, errors.New("cache miss")
-	}
-
 	// #nosec G304 - Path is constructed from validated cache directory
-	data, err := os.ReadFile(cachePath)
+	raw, err := os.ReadFile(cachePath)
+	if os.IsNotExist(err) {
+		return data, errors.New("cache miss")
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to read cache file: %w", err)
+		return data, fmt.Errorf("failed to read cache file: %w", err)
+	}
+	if raw, err = openSealed(raw, sealPurposeCache); err != nil {
+		return data, fmt.Errorf("failed to decrypt cache entry: %w", err)
 	}
 
-	var entry CacheEntry
-	if err := json.Unmarshal(data, &entry); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal cache entry: %w", err)
+	// Check the version before decoding data that may be of another type
+	var entry CacheEntry[json.RawMessage]
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return data, fmt.Errorf("failed to unmarshal cache entry: %w", err)
+	}
+	if entry.stale() {
+		Logger.Debug().
+			Str("key", key).
+			Int("schema", entry.Schema).
+			Str("version", entry.Version).
+			Msg("Cache entry from another gac version, removing")
+		if err := os.Remove(cachePath); err != nil {
+			Logger.Warn().Err(err).Str("file", key).Msg("Failed to remove cache file")
+		}
+		return data, errCacheStale
 	}
 
 	// Check if cache is expired
@@ -176,7 +226,11 @@ func readFromCache(key string, ttl time.Duration) (interface{}, error) {
 			Dur("age", age).
 			Dur("ttl", effectiveTTL).
 			Msg("Cache expired")
-		return nil, errors.New("cache expired")
+		return data, errors.New("cache expired")
+	}
+
+	if err := json.Unmarshal(entry.Data, &data); err != nil {
+		return data, fmt.Errorf("failed to unmarshal cached data: %w", err)
 	}
 
 	Logger.Debug().
@@ -185,11 +239,11 @@ func readFromCache(key string, ttl time.Duration) (interface{}, error) {
 		Dur("ttl", effectiveTTL).
 		Msg("Cache hit")
 
-	return entry.Data, nil
+	return data, nil
 }
 
-// writeToCache writes data to cache
-func writeToCache(key string, data interface{}, ttl time.Duration) error {
+// writeToCache writes data to cache, tagged with this gac version
+func writeToCache[T any](key string, data T, ttl time.Duration) error {
 	if !isCacheEnabled() {
 		return nil // Silently skip if cache disabled
 	}
@@ -199,7 +253,9 @@ func writeToCache(key string, data interface{}, ttl time.Duration) error {
 		return err
 	}
 
-	entry := CacheEntry{
+	entry := CacheEntry[T]{
+		Schema:    cacheSchemaVersion,
+		Version:   version,
 		Timestamp: time.Now(),
 		TTL:       int64(ttl.Seconds()),
 		Data:      data,
@@ -209,6 +265,9 @@ func writeToCache(key string, data interface{}, ttl time.Duration) error {
 	if err != nil {
 		return fmt.Errorf("failed to marshal cache entry: %w", err)
 	}
+	if jsonData, err = sealIfEnabled(jsonData, sealPurposeCache); err != nil {
+		return fmt.Errorf("failed to encrypt cache entry: %w", err)
+	}
 
 	cachePath := filepath.Join(cacheDir, key)
 
@@ -243,14 +302,21 @@ func clearCache(resourceType string) error {
 			continue
 		}
 
+		cachePath := filepath.Join(cacheDir, entry.Name())
+
 		// If resourceType is specified, only delete matching files
-		if resourceType != "" && resourceType != "all" {
+		switch resourceType {
+		case "", "all":
+		case "stale":
+			if !isStaleCacheFile(cachePath) {
+				continue
+			}
+		default:
 			if !strings.HasPrefix(entry.Name(), resourceType+"-") {
 				continue
 			}
 		}
 
-		cachePath := filepath.Join(cacheDir, entry.Name())
 		if err := os.Remove(cachePath); err != nil {
 			Logger.Warn().Err(err).Str("file", entry.Name()).Msg("Failed to remove cache file")
 			continue
@@ -266,6 +332,25 @@ func clearCache(resourceType string) error {
 	return nil
 }
 
+// isStaleCacheFile reports whether the file at path is a cache entry
+// written by another gac version or with another schema. Files that cannot
+// be read or decrypted are left alone.
+func isStaleCacheFile(path string) bool {
+	// #nosec G304 - Path is constructed from validated cache directory
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	if raw, err = openSealed(raw, sealPurposeCache); err != nil {
+		return false
+	}
+	var entry CacheEntry[json.RawMessage]
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return false
+	}
+	return entry.stale()
+}
+
 // getCacheStats returns cache statistics
 func getCacheStats() (*CacheStats, error) {
 	cacheDir, err := getCacheDir()
@@ -280,6 +365,9 @@ func getCacheStats() (*CacheStats, error) {
 		DefaultTTL:    getCacheTTL(),
 		CacheEnabled:  isCacheEnabled(),
 		CacheLocation: cacheDir,
+
+		EncryptionEnabled: encryptionEnabled(),
+		KeySource:         encryptionKeySource(),
 	}
 
 	entries, err := os.ReadDir(cacheDir)
@@ -300,6 +388,11 @@ func getCacheStats() (*CacheStats, error) {
 		stats.TotalSize += info.Size()
 		stats.EntryCount++
 
+		// #nosec G304 - Path is constructed from validated cache directory
+		if data, err := os.ReadFile(filepath.Join(cacheDir, entry.Name())); err == nil && isSealed(data) {
+			stats.EncryptedEntries++
+		}
+
 		modTime := info.ModTime()
 		if stats.OldestEntry.IsZero() || modTime.Before(stats.OldestEntry) {
 			stats.OldestEntry = modTime
@@ -1,12 +1,15 @@
 package cmd
 
 import (
+	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/spf13/viper"
+	admin "google.golang.org/api/admin/directory/v1"
 )
 
 func TestGetCacheDir(t *testing.T) {
@@ -268,7 +271,7 @@ func TestWriteAndReadCache(t *testing.T) {
 
 			if !tt.wantErr {
 				// Read from cache
-				cachedData, err := readFromCache(tt.key, tt.ttl)
+				cachedData, err := readFromCache[interface{}](tt.key, tt.ttl)
 				if err != nil {
 					t.Errorf("readFromCache() error = %v", err)
 					return
@@ -308,7 +311,7 @@ func TestCacheExpiration(t *testing.T) {
 	}
 
 	// Read immediately - should succeed
-	_, err = readFromCache(key, ttl)
+	_, err = readFromCache[string](key, ttl)
 	if err != nil {
 		t.Errorf("readFromCache() should succeed immediately after write, got error: %v", err)
 	}
@@ -317,7 +320,7 @@ func TestCacheExpiration(t *testing.T) {
 	time.Sleep(150 * time.Millisecond)
 
 	// Read after expiration - should fail
-	_, err = readFromCache(key, ttl)
+	_, err = readFromCache[string](key, ttl)
 	if err == nil {
 		t.Errorf("readFromCache() should fail after TTL expiration")
 	}
@@ -469,6 +472,121 @@ func TestFormatBytes(t *testing.T) {
 	}
 }
 
+func TestGetCacheKeyDeterministic(t *testing.T) {
+	filters := map[string]string{"disabled-only": "true", "domain": "example.com", "org-unit": "/Engineering", "query": "a=b&c"}
+	want := getCacheKey("users", "example.com", filters)
+
+	// Map iteration order varies between calls
+	for i := 0; i < 20; i++ {
+		if got := getCacheKey("users", "example.com", filters); got != want {
+			t.Fatalf("getCacheKey() = %v, want %v on every call", got, want)
+		}
+	}
+
+	// Separators in values cannot make different filters collide
+	a := getCacheKey("users", "example.com", map[string]string{"a": "1&b=2"})
+	b := getCacheKey("users", "example.com", map[string]string{"a": "1", "b": "2"})
+	if a == b {
+		t.Errorf("getCacheKey() = %v for different filters", a)
+	}
+}
+
+// withTestCacheDir enables the cache in a temporary directory for one test
+func withTestCacheDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	originalDir, originalEnabled, originalNoCache := viper.Get("cache.directory"), viper.Get("cache.enabled"), noCacheFlag
+	t.Cleanup(func() {
+		viper.Set("cache.directory", originalDir)
+		viper.Set("cache.enabled", originalEnabled)
+		noCacheFlag = originalNoCache
+	})
+	viper.Set("cache.directory", dir)
+	viper.Set("cache.enabled", true)
+	noCacheFlag = false
+	return dir
+}
+
+func TestReadFromCacheTyped(t *testing.T) {
+	withTestCacheDir(t)
+
+	users := []*admin.User{{PrimaryEmail: "alice@example.com", Name: &admin.UserName{FullName: "Alice Smith"}}}
+	if err := writeToCache("users-typed.json", users, time.Hour); err != nil {
+		t.Fatalf("writeToCache() error = %v", err)
+	}
+
+	got, err := readFromCache[[]*admin.User]("users-typed.json", time.Hour)
+	if err != nil {
+		t.Fatalf("readFromCache() error = %v", err)
+	}
+	if len(got) != 1 || got[0].PrimaryEmail != "alice@example.com" || got[0].Name.FullName != "Alice Smith" {
+		t.Errorf("readFromCache() = %+v, want the cached users", got)
+	}
+
+	// Data that does not decode as T is an error, not a hit
+	if _, err := readFromCache[map[string]int]("users-typed.json", time.Hour); err == nil {
+		t.Error("readFromCache() into the wrong type succeeded, want error")
+	}
+}
+
+// writeCacheEntry writes a raw cache entry as another gac version would
+func writeCacheEntry(t *testing.T, dir, key string, schema int, ver string) {
+	t.Helper()
+	b, err := json.Marshal(CacheEntry[[]string]{Schema: schema, Version: ver, Timestamp: time.Now(), TTL: 3600, Data: []string{"old"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, key), b, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadFromCacheStale(t *testing.T) {
+	dir := withTestCacheDir(t)
+
+	tests := []struct {
+		name   string
+		schema int
+		ver    string
+	}{
+		{name: "older schema", schema: cacheSchemaVersion - 1, ver: version},
+		{name: "other gac version", schema: cacheSchemaVersion, ver: "v0.0.1"},
+		{name: "unversioned entry", schema: 0, ver: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeCacheEntry(t, dir, "users-stale.json", tt.schema, tt.ver)
+
+			if _, err := readFromCache[[]string]("users-stale.json", time.Hour); !errors.Is(err, errCacheStale) {
+				t.Errorf("readFromCache() error = %v, want errCacheStale", err)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "users-stale.json")); !os.IsNotExist(err) {
+				t.Errorf("stale entry not removed, stat error = %v", err)
+			}
+		})
+	}
+}
+
+func TestClearCacheStale(t *testing.T) {
+	dir := withTestCacheDir(t)
+
+	writeCacheEntry(t, dir, "users-old.json", cacheSchemaVersion, "v0.0.1")
+	if err := writeToCache("users-current.json", []string{"new"}, time.Hour); err != nil {
+		t.Fatalf("writeToCache() error = %v", err)
+	}
+
+	if err := clearCache("stale"); err != nil {
+		t.Fatalf("clearCache() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "users-old.json")); !os.IsNotExist(err) {
+		t.Error("entry from another version was not cleared")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "users-current.json")); err != nil {
+		t.Errorf("current entry was cleared: %v", err)
+	}
+}
+
 // Helper function to check if string contains substring
 func containsString(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(substr) == 0 ||
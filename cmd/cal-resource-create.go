@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/spf13/cobra"
 	admin "google.golang.org/api/admin/directory/v1"
@@ -61,6 +60,7 @@ Examples:
 
 func init() {
 	calResourceCmd.AddCommand(calResourceCreateCmd)
+	requireScopes(calResourceCreateCmd, admin.AdminDirectoryResourceCalendarScope)
 	calResourceCreateCmd.Flags().StringVarP(&calResourceName, "name", "n", "", "resource name (required)")
 	calResourceCreateCmd.Flags().StringVarP(&calResourceType, "type", "t", "room", "resource type: room, equipment, or other")
 	calResourceCreateCmd.Flags().StringVarP(&calResourceDescription, "description", "d", "", "resource description")
@@ -77,10 +77,9 @@ func init() {
 }
 
 func calResourceCreateRunFunc(cmd *cobra.Command, args []string) error {
-	client, err := newAdminClient()
+	client, err := newDirectoryClient()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating client: %v\n", err)
-		return err
+		return fmt.Errorf("failed to create client: %w", err)
 	}
 
 	resourceId := args[0]
@@ -93,8 +92,7 @@ func calResourceCreateRunFunc(cmd *cobra.Command, args []string) error {
 	}
 	apiResourceType, ok := validTypes[calResourceType]
 	if !ok {
-		fmt.Fprintf(os.Stderr, "Error: Invalid resource type '%s'. Must be: room, equipment, or other\n", calResourceType)
-		return fmt.Errorf("invalid resource type")
+		return invalidInputf("invalid resource type '%s' (must be room, equipment, or other)", calResourceType)
 	}
 
 	// Create the calendar resource
@@ -119,12 +117,9 @@ func calResourceCreateRunFunc(cmd *cobra.Command, args []string) error {
 	// and cannot be set directly during resource creation
 	// Features must be created first, then associated with resources
 
-	customerID := "my_customer"
-
-	result, err := client.Resources.Calendars.Insert(customerID, resource).Do()
+	result, err := client.InsertCalendarResource(apiContext(), resource)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating calendar resource: %v\n", err)
-		return err
+		return fmt.Errorf("failed to create calendar resource: %w", err)
 	}
 
 	fmt.Printf("Successfully created calendar resource:\n\n")
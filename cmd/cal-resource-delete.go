@@ -2,9 +2,9 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/spf13/cobra"
+	admin "google.golang.org/api/admin/directory/v1"
 )
 
 var (
@@ -53,27 +53,25 @@ Before deleting a resource:
 
 func init() {
 	calResourceCmd.AddCommand(calResourceDeleteCmd)
+	requireScopes(calResourceDeleteCmd, admin.AdminDirectoryResourceCalendarScope)
 	calResourceDeleteCmd.Flags().BoolVarP(&calResourceDeleteForce, "force", "f", false, "skip confirmation prompt")
 }
 
 func calResourceDeleteRunFunc(cmd *cobra.Command, args []string) error {
-	client, err := newAdminClient()
+	client, err := newDirectoryClient()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating client: %v\n", err)
-		return err
+		return fmt.Errorf("failed to create client: %w", err)
 	}
 
 	resourceId := args[0]
-	customerID := "my_customer"
 
 	// Show warning and prompt for confirmation unless --force or --yes is used
 	// Get the resource details to show the user what they're deleting
 	var additionalInfo string
 	if !calResourceDeleteForce && !skipConfirmations {
-		resource, err := client.Resources.Calendars.Get(customerID, resourceId).Do()
+		resource, err := client.GetCalendarResource(apiContext(), resourceId)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error retrieving calendar resource: %v\n", err)
-			return err
+			return fmt.Errorf("failed to retrieve calendar resource: %w", err)
 		}
 
 		additionalInfo = fmt.Sprintf("Name: %s\nEmail: %s\nType: %s",
@@ -85,14 +83,14 @@ func calResourceDeleteRunFunc(cmd *cobra.Command, args []string) error {
 	}
 
 	// Delete the calendar resource
-	err = client.Resources.Calendars.Delete(customerID, resourceId).Do()
+	err = client.DeleteCalendarResource(apiContext(), resourceId)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error deleting calendar resource: %v\n", err)
-		fmt.Fprintf(os.Stderr, "\nCommon reasons for failure:\n")
-		fmt.Fprintf(os.Stderr, "  - Resource ID is incorrect\n")
-		fmt.Fprintf(os.Stderr, "  - Insufficient permissions\n")
-		fmt.Fprintf(os.Stderr, "  - Resource doesn't exist\n")
-		return err
+		return withHint(fmt.Errorf("failed to delete calendar resource: %w", err),
+			failureReasons(
+				"Resource ID is incorrect",
+				"Insufficient permissions",
+				"Resource doesn't exist",
+			))
 	}
 
 	fmt.Printf("Successfully deleted calendar resource: %s\n", resourceId)
@@ -43,51 +43,42 @@ The --type flag controls what to display:
 
 func init() {
 	calResourceCmd.AddCommand(calResourceListCmd)
+	requireScopes(calResourceListCmd, admin.AdminDirectoryResourceCalendarReadonlyScope)
 	calResourceListCmd.Flags().StringVarP(&calResourceListType, "type", "t", "all", "resource type filter: all, room, equipment, or other")
 }
 
 func calResourceListRunFunc(cmd *cobra.Command, args []string) error {
-	client, err := newAdminClient()
+	client, err := newGACClient()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating client: %v\n", err)
-		return err
+		return fmt.Errorf("failed to create client: %w", err)
 	}
 
-	// Get the customer ID (my_customer for the current domain)
-	customerID := "my_customer"
-
 	// List all buildings first (needed for resource context)
-	buildingsCall := client.Resources.Buildings.List(customerID)
-	buildingsResult, err := buildingsCall.Do()
+	buildings, err := client.Resources.Buildings(apiContext())
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: Could not retrieve buildings: %v\n", err)
 	}
 
 	// Create a map of building IDs to names for display
 	buildingMap := make(map[string]string)
-	if buildingsResult != nil {
-		for _, building := range buildingsResult.Buildings {
-			buildingMap[building.BuildingId] = building.BuildingName
-		}
+	for _, building := range buildings {
+		buildingMap[building.BuildingId] = building.BuildingName
 	}
 
 	// List calendar resources
-	listCall := client.Resources.Calendars.List(customerID)
-
-	result, err := listCall.Do()
+	resources, err := client.Resources.List(apiContext())
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error listing calendar resources: %v\n", err)
-		return err
+		return fmt.Errorf("failed to list calendar resources: %w", err)
 	}
 
-	if len(result.Items) == 0 {
+	if len(resources) == 0 {
 		fmt.Println("No calendar resources found.")
 		return nil
 	}
 
 	// Filter resources by type if specified
 	var filteredResources []*admin.CalendarResource
-	for _, resource := range result.Items {
+	for _, resource := range resources {
 		if calResourceListType == "all" {
 			filteredResources = append(filteredResources, resource)
 		} else if calResourceListType == "room" && resource.ResourceType == "ROOM" {
@@ -146,10 +137,11 @@ func calResourceListRunFunc(cmd *cobra.Command, args []string) error {
 
 	headers := []string{"Name", "Email", "ID", "Type", "Building", "Floor", "Capacity", "Description"}
 
-	// For JSON/YAML, output full resource data
+	// For JSON/YAML, --fields/--query and --filter/--sort-by, output full resource data
 	var outputData interface{}
-	if outputFormat == OutputFormatJSON || outputFormat == OutputFormatYAML {
+	if structuredOutput() {
 		outputData = filteredResources
+		headers = nil // flattened into a column per field
 	} else {
 		outputData = items
 	}
@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/spf13/cobra"
 	admin "google.golang.org/api/admin/directory/v1"
@@ -61,6 +60,7 @@ Examples:
 
 func init() {
 	calResourceCmd.AddCommand(calResourceUpdateCmd)
+	requireScopes(calResourceUpdateCmd, admin.AdminDirectoryResourceCalendarScope)
 	calResourceUpdateCmd.Flags().StringVarP(&updateCalResourceName, "name", "n", "", "resource name")
 	calResourceUpdateCmd.Flags().StringVarP(&updateCalResourceDescription, "description", "d", "", "resource description")
 	calResourceUpdateCmd.Flags().StringVarP(&updateCalResourceCategory, "category", "c", "", "resource category")
@@ -72,20 +72,17 @@ func init() {
 }
 
 func calResourceUpdateRunFunc(cmd *cobra.Command, args []string) error {
-	client, err := newAdminClient()
+	client, err := newDirectoryClient()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating client: %v\n", err)
-		return err
+		return fmt.Errorf("failed to create client: %w", err)
 	}
 
 	resourceId := args[0]
-	customerID := "my_customer"
 
 	// First, get the existing resource to preserve unchanged fields
-	existing, err := client.Resources.Calendars.Get(customerID, resourceId).Do()
+	existing, err := client.GetCalendarResource(apiContext(), resourceId)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error retrieving calendar resource: %v\n", err)
-		return err
+		return fmt.Errorf("failed to retrieve calendar resource: %w", err)
 	}
 
 	// Start with the existing resource
@@ -129,10 +126,9 @@ func calResourceUpdateRunFunc(cmd *cobra.Command, args []string) error {
 		resource.UserVisibleDescription = updateCalResourceUserVisibleDesc
 	}
 
-	result, err := client.Resources.Calendars.Update(customerID, resourceId, resource).Do()
+	result, err := client.UpdateCalendarResource(apiContext(), resourceId, resource)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error updating calendar resource: %v\n", err)
-		return err
+		return fmt.Errorf("failed to update calendar resource: %w", err)
 	}
 
 	fmt.Printf("Successfully updated calendar resource:\n\n")
@@ -24,7 +24,7 @@ var (
 var createCalendarCmd = &cobra.Command{
 	Use:   "create",
 	Short: "create calendar event",
-	Run:   createCalendarRunFunc,
+	RunE:  createCalendarRunFunc,
 	Long: `
 Create calendar event.
 
@@ -38,6 +38,7 @@ $ gac calendar create someRandomCalendarID@example.com -s "short event" -d "with
 
 func init() {
 	calendarCmd.AddCommand(createCalendarCmd)
+	requireScopes(createCalendarCmd, calendar.CalendarEventsScope)
 
 	// Here you will define your flags and configuration settings.
 	createCalendarCmd.Flags().StringSliceVarP(&eventAttendees, "attendee", "a", eventAttendees, "event attendee (multiple ok)")
@@ -50,22 +51,22 @@ func init() {
 	createCalendarCmd.Flags().StringVarP(&eventRecurrenceFreq, "frequency", "f", "daily", "recurrence frequency (daily, weekly, monthly)")
 }
 
-func createCalendarRunFunc(cmd *cobra.Command, args []string) {
+func createCalendarRunFunc(cmd *cobra.Command, args []string) error {
 	var calendarID string
 
 	if len(args) > 0 {
 		calendarID = args[0]
 	} else {
-		exitWithError("must provide calendar ID")
+		return invalidInputf("must provide calendar ID")
 	}
 
 	if eventSummary == "" || eventStart == "" || eventEnd == "" {
-		exitWithError("--summary, --begin and --end required.")
+		return invalidInputf("--summary, --begin and --end required")
 	}
 
 	client, err := newCalendarClient()
 	if err != nil {
-		exitWithError(fmt.Sprintf("unable to create client: %s", err))
+		return fmt.Errorf("unable to create client: %w", err)
 	}
 
 	event := calendar.Event{}
@@ -74,9 +75,10 @@ func createCalendarRunFunc(cmd *cobra.Command, args []string) {
 	buf, _ := json.MarshalIndent(event, "", "  ")
 	fmt.Printf("%s\n", buf)
 
-	e, err := client.Events.Insert(calendarID, &event).Do()
+	e, err := client.Events.Insert(calendarID, &event).Context(apiContext()).Do()
 	if err != nil {
-		exitWithError(err.Error())
+		return err
 	}
 	fmt.Printf("event URL: %s\n", e.HtmlLink)
+	return nil
 }
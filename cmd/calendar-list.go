@@ -18,7 +18,7 @@ var (
 var listCalendarCmd = &cobra.Command{
 	Use:   "list",
 	Short: "list calendar events",
-	Run:   listCalendarRunFunc,
+	RunE:  listCalendarRunFunc,
 	Long: `
 List calendar event(s).
 
@@ -32,42 +32,44 @@ $ gac calendar list someRandomCalendarID@example.com -n 3 --time-min 2011-06-03T
 
 func init() {
 	calendarCmd.AddCommand(listCalendarCmd)
+	requireScopes(listCalendarCmd, calendar.CalendarEventsReadonlyScope)
 	// Here you will define your flags and configuration settings.
 	listCalendarCmd.Flags().Int64VarP(&numEvents, "num-events", "n", 10, "number of events")
 	listCalendarCmd.Flags().StringVarP(&timeMin, "time-min", "", "", "number of events")
 	listCalendarCmd.Flags().StringVarP(&timeMax, "time-max", "", "", "number of events")
 }
 
-func listCalendarRunFunc(cmd *cobra.Command, args []string) {
+func listCalendarRunFunc(cmd *cobra.Command, args []string) error {
 	var calendarID string
 
 	if len(args) > 0 {
 		calendarID = args[0]
 	} else {
-		exitWithError("must provide calendar ID")
+		return invalidInputf("must provide calendar ID")
 	}
 
 	client, err := newCalendarClient()
 	if err != nil {
-		exitWithError(fmt.Sprintf("unable to create client: %s", err))
+		return fmt.Errorf("unable to create client: %w", err)
 	}
 
 	// list events from provided calendarID
 	var c *calendar.Events
 	if timeMin != "" && timeMax != "" {
-		c, err = client.Events.List(calendarID).ShowDeleted(false).SingleEvents(true).TimeMin(timeMin).TimeMax(timeMax).MaxResults(numEvents).OrderBy("startTime").Do()
+		c, err = client.Events.List(calendarID).ShowDeleted(false).SingleEvents(true).TimeMin(timeMin).TimeMax(timeMax).MaxResults(numEvents).OrderBy("startTime").Context(apiContext()).Do()
 	} else if timeMin != "" {
-		c, err = client.Events.List(calendarID).ShowDeleted(false).SingleEvents(true).TimeMin(timeMin).MaxResults(numEvents).OrderBy("startTime").Do()
+		c, err = client.Events.List(calendarID).ShowDeleted(false).SingleEvents(true).TimeMin(timeMin).MaxResults(numEvents).OrderBy("startTime").Context(apiContext()).Do()
 	} else if timeMax != "" {
-		c, err = client.Events.List(calendarID).ShowDeleted(false).SingleEvents(true).TimeMax(timeMax).MaxResults(numEvents).OrderBy("startTime").Do()
+		c, err = client.Events.List(calendarID).ShowDeleted(false).SingleEvents(true).TimeMax(timeMax).MaxResults(numEvents).OrderBy("startTime").Context(apiContext()).Do()
 	} else {
-		c, err = client.Events.List(calendarID).ShowDeleted(false).SingleEvents(true).MaxResults(numEvents).OrderBy("startTime").Do()
+		c, err = client.Events.List(calendarID).ShowDeleted(false).SingleEvents(true).MaxResults(numEvents).OrderBy("startTime").Context(apiContext()).Do()
 	}
 	if err != nil {
-		exitWithError(err.Error())
+		return err
 	}
 
 	if err := FormatOutput(c.Items, nil); err != nil {
-		exitWithError(fmt.Sprintf("Failed to format output: %s", err))
+		return fmt.Errorf("failed to format output: %w", err)
 	}
+	return nil
 }
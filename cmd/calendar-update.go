@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+	calendar "google.golang.org/api/calendar/v3"
 )
 
 // flags / parameters
@@ -16,7 +17,7 @@ var (
 var updateCalendarCmd = &cobra.Command{
 	Use:   "update",
 	Short: "update calendar event",
-	Run:   updateCalendarRunFunc,
+	RunE:  updateCalendarRunFunc,
 	Long: `
 List calendar event(s).
 
@@ -29,6 +30,7 @@ $ gac calendar update someRandomCalendarID@example.com -i randomEventID ...
 
 func init() {
 	calendarCmd.AddCommand(updateCalendarCmd)
+	requireScopes(updateCalendarCmd, calendar.CalendarEventsScope)
 
 	// Here you will define your flags and configuration settings.
 
@@ -55,36 +57,37 @@ func init() {
 	updateCalendarCmd.Flags().StringVarP(&eventID, "event-id", "i", "", "ID of event to update (required)")
 }
 
-func updateCalendarRunFunc(cmd *cobra.Command, args []string) {
+func updateCalendarRunFunc(cmd *cobra.Command, args []string) error {
 	var calendarID string
 
 	if len(args) > 0 {
 		calendarID = args[0]
 	} else {
-		exitWithError("must provide calendar ID")
+		return invalidInputf("must provide calendar ID")
 	}
 
 	if eventID == "" {
-		exitWithError("must provide event ID")
+		return invalidInputf("must provide event ID")
 	}
 
 	client, err := newCalendarClient()
 	if err != nil {
-		exitWithError(fmt.Sprintf("unable to create client: %s", err))
+		return fmt.Errorf("unable to create client: %w", err)
 	}
 
-	event, err := client.Events.Get(calendarID, eventID).Do()
+	event, err := client.Events.Get(calendarID, eventID).Context(apiContext()).Do()
 	if err != nil {
-		exitWithError(err.Error())
+		return err
 	}
 
 	collectEventInfo(event)
 	buf, _ := json.MarshalIndent(event, "", "  ")
 	fmt.Printf("%s\n", buf)
 
-	e, err := client.Events.Update(calendarID, eventID, event).Do()
+	e, err := client.Events.Update(calendarID, eventID, event).Context(apiContext()).Do()
 	if err != nil {
-		exitWithError(err.Error())
+		return err
 	}
 	fmt.Printf("event URL: %s\n", e.HtmlLink)
+	return nil
 }
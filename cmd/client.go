@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"net/url"
 	"os"
@@ -13,37 +12,23 @@ import (
 	"strings"
 
 	"github.com/spf13/viper"
-	"golang.org/x/net/context"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
+	"golang.org/x/oauth2/jwt"
 	datatransfer "google.golang.org/api/admin/datatransfer/v1"
 	admin "google.golang.org/api/admin/directory/v1"
 	reports "google.golang.org/api/admin/reports/v1"
 	calendar "google.golang.org/api/calendar/v3"
-	groupssettings "google.golang.org/api/groupssettings/v1"
-	"google.golang.org/api/option"
 )
 
+// serviceAccountKeyType is the "type" value Google writes into service
+// account key files
+const serviceAccountKeyType = "service_account"
+
 var (
 	clientSecret string
 	cacheFile    string
-
-	scopes = []string{
-		admin.AdminDirectoryUserReadonlyScope,
-		admin.AdminDirectoryUserScope,
-		admin.AdminDirectoryGroupReadonlyScope,
-		admin.AdminDirectoryGroupMemberReadonlyScope,
-		admin.AdminDirectoryGroupMemberScope,
-		admin.AdminDirectoryResourceCalendarReadonlyScope,
-		admin.AdminDirectoryResourceCalendarScope,
-		calendar.CalendarScope,
-		calendar.CalendarReadonlyScope,
-		calendar.CalendarEventsScope,
-		calendar.CalendarEventsReadonlyScope,
-		datatransfer.AdminDatatransferScope,
-		groupssettings.AppsGroupsSettingsScope,
-		reports.AdminReportsAuditReadonlyScope,
-	}
+	impersonate  string
 )
 
 // validateCredentialPath validates that a file path is safe to use for credentials
@@ -123,14 +108,14 @@ func checkFilePermissions(filePath string) {
 }
 
 func newAdminClient() (*admin.Service, error) {
-	client, err := newHTTPClient()
+	client, err := newHTTPClient("admin")
 	if err != nil {
 		return nil, fmt.Errorf("failed to create HTTP client for admin service: %w", err)
 	}
 
-	srv, err := admin.NewService(context.Background(), option.WithHTTPClient(client))
+	srv, err := newDirectoryService(client)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create admin directory service: %w", err)
+		return nil, err
 	}
 
 	LogDebug("Created admin client", map[string]interface{}{
@@ -140,12 +125,17 @@ func newAdminClient() (*admin.Service, error) {
 }
 
 func newCalendarClient() (*calendar.Service, error) {
-	client, err := newHTTPClient()
+	client, err := newHTTPClient("calendar")
 	if err != nil {
 		return nil, fmt.Errorf("failed to create HTTP client for calendar service: %w", err)
 	}
 
-	srv, err := calendar.NewService(context.Background(), option.WithHTTPClient(client))
+	opts, err := serviceOptions("calendar", client)
+	if err != nil {
+		return nil, err
+	}
+
+	srv, err := calendar.NewService(apiContext(), opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create calendar service: %w", err)
 	}
@@ -157,12 +147,17 @@ func newCalendarClient() (*calendar.Service, error) {
 }
 
 func newDataTransferClient() (*datatransfer.Service, error) {
-	client, err := newHTTPClient()
+	client, err := newHTTPClient("datatransfer")
 	if err != nil {
 		return nil, fmt.Errorf("failed to create HTTP client for data transfer service: %w", err)
 	}
 
-	srv, err := datatransfer.NewService(context.Background(), option.WithHTTPClient(client))
+	opts, err := serviceOptions("datatransfer", client)
+	if err != nil {
+		return nil, err
+	}
+
+	srv, err := datatransfer.NewService(apiContext(), opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create data transfer service: %w", err)
 	}
@@ -173,42 +168,118 @@ func newDataTransferClient() (*datatransfer.Service, error) {
 	return srv, nil
 }
 
-func newGroupsSettingsClient() (*groupssettings.Service, error) {
-	client, err := newHTTPClient()
+func newReportsClient() (*reports.Service, error) {
+	client, err := newHTTPClient("reports")
 	if err != nil {
-		return nil, fmt.Errorf("failed to create HTTP client for groups settings service: %w", err)
+		return nil, fmt.Errorf("failed to create HTTP client for reports service: %w", err)
 	}
 
-	srv, err := groupssettings.NewService(context.Background(), option.WithHTTPClient(client))
+	opts, err := serviceOptions("reports", client)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create groups settings service: %w", err)
+		return nil, err
 	}
 
-	LogDebug("Created groups settings client", map[string]interface{}{
-		"service": "groupssettings",
+	srv, err := reports.NewService(apiContext(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create reports service: %w", err)
+	}
+
+	LogDebug("Created reports client", map[string]interface{}{
+		"service": "reports",
 	})
 	return srv, nil
 }
 
-func newReportsClient() (*reports.Service, error) {
-	client, err := newHTTPClient()
+// return an appropriately configured http.Client for requests to services
+func newHTTPClient(services ...string) (*http.Client, error) {
+	// Replayed fixtures need no credentials or network
+	if replayDir != "" {
+		return newReplayClient()
+	}
+
+	noAuth, err := authDisabled(services...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create HTTP client for reports service: %w", err)
+		return nil, err
+	}
+
+	var client *http.Client
+	if noAuth {
+		LogDebug("Authentication disabled for API endpoint override", nil)
+		client = &http.Client{}
+	} else if client, err = newAuthenticatedClient(); err != nil {
+		return nil, err
 	}
+	client.Transport = newAPITransport(client.Transport)
 
-	srv, err := reports.NewService(context.Background(), option.WithHTTPClient(client))
+	// Recording sits outermost so fixtures hold one exchange per logical
+	// call, never see the Authorization header and skip token refreshes
+	if recordDir != "" {
+		if client.Transport, err = withRecording(client.Transport); err != nil {
+			return nil, err
+		}
+	}
+	return client, nil
+}
+
+// newAuthenticatedClient returns an http.Client that authorizes requests
+// with a service account or the cached OAuth2 token
+func newAuthenticatedClient() (*http.Client, error) {
+	b, err := readClientSecret()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create reports service: %w", err)
+		return nil, err
 	}
 
-	LogDebug("Created reports client", map[string]interface{}{
-		"service": "reports",
-	})
-	return srv, nil
+	// Service account keys use the JWT flow with domain-wide delegation
+	// instead of the interactive installed-app flow
+	if isServiceAccountKey(b) {
+		return newServiceAccountClient(b)
+	}
+
+	// Only the scopes the running command declared are requested
+	required := activeScopes()
+	config, err := google.ConfigFromJSON(b, required...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse client secret JSON: %w", err)
+	}
+	cacheFile, err := tokenCacheFile()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token cache file path: %w", err)
+	}
+	tok, granted, err := tokenFromFile(cacheFile)
+	if err == nil && len(granted) == 0 {
+		granted = legacyTokenScopes
+	}
+	var request []string
+	if err != nil {
+		request = required
+	} else if missing := missingScopes(granted, required); len(missing) > 0 {
+		// Incremental consent: ask only for what the cached token lacks;
+		// Google merges it with the scopes granted earlier
+		fmt.Fprintf(os.Stderr, "This command needs additional access: %s\n", strings.Join(missing, " "))
+		request = missing
+	}
+	if request != nil {
+		config.Scopes = request
+		tok, err = getTokenFromWeb(apiContext(), config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to obtain OAuth2 token: %w", err)
+		}
+		granted = mergeScopes(granted, grantedScopes(tok, request))
+		fmt.Fprintf(os.Stderr, "Saving credential file to: %s\n", cacheFile)
+		if err := saveToken(cacheFile, tok, granted); err != nil {
+			return nil, fmt.Errorf("unable to save token: %w", err)
+		}
+	}
+
+	// Refreshed (and possibly rotated) tokens are written back to the cache
+	ts := newPersistingTokenSource(apiContext(), config, cacheFile, tok, granted)
+	return oauth2.NewClient(apiContext(), ts), nil
+
 }
 
-// return an appropriately configured http.Client
-func newHTTPClient() (*http.Client, error) {
+// readClientSecret reads the OAuth2 client secret or service account key
+// configured with --client-secret, GAC_CLIENT_SECRET or the config file
+func readClientSecret() ([]byte, error) {
 	// Get client secret path from viper (supports flags, env vars, and config file)
 	if clientSecret == "" {
 		clientSecret = viper.GetString("client-secret")
@@ -240,46 +311,63 @@ func newHTTPClient() (*http.Client, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to read client secret file %s: %w", clientSecret, err)
 	}
+	return b, nil
+}
 
-	// If modifying these scopes, delete your previously saved credentials
-	// at ~/.credentials/admin-directory_v1-go-quickstart.json
-	config, err := google.ConfigFromJSON(b, scopes...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse client secret JSON: %w", err)
+// isServiceAccountKey reports whether the credential JSON is a service
+// account key rather than an OAuth2 client secret
+func isServiceAccountKey(b []byte) bool {
+	var key struct {
+		Type string `json:"type"`
 	}
-	cacheFile, err := tokenCacheFile()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get token cache file path: %w", err)
+	if err := json.Unmarshal(b, &key); err != nil {
+		return false
+	}
+	return key.Type == serviceAccountKeyType
+}
+
+// getImpersonatedUser returns the admin user a service account acts as
+// when using domain-wide delegation
+func getImpersonatedUser() string {
+	if impersonate != "" {
+		return impersonate
 	}
-	tok, err := tokenFromFile(cacheFile)
+	return viper.GetString("impersonate")
+}
+
+// newServiceAccountClient builds an HTTP client from a service account key
+// using domain-wide delegation to impersonate a Workspace admin
+func newServiceAccountClient(b []byte) (*http.Client, error) {
+	jwtConfig, err := serviceAccountConfig(b)
 	if err != nil {
-		tok = getTokenFromWeb(config)
-		serr := saveToken(cacheFile, tok)
-		if serr != nil {
-			log.Fatalf("Unable to save token: %v", err)
-		}
+		return nil, err
 	}
-	return config.Client(context.Background(), tok), nil
 
-}
+	LogDebug("Using service account credentials", map[string]interface{}{
+		"service_account": jwtConfig.Email,
+		"subject":         jwtConfig.Subject,
+	})
 
-// getTokenFromWeb uses Config to request a Token.
-// It returns the retrieved Token.
-func getTokenFromWeb(config *oauth2.Config) *oauth2.Token {
-	authURL := config.AuthCodeURL("state-token", oauth2.AccessTypeOffline)
-	fmt.Printf("Go to the following link in your browser then type the "+
-		"authorization code: \n%v\n", authURL)
+	return jwtConfig.Client(apiContext()), nil
+}
 
-	var code string
-	if _, err := fmt.Scan(&code); err != nil {
-		log.Fatalf("Unable to read authorization code %v", err)
+// serviceAccountConfig parses a service account key and sets the admin to
+// impersonate
+func serviceAccountConfig(b []byte) (*jwt.Config, error) {
+	jwtConfig, err := google.JWTConfigFromJSON(b, activeScopes()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse service account key JSON: %w", err)
 	}
 
-	tok, err := config.Exchange(context.Background(), code)
-	if err != nil {
-		log.Fatalf("Unable to retrieve token from web %v", err)
+	subject := getImpersonatedUser()
+	if subject == "" {
+		return nil, errors.New("service account credentials require an admin to impersonate (set --impersonate, GAC_IMPERSONATE, or 'impersonate' in the config file)")
+	}
+	if err := ValidateEmail(subject); err != nil {
+		return nil, fmt.Errorf("invalid impersonated user: %w", err)
 	}
-	return tok
+	jwtConfig.Subject = subject
+	return jwtConfig, nil
 }
 
 // tokenCacheFile generates credential file path/filename.
@@ -301,65 +389,132 @@ func tokenCacheFile() (string, error) {
 		if err != nil {
 			return "", fmt.Errorf("failed to create token cache directory %s: %w", tokenCacheDir, err)
 		}
+		// Each profile gets its own token so tenants never share credentials
+		name := "gac.json"
+		if profile := activeProfile(); profile != "" {
+			name = "gac-" + profile + ".json"
+		}
 		return filepath.Join(tokenCacheDir,
-			url.QueryEscape("gac.json")), err
+			url.QueryEscape(name)), err
 	}
 
 	return cacheFile, nil
 }
 
-// tokenFromFile retrieves a Token from a given file path.
+// storedToken is the token cache file format: the OAuth2 token plus the
+// scopes it was granted, so gac knows when more consent is needed
+type storedToken struct {
+	*oauth2.Token
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+// tokenFromFile retrieves a Token and its granted scopes from a given file path.
 // It returns the retrieved Token and any read error encountered.
-func tokenFromFile(file string) (*oauth2.Token, error) {
+func tokenFromFile(file string) (*oauth2.Token, []string, error) {
 	// Validate credential file path to prevent directory traversal
 	if err := validateCredentialPath(file); err != nil {
-		return nil, fmt.Errorf("invalid token file path: %w", err)
+		return nil, nil, fmt.Errorf("invalid token file path: %w", err)
 	}
 
+	unlock, err := lockFile(file, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer unlock()
+
+	return readTokenFile(file)
+}
+
+// readTokenFile decodes a token file; callers must hold the token lock.
+// Caches written by older gac versions have no scopes recorded.
+func readTokenFile(file string) (*oauth2.Token, []string, error) {
 	// Check file permissions and warn if insecure
 	checkFilePermissions(file)
 
-	// #nosec G304 - Path is validated by validateCredentialPath() above
-	f, err := os.Open(file)
+	// #nosec G304 - Path is validated by the caller
+	b, err := os.ReadFile(file)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	if b, err = openSealed(b, sealPurposeToken); err != nil {
+		return nil, nil, fmt.Errorf("failed to decrypt token: %w", err)
 	}
-	defer func() {
-		cerr := f.Close()
-		if err == nil {
-			err = cerr
-		}
-	}()
-
-	t := &oauth2.Token{}
-	err = json.NewDecoder(f).Decode(t)
 
-	return t, err
+	var t storedToken
+	if err := json.Unmarshal(b, &t); err != nil {
+		return nil, nil, err
+	}
+	if t.Token == nil {
+		return nil, nil, errors.New("token file contains no token")
+	}
+	return t.Token, t.Scopes, nil
 }
 
-// saveToken uses a file path to create a file and store the
-// token in it.
-func saveToken(file string, token *oauth2.Token) (err error) {
+// saveToken atomically writes the token to the given file path, holding
+// an exclusive lock so parallel gac processes cannot interleave writes.
+func saveToken(file string, token *oauth2.Token, scopes []string) error {
 	// Validate credential file path to prevent directory traversal
 	if err := validateCredentialPath(file); err != nil {
 		return fmt.Errorf("invalid token save path: %w", err)
 	}
 
-	fmt.Printf("Saving credential file to: %s\n", file)
-	// #nosec G304 - Path is validated by validateCredentialPath() above
-	f, err := os.OpenFile(file, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	unlock, err := lockFile(file, true)
 	if err != nil {
-		return
+		return err
+	}
+	defer unlock()
+
+	return writeTokenFile(file, token, scopes)
+}
+
+// writeTokenFile writes the token to a temporary file in the same directory
+// and renames it into place; callers must hold the token lock
+func writeTokenFile(file string, token *oauth2.Token, scopes []string) error {
+	b, err := json.Marshal(storedToken{Token: token, Scopes: scopes})
+	if err != nil {
+		return fmt.Errorf("failed to encode token: %w", err)
 	}
+	if b, err = sealIfEnabled(b, sealPurposeToken); err != nil {
+		return fmt.Errorf("failed to encrypt token: %w", err)
+	}
+
+	LogDebug("Saving credential file", map[string]interface{}{
+		"path": file,
+	})
+	return writeFileAtomic(file, b, 0600)
+}
 
+// writeFileAtomic writes data to a temp file next to path, syncs it and
+// renames it over path so readers never observe a partial file
+func writeFileAtomic(path string, data []byte, perm os.FileMode) (err error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpName := tmp.Name()
 	defer func() {
-		cerr := f.Close()
-		if err == nil {
-			err = cerr
+		if err != nil {
+			_ = os.Remove(tmpName)
 		}
 	}()
 
-	err = json.NewEncoder(f).Encode(token)
-
-	return
+	if err = tmp.Chmod(perm); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to set permissions on temp file: %w", err)
+	}
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err = tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to sync temp file: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temp file: %w", err)
+	}
+	if err = os.Rename(tmpName, path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
 }
@@ -0,0 +1,583 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/acockrell/google-admin-client/pkg/gac"
+	admin "google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/googleapi"
+	groupssettings "google.golang.org/api/groupssettings/v1"
+)
+
+// fakeDirectoryClient is an in-memory gac.Directory. Writes change its
+// state, so multi-step flows can be tested, and errors are *googleapi.Error
+// values like the real API returns.
+type fakeDirectoryClient struct {
+	mu sync.Mutex
+
+	// pageSize limits the items in each page of paged list calls
+	pageSize int
+
+	users         map[string]*admin.User
+	aliases       map[string][]string
+	groups        map[string]*admin.Group
+	members       map[string][]*admin.Member
+	orgUnits      map[string]*admin.OrgUnit
+	resources     map[string]*admin.CalendarResource
+	buildings     []*admin.Building
+	groupSettings map[string]*groupssettings.Groups
+
+	// memberLists counts ListMembers calls
+	memberLists int
+
+	nextID int
+}
+
+func newFakeDirectoryClient() *fakeDirectoryClient {
+	return &fakeDirectoryClient{
+		pageSize:      100,
+		users:         map[string]*admin.User{},
+		aliases:       map[string][]string{},
+		groups:        map[string]*admin.Group{},
+		members:       map[string][]*admin.Member{},
+		orgUnits:      map[string]*admin.OrgUnit{},
+		resources:     map[string]*admin.CalendarResource{},
+		groupSettings: map[string]*groupssettings.Groups{},
+	}
+}
+
+// withFakeDirectory makes commands use a new fake for one test
+func withFakeDirectory(t *testing.T) *fakeDirectoryClient {
+	t.Helper()
+	fake := newFakeDirectoryClient()
+	original := newDirectoryClient
+	newDirectoryClient = func() (gac.Directory, error) { return fake, nil }
+	t.Cleanup(func() { newDirectoryClient = original })
+	return fake
+}
+
+func fakeError(code int, format string, args ...interface{}) error {
+	return &googleapi.Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// clone deep-copies an API object through its JSON form
+func clone[T any](v *T) *T {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	out := new(T)
+	if err := json.Unmarshal(b, out); err != nil {
+		panic(err)
+	}
+	return out
+}
+
+// patch applies the fields an update request would send, honouring
+// ForceSendFields and NullFields
+func patch[T any](existing, update *T) *T {
+	out := clone(existing)
+	b, err := json.Marshal(update)
+	if err != nil {
+		panic(err)
+	}
+	if err := json.Unmarshal(b, out); err != nil {
+		panic(err)
+	}
+	return out
+}
+
+// page returns the page of items starting at pageToken
+func page[T any](items []T, pageToken string, size int) ([]T, string, error) {
+	start := 0
+	if pageToken != "" {
+		n, err := strconv.Atoi(pageToken)
+		if err != nil || n < 0 || n > len(items) {
+			return nil, "", fakeError(http.StatusBadRequest, "Invalid page token %q", pageToken)
+		}
+		start = n
+	}
+	end := start + size
+	if end >= len(items) {
+		return items[start:], "", nil
+	}
+	return items[start:end], strconv.Itoa(end), nil
+}
+
+func (f *fakeDirectoryClient) newID() string {
+	f.nextID++
+	return fmt.Sprintf("%06d", f.nextID)
+}
+
+// addUser seeds a user; the OU defaults to /
+func (f *fakeDirectoryClient) addUser(u *admin.User) *admin.User {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	u = clone(u)
+	if u.Id == "" {
+		u.Id = f.newID()
+	}
+	if u.OrgUnitPath == "" {
+		u.OrgUnitPath = "/"
+	}
+	f.users[strings.ToLower(u.PrimaryEmail)] = u
+	return u
+}
+
+// addGroup seeds a group and its members
+func (f *fakeDirectoryClient) addGroup(g *admin.Group, members ...*admin.Member) *admin.Group {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	g = clone(g)
+	if g.Id == "" {
+		g.Id = f.newID()
+	}
+	key := strings.ToLower(g.Email)
+	f.groups[key] = g
+	for _, m := range members {
+		f.members[key] = append(f.members[key], clone(m))
+	}
+	return g
+}
+
+// user returns a copy of the stored user, or nil
+func (f *fakeDirectoryClient) user(email string) *admin.User {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if u := f.findUser(email); u != nil {
+		return clone(u)
+	}
+	return nil
+}
+
+func (f *fakeDirectoryClient) findUser(userKey string) *admin.User {
+	key := strings.ToLower(userKey)
+	if u, ok := f.users[key]; ok {
+		return u
+	}
+	for email, aliases := range f.aliases {
+		for _, a := range aliases {
+			if strings.EqualFold(a, userKey) {
+				return f.users[email]
+			}
+		}
+	}
+	for _, u := range f.users {
+		if u.Id == userKey {
+			return u
+		}
+	}
+	return nil
+}
+
+func (f *fakeDirectoryClient) findGroup(groupKey string) (string, *admin.Group) {
+	key := strings.ToLower(groupKey)
+	if g, ok := f.groups[key]; ok {
+		return key, g
+	}
+	for k, g := range f.groups {
+		if g.Id == groupKey {
+			return k, g
+		}
+	}
+	return "", nil
+}
+
+func (f *fakeDirectoryClient) GetUser(_ context.Context, userKey string, opts ...googleapi.CallOption) (*admin.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	u := f.findUser(userKey)
+	if u == nil {
+		return nil, fakeError(http.StatusNotFound, "Resource Not Found: userKey")
+	}
+	return clone(u), nil
+}
+
+func (f *fakeDirectoryClient) ListUsers(_ context.Context, pageToken string) (*admin.Users, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var users []*admin.User
+	for _, u := range f.users {
+		users = append(users, clone(u))
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].PrimaryEmail < users[j].PrimaryEmail })
+
+	items, next, err := page(users, pageToken, f.pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return &admin.Users{Users: items, NextPageToken: next}, nil
+}
+
+func (f *fakeDirectoryClient) InsertUser(_ context.Context, user *admin.User) (*admin.User, error) {
+	f.mu.Lock()
+	exists := f.findUser(user.PrimaryEmail) != nil
+	f.mu.Unlock()
+	if exists {
+		return nil, fakeError(http.StatusConflict, "Entity already exists.")
+	}
+	u := clone(user)
+	u.Password = ""
+	return f.addUser(u), nil
+}
+
+func (f *fakeDirectoryClient) UpdateUser(_ context.Context, userKey string, user *admin.User) (*admin.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	u := f.findUser(userKey)
+	if u == nil {
+		return nil, fakeError(http.StatusNotFound, "Resource Not Found: userKey")
+	}
+	updated := patch(u, user)
+	updated.Password = ""
+	f.users[strings.ToLower(u.PrimaryEmail)] = updated
+	return clone(updated), nil
+}
+
+func (f *fakeDirectoryClient) ListAliases(_ context.Context, userKey string) (*admin.Aliases, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	u := f.findUser(userKey)
+	if u == nil {
+		return nil, fakeError(http.StatusNotFound, "Resource Not Found: userKey")
+	}
+	result := &admin.Aliases{}
+	for _, a := range f.aliases[strings.ToLower(u.PrimaryEmail)] {
+		result.Aliases = append(result.Aliases, map[string]interface{}{
+			"alias":        a,
+			"primaryEmail": u.PrimaryEmail,
+		})
+	}
+	return result, nil
+}
+
+func (f *fakeDirectoryClient) InsertAlias(_ context.Context, userKey string, alias *admin.Alias) (*admin.Alias, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	u := f.findUser(userKey)
+	if u == nil {
+		return nil, fakeError(http.StatusNotFound, "Resource Not Found: userKey")
+	}
+	if f.findUser(alias.Alias) != nil {
+		return nil, fakeError(http.StatusConflict, "Entity already exists.")
+	}
+	key := strings.ToLower(u.PrimaryEmail)
+	f.aliases[key] = append(f.aliases[key], alias.Alias)
+	return &admin.Alias{Alias: alias.Alias, PrimaryEmail: u.PrimaryEmail, Id: u.Id}, nil
+}
+
+func (f *fakeDirectoryClient) DeleteAlias(_ context.Context, userKey, alias string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	u := f.findUser(userKey)
+	if u == nil {
+		return fakeError(http.StatusNotFound, "Resource Not Found: userKey")
+	}
+	key := strings.ToLower(u.PrimaryEmail)
+	for i, a := range f.aliases[key] {
+		if strings.EqualFold(a, alias) {
+			f.aliases[key] = append(f.aliases[key][:i], f.aliases[key][i+1:]...)
+			return nil
+		}
+	}
+	return fakeError(http.StatusNotFound, "Resource Not Found: alias")
+}
+
+func (f *fakeDirectoryClient) GetGroup(_ context.Context, groupKey string) (*admin.Group, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, g := f.findGroup(groupKey)
+	if g == nil {
+		return nil, fakeError(http.StatusNotFound, "Resource Not Found: groupKey")
+	}
+	return clone(g), nil
+}
+
+func (f *fakeDirectoryClient) ListGroups(_ context.Context, pageToken string) (*admin.Groups, error) {
+	return f.listGroups(pageToken, func(string) bool { return true })
+}
+
+func (f *fakeDirectoryClient) ListUserGroups(_ context.Context, userKey, pageToken string) (*admin.Groups, error) {
+	return f.listGroups(pageToken, func(key string) bool {
+		for _, m := range f.members[key] {
+			if strings.EqualFold(m.Email, userKey) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func (f *fakeDirectoryClient) listGroups(pageToken string, include func(key string) bool) (*admin.Groups, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var groups []*admin.Group
+	for key, g := range f.groups {
+		if include(key) {
+			groups = append(groups, clone(g))
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Email < groups[j].Email })
+
+	items, next, err := page(groups, pageToken, f.pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return &admin.Groups{Groups: items, NextPageToken: next}, nil
+}
+
+func (f *fakeDirectoryClient) ListMembers(_ context.Context, groupKey, pageToken string) (*admin.Members, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.memberLists++
+	key, g := f.findGroup(groupKey)
+	if g == nil {
+		return nil, fakeError(http.StatusNotFound, "Resource Not Found: groupKey")
+	}
+	var members []*admin.Member
+	for _, m := range f.members[key] {
+		members = append(members, clone(m))
+	}
+
+	items, next, err := page(members, pageToken, f.pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return &admin.Members{Members: items, NextPageToken: next}, nil
+}
+
+func (f *fakeDirectoryClient) InsertMember(_ context.Context, groupKey string, member *admin.Member) (*admin.Member, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key, g := f.findGroup(groupKey)
+	if g == nil {
+		return nil, fakeError(http.StatusNotFound, "Resource Not Found: groupKey")
+	}
+	for _, m := range f.members[key] {
+		if strings.EqualFold(m.Email, member.Email) {
+			return nil, fakeError(http.StatusConflict, "Member already exists.")
+		}
+	}
+	m := clone(member)
+	if m.Role == "" {
+		m.Role = "MEMBER"
+	}
+	if m.Type == "" {
+		m.Type = "USER"
+	}
+	f.members[key] = append(f.members[key], m)
+	return clone(m), nil
+}
+
+func (f *fakeDirectoryClient) DeleteMember(_ context.Context, groupKey, memberKey string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key, g := f.findGroup(groupKey)
+	if g == nil {
+		return fakeError(http.StatusNotFound, "Resource Not Found: groupKey")
+	}
+	for i, m := range f.members[key] {
+		if strings.EqualFold(m.Email, memberKey) {
+			f.members[key] = append(f.members[key][:i], f.members[key][i+1:]...)
+			return nil
+		}
+	}
+	return fakeError(http.StatusNotFound, "Resource Not Found: memberKey")
+}
+
+// orgUnitPath joins a parent path and an OU name
+func orgUnitPath(parent, name string) string {
+	return strings.TrimSuffix(parent, "/") + "/" + name
+}
+
+func (f *fakeDirectoryClient) ListOrgUnits(_ context.Context, path, listType string) (*admin.OrgUnits, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	parent := path
+	if parent == "" {
+		parent = "/"
+	}
+	if parent != "/" && f.orgUnits[parent] == nil {
+		return nil, fakeError(http.StatusNotFound, "Org unit not found")
+	}
+
+	var units []*admin.OrgUnit
+	for p, ou := range f.orgUnits {
+		switch {
+		case listType == "children" && ou.ParentOrgUnitPath == parent:
+		case listType != "children" && (parent == "/" || strings.HasPrefix(p, parent+"/")):
+		default:
+			continue
+		}
+		units = append(units, clone(ou))
+	}
+	sort.Slice(units, func(i, j int) bool { return units[i].OrgUnitPath < units[j].OrgUnitPath })
+	return &admin.OrgUnits{OrganizationUnits: units}, nil
+}
+
+func (f *fakeDirectoryClient) InsertOrgUnit(_ context.Context, ou *admin.OrgUnit) (*admin.OrgUnit, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	parent := ou.ParentOrgUnitPath
+	if parent == "" {
+		parent = "/"
+	}
+	if parent != "/" && f.orgUnits[parent] == nil {
+		return nil, fakeError(http.StatusBadRequest, "Invalid parent orgunit")
+	}
+	path := orgUnitPath(parent, ou.Name)
+	if f.orgUnits[path] != nil {
+		return nil, fakeError(http.StatusConflict, "Invalid Ou Id")
+	}
+
+	created := clone(ou)
+	created.ParentOrgUnitPath = parent
+	created.OrgUnitPath = path
+	created.OrgUnitId = "id:" + f.newID()
+	f.orgUnits[path] = created
+	return clone(created), nil
+}
+
+func (f *fakeDirectoryClient) UpdateOrgUnit(_ context.Context, path string, ou *admin.OrgUnit) (*admin.OrgUnit, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	existing := f.orgUnits[path]
+	if existing == nil {
+		return nil, fakeError(http.StatusNotFound, "Org unit not found")
+	}
+	updated := patch(existing, ou)
+	updated.OrgUnitPath = orgUnitPath(updated.ParentOrgUnitPath, updated.Name)
+	delete(f.orgUnits, path)
+	f.orgUnits[updated.OrgUnitPath] = updated
+	return clone(updated), nil
+}
+
+func (f *fakeDirectoryClient) DeleteOrgUnit(_ context.Context, path string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.orgUnits[path] == nil {
+		return fakeError(http.StatusNotFound, "Org unit not found")
+	}
+	for p := range f.orgUnits {
+		if strings.HasPrefix(p, path+"/") {
+			return fakeError(http.StatusBadRequest, "Org unit has child org units")
+		}
+	}
+	for _, u := range f.users {
+		if u.OrgUnitPath == path {
+			return fakeError(http.StatusBadRequest, "Org unit contains users")
+		}
+	}
+	delete(f.orgUnits, path)
+	return nil
+}
+
+func (f *fakeDirectoryClient) GetCalendarResource(_ context.Context, resourceID string) (*admin.CalendarResource, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	r := f.resources[resourceID]
+	if r == nil {
+		return nil, fakeError(http.StatusNotFound, "Resource Not Found: resourceId")
+	}
+	return clone(r), nil
+}
+
+func (f *fakeDirectoryClient) ListCalendarResources(_ context.Context, pageToken string) (*admin.CalendarResources, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var resources []*admin.CalendarResource
+	for _, r := range f.resources {
+		resources = append(resources, clone(r))
+	}
+	sort.Slice(resources, func(i, j int) bool { return resources[i].ResourceId < resources[j].ResourceId })
+
+	items, next, err := page(resources, pageToken, f.pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return &admin.CalendarResources{Items: items, NextPageToken: next}, nil
+}
+
+func (f *fakeDirectoryClient) InsertCalendarResource(_ context.Context, resource *admin.CalendarResource) (*admin.CalendarResource, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.resources[resource.ResourceId] != nil {
+		return nil, fakeError(http.StatusConflict, "Entity already exists.")
+	}
+	r := clone(resource)
+	r.ResourceEmail = "c_" + r.ResourceId + "@resource.calendar.google.com"
+	f.resources[r.ResourceId] = r
+	return clone(r), nil
+}
+
+func (f *fakeDirectoryClient) UpdateCalendarResource(_ context.Context, resourceID string, resource *admin.CalendarResource) (*admin.CalendarResource, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	existing := f.resources[resourceID]
+	if existing == nil {
+		return nil, fakeError(http.StatusNotFound, "Resource Not Found: resourceId")
+	}
+	updated := patch(existing, resource)
+	f.resources[resourceID] = updated
+	return clone(updated), nil
+}
+
+func (f *fakeDirectoryClient) DeleteCalendarResource(_ context.Context, resourceID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.resources[resourceID] == nil {
+		return fakeError(http.StatusNotFound, "Resource Not Found: resourceId")
+	}
+	delete(f.resources, resourceID)
+	return nil
+}
+
+func (f *fakeDirectoryClient) ListBuildings(_ context.Context, pageToken string) (*admin.Buildings, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	items, next, err := page(f.buildings, pageToken, f.pageSize)
+	if err != nil {
+		return nil, err
+	}
+	return &admin.Buildings{Buildings: items, NextPageToken: next}, nil
+}
+
+func (f *fakeDirectoryClient) GetGroupSettings(_ context.Context, groupEmail string) (*groupssettings.Groups, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key, g := f.findGroup(groupEmail)
+	if g == nil {
+		return nil, fakeError(http.StatusNotFound, "Resource Not Found: groupUniqueId")
+	}
+	if s := f.groupSettings[key]; s != nil {
+		return clone(s), nil
+	}
+	return &groupssettings.Groups{Email: g.Email, Name: g.Name}, nil
+}
+
+func (f *fakeDirectoryClient) UpdateGroupSettings(_ context.Context, groupEmail string, settings *groupssettings.Groups) (*groupssettings.Groups, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key, g := f.findGroup(groupEmail)
+	if g == nil {
+		return nil, fakeError(http.StatusNotFound, "Resource Not Found: groupUniqueId")
+	}
+	existing := f.groupSettings[key]
+	if existing == nil {
+		existing = &groupssettings.Groups{Email: g.Email, Name: g.Name}
+	}
+	updated := patch(existing, settings)
+	f.groupSettings[key] = updated
+	return clone(updated), nil
+}
+
+// Compile-time check that the fake satisfies the interface
+var _ gac.Directory = (*fakeDirectoryClient)(nil)
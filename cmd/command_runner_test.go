@@ -1,15 +1,26 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 	"testing"
 
+	"github.com/acockrell/google-admin-client/pkg/gac"
+	"github.com/acockrell/google-admin-client/pkg/gactest"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	admin "google.golang.org/api/admin/directory/v1"
 	"google.golang.org/api/googleapi"
 )
 
-// mockAdminClient implements adminClientInterface for testing
+// mockAdminClient overrides the gac.Directory calls user creation makes;
+// the rest fall through to the embedded client
 type mockAdminClient struct {
+	gac.Directory
 	insertUserFunc   func(*admin.User) (*admin.User, error)
 	getUserFunc      func(string) (*admin.User, error)
 	listUsersFunc    func() (*admin.Users, error)
@@ -17,35 +28,35 @@ type mockAdminClient struct {
 	listMembersFunc  func(string) (*admin.Members, error)
 }
 
-func (m *mockAdminClient) InsertUser(user *admin.User) (*admin.User, error) {
+func (m *mockAdminClient) InsertUser(_ context.Context, user *admin.User) (*admin.User, error) {
 	if m.insertUserFunc != nil {
 		return m.insertUserFunc(user)
 	}
 	return user, nil
 }
 
-func (m *mockAdminClient) GetUser(email string) (*admin.User, error) {
+func (m *mockAdminClient) GetUser(_ context.Context, email string, opts ...googleapi.CallOption) (*admin.User, error) {
 	if m.getUserFunc != nil {
 		return m.getUserFunc(email)
 	}
 	return nil, &googleapi.Error{Code: 404, Message: "User not found"}
 }
 
-func (m *mockAdminClient) ListUsers() (*admin.Users, error) {
+func (m *mockAdminClient) ListUsers(_ context.Context, pageToken string) (*admin.Users, error) {
 	if m.listUsersFunc != nil {
 		return m.listUsersFunc()
 	}
 	return &admin.Users{Users: []*admin.User{}}, nil
 }
 
-func (m *mockAdminClient) InsertMember(groupEmail string, member *admin.Member) (*admin.Member, error) {
+func (m *mockAdminClient) InsertMember(_ context.Context, groupEmail string, member *admin.Member) (*admin.Member, error) {
 	if m.insertMemberFunc != nil {
 		return m.insertMemberFunc(groupEmail, member)
 	}
 	return member, nil
 }
 
-func (m *mockAdminClient) ListMembers(groupEmail string) (*admin.Members, error) {
+func (m *mockAdminClient) ListMembers(_ context.Context, groupEmail, pageToken string) (*admin.Members, error) {
 	if m.listMembersFunc != nil {
 		return m.listMembersFunc(groupEmail)
 	}
@@ -206,7 +217,7 @@ func TestCreateUserWithClient(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Create mock client
-			mockClient := &mockAdminClient{}
+			mockClient := &mockAdminClient{Directory: newFakeDirectoryClient()}
 			if tt.setupMock != nil {
 				tt.setupMock(mockClient)
 			}
@@ -236,7 +247,7 @@ func TestCreateUserWithClient(t *testing.T) {
 			}
 
 			// Execute the function
-			err := createUserWithClient(mockClient, tt.args, tt.flags)
+			_, err := createUserWithClient(mockClient, tt.args, tt.flags)
 
 			// Verify results
 			if tt.wantErr {
@@ -264,3 +275,480 @@ func TestCreateUserWithClient(t *testing.T) {
 		})
 	}
 }
+
+// captureStdout returns what fn writes to stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = original }()
+
+	out := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		out <- string(b)
+	}()
+	fn()
+	_ = w.Close()
+	return <-out
+}
+
+// withRunnerGlobals resets the output and cache settings runners read and
+// restores them after the test
+func withRunnerGlobals(t *testing.T, format OutputFormat) {
+	t.Helper()
+	originalFormat, originalQuiet, originalNoCache := outputFormat, quietMode, noCacheFlag
+	t.Cleanup(func() {
+		outputFormat, quietMode, noCacheFlag = originalFormat, originalQuiet, originalNoCache
+	})
+	outputFormat, quietMode, noCacheFlag = format, false, true
+}
+
+func TestUserSuspendFlow(t *testing.T) {
+	fake := withFakeDirectory(t)
+	withRunnerGlobals(t, OutputFormatJSON)
+	originalSuspend, originalUnsuspend := suspendForce, unsuspendForce
+	defer func() { suspendForce, unsuspendForce = originalSuspend, originalUnsuspend }()
+	suspendForce, unsuspendForce = true, true
+
+	fake.addUser(&admin.User{PrimaryEmail: "jdoe@example.com", Name: &admin.UserName{GivenName: "Jane", FamilyName: "Doe"}})
+
+	out := captureStdout(t, func() {
+		if err := userSuspendRunFunc(userSuspendCmd, []string{"jdoe@example.com"}); err != nil {
+			t.Fatalf("userSuspendRunFunc() error = %v", err)
+		}
+	})
+	if !strings.Contains(out, "Suspended: true") || !fake.user("jdoe@example.com").Suspended {
+		t.Fatalf("user not suspended, output:\n%s", out)
+	}
+
+	out = captureStdout(t, func() {
+		if err := listUserRunFunc(listUserCmd, nil); err != nil {
+			t.Fatalf("listUserRunFunc() error = %v", err)
+		}
+	})
+	var users []admin.User
+	if err := json.Unmarshal([]byte(out), &users); err != nil {
+		t.Fatalf("user list output is not JSON: %v\n%s", err, out)
+	}
+	if len(users) != 1 || !users[0].Suspended {
+		t.Errorf("user list = %+v, want the suspended user", users)
+	}
+
+	captureStdout(t, func() {
+		if err := userUnsuspendRunFunc(userUnsuspendCmd, []string{"jdoe@example.com"}); err != nil {
+			t.Fatalf("userUnsuspendRunFunc() error = %v", err)
+		}
+	})
+	if fake.user("jdoe@example.com").Suspended {
+		t.Error("user still suspended after unsuspend")
+	}
+
+	if err := userSuspendRunFunc(userSuspendCmd, []string{"nobody@example.com"}); err == nil {
+		t.Error("suspending an unknown user succeeded, want error")
+	}
+}
+
+func TestUpdateUserRunFuncGroups(t *testing.T) {
+	fake := withFakeDirectory(t)
+	withRunnerGlobals(t, OutputFormatPlain)
+	originalGroups, originalTitle := groups, title
+	defer func() { groups, title = originalGroups, originalTitle }()
+
+	fake.addUser(&admin.User{PrimaryEmail: "jdoe@example.com"})
+	fake.addGroup(&admin.Group{Email: "eng@example.com", Name: "Engineering"})
+	fake.addGroup(&admin.Group{Email: "ops@example.com", Name: "Ops"})
+
+	tests := []struct {
+		name  string
+		group string
+		title string
+	}{
+		{name: "group only", group: "eng@example.com"},
+		{name: "group and field", group: "ops@example.com", title: "Engineer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups, title = []string{tt.group}, tt.title
+			if err := updateUserRunFunc(updateUserCmd, []string{"jdoe@example.com"}); err != nil {
+				t.Fatalf("updateUserRunFunc() error = %v", err)
+			}
+			res, err := fake.ListMembers(context.Background(), tt.group, "")
+			if err != nil {
+				t.Fatalf("ListMembers() error = %v", err)
+			}
+			if len(res.Members) != 1 || res.Members[0].Email != "jdoe@example.com" {
+				t.Errorf("members of %s = %+v, want jdoe@example.com", tt.group, res.Members)
+			}
+		})
+	}
+
+	// A failed group add is reported with its API error kind
+	groups, title = []string{"missing@example.com"}, "Engineer"
+	err := updateUserRunFunc(updateUserCmd, []string{"jdoe@example.com"})
+	if kind := classifyError(err); kind != kindNotFound {
+		t.Errorf("updateUserRunFunc() error = %v, kind %v, want %v", err, kind, kindNotFound)
+	}
+}
+
+// TestUserSuspendFlowFakeWorkspace runs the same flow through the real API
+// client against the gactest fake, with --api-endpoint and --no-auth
+func TestUserSuspendFlowFakeWorkspace(t *testing.T) {
+	srv := gactest.NewServer(nil)
+	defer srv.Close()
+	srv.PageSize = 2
+	err := srv.Load([]byte(`
+users:
+  - primaryEmail: jdoe@example.com
+    name: {givenName: Jane, familyName: Doe}
+  - primaryEmail: asmith@example.com
+    name: {givenName: Alex, familyName: Smith}
+  - primaryEmail: bwhite@example.com
+    name: {givenName: Blake, familyName: White}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	withEndpointSettings(t, []string{srv.URL}, nil)
+	viper.Set("no-auth", true)
+	withRunnerGlobals(t, OutputFormatJSON)
+	originalSuspend, originalUnsuspend := suspendForce, unsuspendForce
+	defer func() { suspendForce, unsuspendForce = originalSuspend, originalUnsuspend }()
+	suspendForce, unsuspendForce = true, true
+
+	captureStdout(t, func() {
+		if err := userSuspendRunFunc(userSuspendCmd, []string{"jdoe@example.com"}); err != nil {
+			t.Fatalf("userSuspendRunFunc() error = %v", err)
+		}
+	})
+
+	out := captureStdout(t, func() {
+		if err := listUserRunFunc(listUserCmd, nil); err != nil {
+			t.Fatalf("listUserRunFunc() error = %v", err)
+		}
+	})
+	var users []admin.User
+	if err := json.Unmarshal([]byte(out), &users); err != nil {
+		t.Fatalf("user list output is not JSON: %v\n%s", err, out)
+	}
+	suspended := map[string]bool{}
+	for _, u := range users {
+		suspended[u.PrimaryEmail] = u.Suspended
+	}
+	if len(users) != 3 || !suspended["jdoe@example.com"] || suspended["asmith@example.com"] {
+		t.Errorf("user list = %v, want all three users across pages with jdoe suspended", suspended)
+	}
+
+	captureStdout(t, func() {
+		if err := userUnsuspendRunFunc(userUnsuspendCmd, []string{"jdoe@example.com"}); err != nil {
+			t.Fatalf("userUnsuspendRunFunc() error = %v", err)
+		}
+	})
+	client, err := newDirectoryClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u, err := client.GetUser(context.Background(), "jdoe@example.com"); err != nil || u.Suspended {
+		t.Errorf("GetUser() after unsuspend = %+v, %v, want an active user", u, err)
+	}
+}
+
+func TestListUserRunFuncPages(t *testing.T) {
+	fake := withFakeDirectory(t)
+	withRunnerGlobals(t, OutputFormatJSON)
+	originalDisabled := disabledOnly
+	defer func() { disabledOnly = originalDisabled }()
+
+	fake.pageSize = 2
+	for i := 0; i < 5; i++ {
+		u := &admin.User{PrimaryEmail: fmt.Sprintf("user%d@example.com", i), Name: &admin.UserName{FullName: "User"}}
+		if i == 3 {
+			u.OrgUnitPath = "/Former employees"
+		}
+		fake.addUser(u)
+	}
+
+	tests := []struct {
+		name         string
+		disabledOnly bool
+		want         int
+	}{
+		{name: "every page", want: 5},
+		{name: "disabled only", disabledOnly: true, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			disabledOnly = tt.disabledOnly
+			out := captureStdout(t, func() {
+				if err := listUserRunFunc(listUserCmd, nil); err != nil {
+					t.Fatalf("listUserRunFunc() error = %v", err)
+				}
+			})
+			var users []admin.User
+			if err := json.Unmarshal([]byte(out), &users); err != nil {
+				t.Fatalf("output is not JSON: %v\n%s", err, out)
+			}
+			if len(users) != tt.want {
+				t.Errorf("listed %d users, want %d", len(users), tt.want)
+			}
+		})
+	}
+}
+
+func TestListUserRunFuncNDJSON(t *testing.T) {
+	fake := withFakeDirectory(t)
+	withRunnerGlobals(t, OutputFormatNDJSON)
+
+	fake.pageSize = 2
+	for i := 0; i < 5; i++ {
+		fake.addUser(&admin.User{PrimaryEmail: fmt.Sprintf("user%d@example.com", i), Name: &admin.UserName{FullName: "User"}})
+	}
+
+	out := captureStdout(t, func() {
+		if err := listUserRunFunc(listUserCmd, nil); err != nil {
+			t.Fatalf("listUserRunFunc() error = %v", err)
+		}
+	})
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("wrote %d lines, want one per user:\n%s", len(lines), out)
+	}
+	for _, line := range lines {
+		var u admin.User
+		if err := json.Unmarshal([]byte(line), &u); err != nil || u.PrimaryEmail == "" {
+			t.Errorf("line %q is not a user: %v", line, err)
+		}
+	}
+}
+
+func TestListGroupMembersRunFunc(t *testing.T) {
+	fake := withFakeDirectory(t)
+	withRunnerGlobals(t, OutputFormatJSON)
+	originalGetMembers := getMembers
+	defer func() { getMembers = originalGetMembers }()
+	getMembers = true
+
+	fake.addUser(&admin.User{PrimaryEmail: "active@example.com"})
+	fake.addUser(&admin.User{PrimaryEmail: "gone@example.com", OrgUnitPath: "/Former employees"})
+	fake.addGroup(&admin.Group{Email: "ops@example.com", Name: "Ops"},
+		&admin.Member{Email: "active@example.com", Type: "USER", Role: "OWNER"},
+		&admin.Member{Email: "gone@example.com", Type: "USER", Role: "MEMBER"},
+		&admin.Member{Email: "eng@example.com", Type: "GROUP", Role: "MEMBER"},
+	)
+
+	out := captureStdout(t, func() {
+		if err := listGroupRunFunc(listGroupCmd, []string{"ops@example.com"}); err != nil {
+			t.Fatalf("listGroupRunFunc() error = %v", err)
+		}
+	})
+	var members []gac.MemberStatus
+	if err := json.Unmarshal([]byte(out), &members); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out)
+	}
+	want := map[string]string{"active@example.com": "active", "gone@example.com": "former", "eng@example.com": "group"}
+	if len(members) != len(want) {
+		t.Fatalf("members = %+v, want %d", members, len(want))
+	}
+	for _, m := range members {
+		if m.Status != want[m.Email] {
+			t.Errorf("status of %s = %q, want %q", m.Email, m.Status, want[m.Email])
+		}
+	}
+}
+
+func TestAliasRunFuncs(t *testing.T) {
+	fake := withFakeDirectory(t)
+	withRunnerGlobals(t, OutputFormatPlain)
+	originalForce := aliasRemoveForce
+	defer func() { aliasRemoveForce = originalForce }()
+	aliasRemoveForce = true
+
+	fake.addUser(&admin.User{PrimaryEmail: "jdoe@example.com"})
+
+	captureStdout(t, func() {
+		if err := aliasAddRunFunc(aliasAddCmd, []string{"jdoe@example.com", "jane@example.com"}); err != nil {
+			t.Fatalf("aliasAddRunFunc() error = %v", err)
+		}
+		// An alias in use cannot be added twice
+		if err := aliasAddRunFunc(aliasAddCmd, []string{"jdoe@example.com", "jane@example.com"}); err == nil {
+			t.Error("adding a duplicate alias succeeded, want error")
+		}
+	})
+
+	out := captureStdout(t, func() {
+		if err := aliasListRunFunc(aliasListCmd, []string{"jdoe@example.com"}); err != nil {
+			t.Fatalf("aliasListRunFunc() error = %v", err)
+		}
+	})
+	if !strings.Contains(out, "jane@example.com") || !strings.Contains(out, "Total: 1") {
+		t.Errorf("alias list output missing the alias:\n%s", out)
+	}
+
+	captureStdout(t, func() {
+		if err := aliasRemoveRunFunc(aliasRemoveCmd, []string{"jdoe@example.com", "jane@example.com"}); err != nil {
+			t.Fatalf("aliasRemoveRunFunc() error = %v", err)
+		}
+	})
+	if aliases, _ := fake.ListAliases(context.Background(), "jdoe@example.com"); len(aliases.Aliases) != 0 {
+		t.Errorf("aliases after remove = %v, want none", aliases.Aliases)
+	}
+}
+
+func TestOURunFuncs(t *testing.T) {
+	fake := withFakeDirectory(t)
+	withRunnerGlobals(t, OutputFormatJSON)
+	originalDescription, originalParent, originalListType := ouDescription, ouParent, ouListType
+	originalUpdateDescription, originalDeleteForce := ouUpdateDescription, ouDeleteForce
+	defer func() {
+		ouDescription, ouParent, ouListType = originalDescription, originalParent, originalListType
+		ouUpdateDescription, ouDeleteForce = originalUpdateDescription, originalDeleteForce
+	}()
+	ouParent, ouListType, ouDeleteForce = "", "children", true
+
+	captureStdout(t, func() {
+		for _, path := range []string{"/Engineering", "/Engineering/Backend"} {
+			if err := ouCreateRunFunc(ouCreateCmd, []string{path}); err != nil {
+				t.Fatalf("ouCreateRunFunc(%s) error = %v", path, err)
+			}
+		}
+	})
+
+	out := captureStdout(t, func() {
+		if err := ouListRunFunc(ouListCmd, []string{"/Engineering"}); err != nil {
+			t.Fatalf("ouListRunFunc() error = %v", err)
+		}
+	})
+	if !strings.Contains(out, `"orgUnitPath": "/Engineering/Backend"`) {
+		t.Errorf("ou list output missing the child OU:\n%s", out)
+	}
+
+	ouUpdateDescription = "Backend team"
+	captureStdout(t, func() {
+		if err := ouUpdateRunFunc(ouUpdateCmd, []string{"/Engineering/Backend"}); err != nil {
+			t.Fatalf("ouUpdateRunFunc() error = %v", err)
+		}
+		// An OU with children cannot be deleted
+		if err := ouDeleteRunFunc(ouDeleteCmd, []string{"/Engineering"}); err == nil {
+			t.Error("deleting an OU with children succeeded, want error")
+		}
+	})
+	if ous, _ := fake.ListOrgUnits(context.Background(), "/Engineering", "children"); len(ous.OrganizationUnits) != 1 || ous.OrganizationUnits[0].Description != "Backend team" {
+		t.Errorf("OUs after update = %+v, want the new description", ous.OrganizationUnits)
+	}
+
+	captureStdout(t, func() {
+		if err := ouDeleteRunFunc(ouDeleteCmd, []string{"/Engineering/Backend"}); err != nil {
+			t.Fatalf("ouDeleteRunFunc() error = %v", err)
+		}
+	})
+	if ous, _ := fake.ListOrgUnits(context.Background(), "", "all"); len(ous.OrganizationUnits) != 1 {
+		t.Errorf("OUs after delete = %d, want 1", len(ous.OrganizationUnits))
+	}
+}
+
+func TestCalResourceRunFuncs(t *testing.T) {
+	fake := withFakeDirectory(t)
+	withRunnerGlobals(t, OutputFormatJSON)
+	originalName, originalType, originalCapacity := calResourceName, calResourceType, calResourceCapacity
+	originalListType, originalForce := calResourceListType, calResourceDeleteForce
+	defer func() {
+		calResourceName, calResourceType, calResourceCapacity = originalName, originalType, originalCapacity
+		calResourceListType, calResourceDeleteForce = originalListType, originalForce
+	}()
+
+	captureStdout(t, func() {
+		calResourceName, calResourceType, calResourceCapacity = "Conference A", "room", 10
+		if err := calResourceCreateRunFunc(calResourceCreateCmd, []string{"conf-a"}); err != nil {
+			t.Fatalf("calResourceCreateRunFunc() error = %v", err)
+		}
+		calResourceName, calResourceType, calResourceCapacity = "Projector", "equipment", 0
+		if err := calResourceCreateRunFunc(calResourceCreateCmd, []string{"proj-1"}); err != nil {
+			t.Fatalf("calResourceCreateRunFunc() error = %v", err)
+		}
+	})
+
+	// Resources on later pages are listed too
+	fake.pageSize = 1
+	calResourceListType = "all"
+	out := captureStdout(t, func() {
+		if err := calResourceListRunFunc(calResourceListCmd, nil); err != nil {
+			t.Fatalf("calResourceListRunFunc() error = %v", err)
+		}
+	})
+	if !strings.Contains(out, "conf-a") || !strings.Contains(out, "proj-1") {
+		t.Errorf("list output:\n%s\nwant both resources", out)
+	}
+
+	calResourceListType = "room"
+	out = captureStdout(t, func() {
+		if err := calResourceListRunFunc(calResourceListCmd, nil); err != nil {
+			t.Fatalf("calResourceListRunFunc() error = %v", err)
+		}
+	})
+	if !strings.Contains(out, "conf-a") || strings.Contains(out, "proj-1") {
+		t.Errorf("room list output:\n%s\nwant only conf-a", out)
+	}
+
+	// Only flags that were set are updated
+	cmd := &cobra.Command{}
+	cmd.Flags().Int64Var(&updateCalResourceCapacity, "capacity", -1, "")
+	if err := cmd.Flags().Set("capacity", "12"); err != nil {
+		t.Fatal(err)
+	}
+	captureStdout(t, func() {
+		if err := calResourceUpdateRunFunc(cmd, []string{"conf-a"}); err != nil {
+			t.Fatalf("calResourceUpdateRunFunc() error = %v", err)
+		}
+	})
+	if r, _ := fake.GetCalendarResource(context.Background(), "conf-a"); r.Capacity != 12 || r.ResourceName != "Conference A" {
+		t.Errorf("resource after update = %+v, want capacity 12 and the name kept", r)
+	}
+
+	calResourceDeleteForce = true
+	captureStdout(t, func() {
+		if err := calResourceDeleteRunFunc(calResourceDeleteCmd, []string{"proj-1"}); err != nil {
+			t.Fatalf("calResourceDeleteRunFunc() error = %v", err)
+		}
+	})
+	if _, err := fake.GetCalendarResource(context.Background(), "proj-1"); err == nil {
+		t.Error("resource still exists after delete")
+	}
+}
+
+func TestGroupSettingsRunFuncs(t *testing.T) {
+	fake := withFakeDirectory(t)
+	withRunnerGlobals(t, OutputFormatJSON)
+	fake.addGroup(&admin.Group{Email: "ops@example.com", Name: "Ops"})
+
+	cmd := &cobra.Command{}
+	cmd.Flags().StringVar(&whoCanJoin, "who-can-join", "", "")
+	if err := groupSettingsUpdateRunFunc(cmd, []string{"ops@example.com"}); err == nil {
+		t.Error("update with no settings succeeded, want error")
+	}
+
+	if err := cmd.Flags().Set("who-can-join", "INVITED_CAN_JOIN"); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { whoCanJoin = "" }()
+	captureStdout(t, func() {
+		if err := groupSettingsUpdateRunFunc(cmd, []string{"ops@example.com"}); err != nil {
+			t.Fatalf("groupSettingsUpdateRunFunc() error = %v", err)
+		}
+	})
+
+	out := captureStdout(t, func() {
+		if err := groupSettingsListRunFunc(groupSettingsListCmd, []string{"ops@example.com"}); err != nil {
+			t.Fatalf("groupSettingsListRunFunc() error = %v", err)
+		}
+	})
+	if !strings.Contains(out, `"whoCanJoin": "INVITED_CAN_JOIN"`) {
+		t.Errorf("group settings output missing the update:\n%s", out)
+	}
+}
@@ -13,6 +13,7 @@ func TestAllCommandsRegistered(t *testing.T) {
 		"calendar": false,
 		"transfer": false,
 		"init":     false,
+		"auth":     false,
 	}
 
 	for _, cmd := range rootCmd.Commands() {
@@ -47,6 +48,23 @@ func TestUserSubcommands(t *testing.T) {
 	}
 }
 
+func TestAuthSubcommands(t *testing.T) {
+	expectedSubcommands := []string{"status", "whoami", "revoke", "refresh"}
+
+	for _, expected := range expectedSubcommands {
+		found := false
+		for _, cmd := range authCmd.Commands() {
+			if cmd.Use == expected {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("auth subcommand %q not found", expected)
+		}
+	}
+}
+
 func TestCalendarSubcommands(t *testing.T) {
 	expectedSubcommands := []string{"create", "list", "update"}
 
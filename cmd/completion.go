@@ -87,11 +87,11 @@ After installation, restart your shell or run:
   source ~/.bashrc
 `,
 	Args: cobra.NoArgs,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		if err := rootCmd.GenBashCompletionV2(os.Stdout, true); err != nil {
-			fmt.Fprintf(os.Stderr, "Error generating bash completion: %v\n", err)
-			os.Exit(1)
+			return fmt.Errorf("failed to generate bash completion: %w", err)
 		}
+		return nil
 	},
 }
 
@@ -112,11 +112,11 @@ After installation, restart your shell or run:
   autoload -U compinit && compinit
 `,
 	Args: cobra.NoArgs,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		if err := rootCmd.GenZshCompletion(os.Stdout); err != nil {
-			fmt.Fprintf(os.Stderr, "Error generating zsh completion: %v\n", err)
-			os.Exit(1)
+			return fmt.Errorf("failed to generate zsh completion: %w", err)
 		}
+		return nil
 	},
 }
 
@@ -133,11 +133,11 @@ After installation, fish will automatically load the completions.
 No need to restart the shell.
 `,
 	Args: cobra.NoArgs,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		if err := rootCmd.GenFishCompletion(os.Stdout, true); err != nil {
-			fmt.Fprintf(os.Stderr, "Error generating fish completion: %v\n", err)
-			os.Exit(1)
+			return fmt.Errorf("failed to generate fish completion: %w", err)
 		}
+		return nil
 	},
 }
 
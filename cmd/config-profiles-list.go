@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// configProfilesListCmd represents the config profiles list command
+var configProfilesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List configured profiles",
+	Long: `List the profiles defined in the config file.
+
+The active profile is marked in the Active column.
+
+Examples:
+  gac config profiles list
+  gac config profiles list --format json`,
+	Args: cobra.NoArgs,
+	RunE: configProfilesListRunFunc,
+}
+
+func init() {
+	configProfilesCmd.AddCommand(configProfilesListCmd)
+}
+
+type profileSummary struct {
+	Name       string `json:"name"`
+	Domain     string `json:"domain"`
+	CustomerID string `json:"customerId"`
+	Active     bool   `json:"active"`
+}
+
+func configProfilesListRunFunc(cmd *cobra.Command, args []string) error {
+	names := listProfiles()
+	if len(names) == 0 {
+		QuietPrintln("No profiles configured (add a \"profiles:\" section to the config file)")
+		return nil
+	}
+
+	active := activeProfile()
+	summaries := make([]profileSummary, 0, len(names))
+	for _, name := range names {
+		profile, err := getProfile(name)
+		if err != nil {
+			return err
+		}
+		customerID := profile.GetString("customer-id")
+		if customerID == "" {
+			customerID = defaultCustomerID
+		}
+		summaries = append(summaries, profileSummary{
+			Name:       name,
+			Domain:     profile.GetString("domain"),
+			CustomerID: customerID,
+			Active:     name == active,
+		})
+	}
+
+	headers := []string{"Name", "Domain", "CustomerID", "Active"}
+	if err := FormatOutput(summaries, headers); err != nil {
+		return fmt.Errorf("failed to format output: %w", err)
+	}
+	return nil
+}
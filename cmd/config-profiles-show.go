@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// configProfilesShowCmd represents the config profiles show command
+var configProfilesShowCmd = &cobra.Command{
+	Use:   "show [profile]",
+	Short: "Show the settings of a profile",
+	Long: `Show the settings of a profile as they would be used, including the
+token cache and cache directory derived for it. Without an argument the
+active profile is shown.
+
+Examples:
+  gac config profiles show
+  gac config profiles show sandbox --format json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: configProfilesShowRunFunc,
+}
+
+func init() {
+	configProfilesCmd.AddCommand(configProfilesShowCmd)
+}
+
+type profileDetails struct {
+	Name           string `json:"name"`
+	Active         bool   `json:"active"`
+	Domain         string `json:"domain,omitempty"`
+	CustomerID     string `json:"customerId"`
+	ClientSecret   string `json:"clientSecret,omitempty"`
+	Impersonate    string `json:"impersonate,omitempty"`
+	CacheFile      string `json:"cacheFile"`
+	CacheDirectory string `json:"cacheDirectory"`
+}
+
+func configProfilesShowRunFunc(cmd *cobra.Command, args []string) error {
+	name := activeProfile()
+	if len(args) == 1 {
+		name = strings.ToLower(args[0])
+	}
+	if name == "" {
+		return invalidInputf("no active profile (pass a profile name, --profile, or set default-profile)")
+	}
+
+	details, err := describeProfile(name)
+	if err != nil {
+		return err
+	}
+
+	if err := FormatOutput(details, nil); err != nil {
+		return fmt.Errorf("failed to format output: %w", err)
+	}
+	return nil
+}
+
+// describeProfile resolves the settings a profile would use. Values not set
+// in the profile fall back to the top-level configuration.
+func describeProfile(name string) (profileDetails, error) {
+	profile, err := getProfile(name)
+	if err != nil {
+		return profileDetails{}, err
+	}
+
+	details := profileDetails{
+		Name:         name,
+		Active:       name == activeProfile(),
+		Domain:       profile.GetString("domain"),
+		CustomerID:   profile.GetString("customer-id"),
+		ClientSecret: profile.GetString("client-secret"),
+		Impersonate:  profile.GetString("impersonate"),
+		CacheFile:    profile.GetString("cache-file"),
+	}
+	if details.CustomerID == "" {
+		details.CustomerID = defaultCustomerID
+	}
+	if details.CacheFile == "" {
+		details.CacheFile = filepath.Join("~", ".credentials", "gac-"+name+".json")
+	}
+	details.CacheDirectory = profile.GetString("cache-directory")
+	if details.CacheDirectory == "" {
+		details.CacheDirectory = filepath.Join("~", ".cache", "gac", name)
+	}
+
+	// The active profile has already been applied, so report what is in effect
+	if details.Active {
+		details.Domain = getDomain()
+		details.CustomerID = getCustomerID()
+		if cacheFile, err := tokenCacheFile(); err == nil {
+			details.CacheFile = cacheFile
+		}
+		if cacheDir, err := getCacheDir(); err == nil {
+			details.CacheDirectory = cacheDir
+		}
+	}
+	return details, nil
+}
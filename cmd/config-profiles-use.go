@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// configProfilesUseCmd represents the config profiles use command
+var configProfilesUseCmd = &cobra.Command{
+	Use:   "use <profile>",
+	Short: "Set the default profile",
+	Long: `Set the default profile by writing "default-profile" to the config file.
+
+Comments and formatting elsewhere in the file are preserved. --profile and
+GAC_PROFILE still override the default for a single invocation.
+
+Examples:
+  gac config profiles use production`,
+	Args: cobra.ExactArgs(1),
+	RunE: configProfilesUseRunFunc,
+}
+
+func init() {
+	configProfilesCmd.AddCommand(configProfilesUseCmd)
+}
+
+func configProfilesUseRunFunc(cmd *cobra.Command, args []string) error {
+	name := strings.ToLower(args[0])
+	if _, err := getProfile(name); err != nil {
+		return err
+	}
+
+	if err := setDefaultProfile(viper.ConfigFileUsed(), name); err != nil {
+		return fmt.Errorf("failed to set default profile: %w", err)
+	}
+
+	QuietPrintf("Default profile set to %q\n", name)
+	return nil
+}
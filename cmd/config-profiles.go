@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// configProfilesCmd represents the config profiles command
+var configProfilesCmd = &cobra.Command{
+	Use:   "profiles",
+	Short: "Manage named configuration profiles",
+	Long: `Manage named configuration profiles.
+
+Profiles let one config file hold settings for several Google Workspace
+tenants. Each profile under the "profiles:" key may set its own domain,
+client-secret, cache-file, impersonate, customer-id and cache-directory.
+Profiles without their own cache-file or cache-directory still get a
+separate token cache and API cache, so tenants never share credentials.
+
+Select a profile with --profile, the GAC_PROFILE environment variable, or
+"default-profile" in the config file (set by "gac config profiles use").
+
+Example config:
+
+  default-profile: production
+  profiles:
+    production:
+      domain: example.com
+      client-secret: ~/.credentials/prod_secret.json
+    sandbox:
+      domain: sandbox.example.com
+      client-secret: ~/.credentials/sandbox_secret.json
+      customer-id: C0123abcd
+
+Examples:
+  # List configured profiles
+  gac config profiles list
+
+  # Make sandbox the default profile
+  gac config profiles use sandbox
+
+  # Show the resolved settings of a profile
+  gac config profiles show production
+
+  # Run a single command against another tenant
+  gac --profile sandbox user list
+`,
+}
+
+func init() {
+	configCmd.AddCommand(configProfilesCmd)
+}
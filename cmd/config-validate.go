@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -21,7 +22,7 @@ var configValidateCmd = &cobra.Command{
 - Client secret file existence and permissions
 - Cache file path and permissions
 - OAuth2 token validity
-- Required API scopes
+- OAuth2 scopes granted to the cached token
 
 This command helps diagnose configuration issues and ensures all required
 settings are properly configured before running gac commands.
@@ -34,20 +35,23 @@ Examples:
   # Validate current configuration
   gac config validate
 
+  # Validate a named profile
+  gac config validate --profile sandbox
+
   # Validate with specific config file
   gac config validate --config ~/.google-admin-test.yaml
 
   # Validate with verbose output
   gac config validate --verbose
 `,
-	Run: configValidateRunFunc,
+	RunE: configValidateRunFunc,
 }
 
 func init() {
 	configCmd.AddCommand(configValidateCmd)
 }
 
-func configValidateRunFunc(cmd *cobra.Command, args []string) {
+func configValidateRunFunc(cmd *cobra.Command, args []string) error {
 	hasErrors := false
 	hasWarnings := false
 
@@ -78,6 +82,9 @@ func configValidateRunFunc(cmd *cobra.Command, args []string) {
 	} else {
 		fmt.Println("  ℹ No config file in use (using flags/env vars)")
 	}
+	if profile := activeProfile(); profile != "" {
+		fmt.Printf("  ✓ Active profile: %s\n", profile)
+	}
 	fmt.Println()
 
 	// 2. Validate domain
@@ -96,6 +103,7 @@ func configValidateRunFunc(cmd *cobra.Command, args []string) {
 			fmt.Printf("  ✓ Domain: %s\n", domain)
 		}
 	}
+	fmt.Printf("  ✓ Customer ID: %s\n", getCustomerID())
 	fmt.Println()
 
 	// 3. Validate client secret file
@@ -142,6 +150,21 @@ func configValidateRunFunc(cmd *cobra.Command, args []string) {
 				hasErrors = true
 			} else {
 				fmt.Println("  ✓ Client secret file path is valid")
+
+				// Report which authentication flow the credential file selects
+				// #nosec G304 - Path is validated by validateCredentialPath() above
+				if b, err := os.ReadFile(clientSecretPath); err == nil && isServiceAccountKey(b) {
+					fmt.Println("  ✓ Credential type: service account (domain-wide delegation)")
+					if subject := getImpersonatedUser(); subject != "" {
+						fmt.Printf("  ✓ Impersonating: %s\n", subject)
+					} else {
+						fmt.Println("  ✗ Service account requires an admin to impersonate")
+						fmt.Println("    Set via: --impersonate flag, GAC_IMPERSONATE env var, or config file")
+						hasErrors = true
+					}
+				} else {
+					fmt.Println("  ✓ Credential type: OAuth2 client secret (installed app)")
+				}
 			}
 		}
 	}
@@ -152,7 +175,12 @@ func configValidateRunFunc(cmd *cobra.Command, args []string) {
 	cacheFilePath := viper.GetString("cache-file")
 	if cacheFilePath == "" {
 		fmt.Println("  ℹ Using default cache file location")
-		cacheFilePath = filepath.Join(os.Getenv("HOME"), ".credentials", "gac.json")
+		if defaultPath, err := tokenCacheFile(); err == nil {
+			cacheFilePath = defaultPath
+		} else {
+			fmt.Printf("  ⚠ Warning: Unable to determine default cache file: %v\n", err)
+			hasWarnings = true
+		}
 	}
 
 	// Expand home directory if needed
@@ -190,6 +218,7 @@ func configValidateRunFunc(cmd *cobra.Command, args []string) {
 		// Try to validate token if it exists
 		if validateToken(cacheFilePath) {
 			fmt.Println("  ✓ Token cache is valid")
+			reportTokenScopes(cacheFilePath)
 		} else {
 			fmt.Println("  ⚠ Warning: Token cache may be invalid or expired (re-authentication may be required)")
 			hasWarnings = true
@@ -202,7 +231,7 @@ func configValidateRunFunc(cmd *cobra.Command, args []string) {
 	if hasErrors {
 		fmt.Println("  ✗ Configuration has errors that must be fixed")
 		fmt.Println()
-		os.Exit(1)
+		return errors.New("configuration is invalid")
 	} else if hasWarnings {
 		fmt.Println("  ⚠ Configuration is valid but has warnings")
 		fmt.Println("  ✓ gac should work, but consider addressing warnings above")
@@ -211,6 +240,7 @@ func configValidateRunFunc(cmd *cobra.Command, args []string) {
 		fmt.Println("  ✓ Configuration is valid with no errors or warnings")
 		fmt.Println()
 	}
+	return nil
 }
 
 // validateToken attempts to validate the OAuth2 token from the cache file
@@ -241,3 +271,23 @@ func validateToken(cacheFilePath string) bool {
 	// If we got here, the token file appears valid (actual validation happens during API calls)
 	return true
 }
+
+// reportTokenScopes prints the OAuth2 scopes recorded in the token cache
+func reportTokenScopes(cacheFilePath string) {
+	_, scopes, err := readTokenFile(cacheFilePath)
+	if err != nil {
+		fmt.Printf("  ⚠ Warning: Unable to read token scopes: %v\n", err)
+		return
+	}
+	if len(scopes) == 0 {
+		fmt.Println("  ℹ Granted scopes not recorded (token predates per-command scopes; all scopes assumed)")
+		return
+	}
+	fmt.Println("  ✓ Granted scopes:")
+	for _, scope := range scopes {
+		fmt.Printf("      %s\n", scope)
+	}
+	if missing := missingScopes(scopes, allScopes); len(missing) > 0 {
+		fmt.Printf("  ℹ %d scope(s) not yet granted; gac will ask for consent when a command needs them\n", len(missing))
+	}
+}
@@ -19,6 +19,9 @@ Examples:
 
   # Show current configuration
   gac config show
+
+  # List named profiles
+  gac config profiles list
 `,
 }
 
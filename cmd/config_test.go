@@ -28,6 +28,24 @@ func TestConfigCommandRegistration(t *testing.T) {
 				t.Error("config command missing validate subcommand")
 			}
 
+			// Check for profiles subcommand and its children
+			profilesFound := false
+			for _, subcmd := range cmd.Commands() {
+				if subcmd.Name() == "profiles" {
+					profilesFound = true
+					for _, name := range []string{"list", "use", "show"} {
+						if c, _, err := subcmd.Find([]string{name}); err != nil || c.Name() != name {
+							t.Errorf("config profiles command missing %s subcommand", name)
+						}
+					}
+					break
+				}
+			}
+
+			if !profilesFound {
+				t.Error("config command missing profiles subcommand")
+			}
+
 			break
 		}
 	}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Exit codes for interrupted commands, following shell conventions
+const (
+	// exitCodeTimeout matches timeout(1) when --timeout expires
+	exitCodeTimeout = 124
+	// exitCodeCancelled is 128+SIGINT, as for a command killed by Ctrl-C
+	exitCodeCancelled = 130
+)
+
+var (
+	timeoutFlag time.Duration
+
+	// commandCtx is the running command's context, cancelled on SIGINT or
+	// SIGTERM and bounded by --timeout
+	commandCtx = context.Background()
+
+	// cancelTimeout releases the --timeout timer
+	cancelTimeout context.CancelFunc = func() {}
+)
+
+// apiContext returns the context every API call should use
+func apiContext() context.Context {
+	return commandCtx
+}
+
+// newSignalContext returns a context cancelled by the first SIGINT or
+// SIGTERM. A second signal terminates the process immediately.
+func newSignalContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		// Restore default signal handling so a second Ctrl-C kills gac
+		stop()
+		if errors.Is(context.Cause(ctx), context.Canceled) {
+			fmt.Fprintln(os.Stderr, "\nInterrupted, stopping... (press Ctrl-C again to force quit)")
+		}
+	}()
+	return ctx, stop
+}
+
+// setupCommandContext applies --timeout to the command's context and makes
+// it available to API calls through apiContext
+func setupCommandContext(cmd *cobra.Command) {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if timeoutFlag > 0 {
+		ctx, cancelTimeout = context.WithTimeout(ctx, timeoutFlag)
+		cmd.SetContext(ctx)
+	}
+	commandCtx = ctx
+}
+
+// cancellation reports whether the command was interrupted or timed out,
+// with the matching exit code and message
+func cancellation() (int, string, bool) {
+	switch err := commandCtx.Err(); {
+	case errors.Is(err, context.DeadlineExceeded):
+		return exitCodeTimeout, fmt.Sprintf("Timed out after %s", timeoutFlag), true
+	case errors.Is(err, context.Canceled):
+		return exitCodeCancelled, "Cancelled", true
+	}
+	return 0, "", false
+}
+
+// warnPartialOutput tells the user that output was cut short
+func warnPartialOutput(count int, what string) {
+	if _, msg, ok := cancellation(); ok {
+		LogWarn("Output is incomplete", map[string]interface{}{
+			"reason": msg,
+			"count":  count,
+			"items":  what,
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// withCommandContext installs ctx as the command context for one test
+func withCommandContext(t *testing.T, ctx context.Context) {
+	t.Helper()
+	original := commandCtx
+	originalTimeout := timeoutFlag
+	t.Cleanup(func() {
+		commandCtx = original
+		timeoutFlag = originalTimeout
+	})
+	commandCtx = ctx
+}
+
+func TestSetupCommandContext(t *testing.T) {
+	withCommandContext(t, context.Background())
+
+	timeoutFlag = time.Minute
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+	setupCommandContext(cmd)
+	defer cancelTimeout()
+
+	deadline, ok := apiContext().Deadline()
+	if !ok {
+		t.Fatal("apiContext() has no deadline with --timeout set")
+	}
+	if remaining := time.Until(deadline); remaining <= 0 || remaining > time.Minute {
+		t.Errorf("deadline in %v, want within 1m", remaining)
+	}
+	if cmd.Context() != apiContext() {
+		t.Error("command context and apiContext() differ")
+	}
+
+	timeoutFlag = 0
+	setupCommandContext(&cobra.Command{})
+	if _, ok := apiContext().Deadline(); ok {
+		t.Error("apiContext() should have no deadline without --timeout")
+	}
+}
+
+func TestCancellation(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancelExpired := context.WithTimeout(context.Background(), -time.Second)
+	defer cancelExpired()
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		wantCode int
+		wantOK   bool
+	}{
+		{name: "running", ctx: context.Background(), wantOK: false},
+		{name: "interrupted", ctx: cancelled, wantCode: exitCodeCancelled, wantOK: true},
+		{name: "timed out", ctx: expired, wantCode: exitCodeTimeout, wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withCommandContext(t, tt.ctx)
+			code, _, ok := cancellation()
+			if ok != tt.wantOK || code != tt.wantCode {
+				t.Errorf("cancellation() = %d, %v, want %d, %v", code, ok, tt.wantCode, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestAPICallsUseCommandContext(t *testing.T) {
+	server := newMockServer(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	defer server.Close()
+	client := createMockAdminClient(t, server.Server)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	withCommandContext(t, ctx)
+
+	start := time.Now()
+	_, err := client.Users.Get("user@example.com").Context(apiContext()).Do()
+	if err == nil {
+		t.Fatal("expected error from hung API call")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("hung call took %v to abort", elapsed)
+	}
+	if code, _, ok := cancellation(); !ok || code != exitCodeTimeout {
+		t.Errorf("cancellation() = %d, %v, want timeout", code, ok)
+	}
+}
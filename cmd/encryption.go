@@ -0,0 +1,315 @@
+package cmd
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+// Encryption envelope settings
+const (
+	encryptionVersion   = 1
+	encryptionAlgorithm = "AES-256-GCM"
+
+	// kdfNone marks files sealed with a raw 256-bit key, kdfPBKDF2 files
+	// sealed with a key derived from a passphrase and the file's salt
+	kdfNone   = "none"
+	kdfPBKDF2 = "pbkdf2-sha256"
+
+	pbkdf2Iterations = 600000
+	saltSize         = 16
+	keySize          = 32
+)
+
+// Sources an encryption key can come from, as reported by `gac cache status`
+const (
+	keySourceEnv        = "env"
+	keySourceFile       = "key-file"
+	keySourcePassphrase = "passphrase"
+)
+
+// Purposes bound to each sealed file as additional authenticated data, so a
+// cache entry cannot be swapped in for the token or vice versa
+const (
+	sealPurposeToken = "token"
+	sealPurposeCache = "cache"
+)
+
+// sealedFile is the on-disk format of an encrypted file. Files without the
+// gac_encrypted marker are plaintext and are still read, so enabling
+// encryption does not invalidate existing caches.
+type sealedFile struct {
+	Encrypted int    `json:"gac_encrypted"`
+	Algorithm string `json:"alg"`
+	KDF       string `json:"kdf"`
+	Salt      string `json:"salt,omitempty"`
+	Nonce     string `json:"nonce"`
+	Data      string `json:"data"`
+}
+
+// encryptionSecret is the key material in use: a raw key, or a passphrase
+// from which per-salt keys are derived
+type encryptionSecret struct {
+	source     string
+	key        []byte
+	passphrase string
+}
+
+var (
+	secretOnce sync.Once
+	secret     *encryptionSecret
+	secretErr  error
+
+	// writeSalt is shared by every file this process seals so the
+	// passphrase is stretched once per run
+	writeSalt []byte
+
+	derivedKeys   = map[string][]byte{}
+	derivedKeysMu sync.Mutex
+
+	// readPassphrase prompts for the passphrase; replaced in tests
+	readPassphrase = promptPassphrase
+)
+
+// encryptionEnabled reports whether token and cache files are written
+// encrypted. Setting a key enables it without encryption.enabled.
+func encryptionEnabled() bool {
+	return viper.GetBool("encryption.enabled") || encryptionKeySource() != keySourcePassphrase
+}
+
+// encryptionKeySource returns where the key will come from: the
+// GAC_ENCRYPTION_KEY env var, a key file, or a passphrase prompt
+func encryptionKeySource() string {
+	switch {
+	case viper.GetString("encryption.key") != "":
+		return keySourceEnv
+	case viper.GetString("encryption.key-file") != "":
+		return keySourceFile
+	}
+	return keySourcePassphrase
+}
+
+// loadEncryptionSecret resolves the key material once per process
+func loadEncryptionSecret() (*encryptionSecret, error) {
+	secretOnce.Do(func() {
+		secret, secretErr = resolveEncryptionSecret()
+	})
+	return secret, secretErr
+}
+
+func resolveEncryptionSecret() (*encryptionSecret, error) {
+	switch source := encryptionKeySource(); source {
+	case keySourceEnv:
+		value := viper.GetString("encryption.key")
+		// A base64 256-bit key is used as is; anything else is a passphrase
+		if key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value)); err == nil && len(key) == keySize {
+			return &encryptionSecret{source: source, key: key}, nil
+		}
+		return &encryptionSecret{source: source, passphrase: value}, nil
+
+	case keySourceFile:
+		key, err := readKeyFile(expandHome(viper.GetString("encryption.key-file")))
+		if err != nil {
+			return nil, err
+		}
+		return &encryptionSecret{source: source, key: key}, nil
+
+	default:
+		passphrase, err := readPassphrase("Encryption passphrase: ")
+		if err != nil {
+			return nil, fmt.Errorf("encryption key unavailable (set GAC_ENCRYPTION_KEY or encryption.key-file): %w", err)
+		}
+		if passphrase == "" {
+			return nil, errors.New("encryption passphrase cannot be empty")
+		}
+		return &encryptionSecret{source: source, passphrase: passphrase}, nil
+	}
+}
+
+// readKeyFile reads a base64-encoded 256-bit key, e.g. one generated with
+// `openssl rand -base64 32`
+func readKeyFile(path string) ([]byte, error) {
+	if err := validateCredentialPath(path); err != nil {
+		return nil, fmt.Errorf("invalid encryption key file path: %w", err)
+	}
+	checkFilePermissions(path)
+
+	// #nosec G304 - Path is validated by validateCredentialPath() above
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read encryption key file: %w", err)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(b)))
+	if err != nil || len(key) != keySize {
+		return nil, fmt.Errorf("encryption key file %s must contain a base64-encoded %d-byte key", path, keySize)
+	}
+	return key, nil
+}
+
+// expandHome expands a leading ~/ to the user's home directory
+func expandHome(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		if usr, err := user.Current(); err == nil {
+			return filepath.Join(usr.HomeDir, path[2:])
+		}
+	}
+	return path
+}
+
+// keyFor returns the AES key for a file with the given KDF and salt
+func (s *encryptionSecret) keyFor(kdf string, salt []byte) ([]byte, error) {
+	switch kdf {
+	case kdfNone:
+		if s.key == nil {
+			return nil, errors.New("file was encrypted with a key, but a passphrase is configured")
+		}
+		return s.key, nil
+	case kdfPBKDF2:
+		if s.passphrase == "" {
+			return nil, errors.New("file was encrypted with a passphrase, but a key is configured")
+		}
+		derivedKeysMu.Lock()
+		defer derivedKeysMu.Unlock()
+		if key, ok := derivedKeys[string(salt)]; ok {
+			return key, nil
+		}
+		key, err := pbkdf2.Key(sha256.New, s.passphrase, salt, pbkdf2Iterations, keySize)
+		if err != nil {
+			return nil, err
+		}
+		derivedKeys[string(salt)] = key
+		return key, nil
+	}
+	return nil, fmt.Errorf("unsupported key derivation %q", kdf)
+}
+
+// sealIfEnabled encrypts plaintext when encryption is enabled and returns it
+// unchanged otherwise
+func sealIfEnabled(plaintext []byte, purpose string) ([]byte, error) {
+	if !encryptionEnabled() {
+		return plaintext, nil
+	}
+	s, err := loadEncryptionSecret()
+	if err != nil {
+		return nil, err
+	}
+	return seal(s, plaintext, purpose)
+}
+
+// seal encrypts plaintext into a sealedFile
+func seal(s *encryptionSecret, plaintext []byte, purpose string) ([]byte, error) {
+	f := sealedFile{Encrypted: encryptionVersion, Algorithm: encryptionAlgorithm, KDF: kdfNone}
+	var salt []byte
+	if s.key == nil {
+		derivedKeysMu.Lock()
+		if writeSalt == nil {
+			writeSalt = make([]byte, saltSize)
+			if _, err := rand.Read(writeSalt); err != nil {
+				derivedKeysMu.Unlock()
+				return nil, err
+			}
+		}
+		salt = writeSalt
+		derivedKeysMu.Unlock()
+		f.KDF = kdfPBKDF2
+		f.Salt = base64.StdEncoding.EncodeToString(salt)
+	}
+
+	key, err := s.keyFor(f.KDF, salt)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	f.Nonce = base64.StdEncoding.EncodeToString(nonce)
+	f.Data = base64.StdEncoding.EncodeToString(gcm.Seal(nil, nonce, plaintext, []byte("gac:"+purpose)))
+	return json.Marshal(f)
+}
+
+// isSealed reports whether data is an encrypted file
+func isSealed(data []byte) bool {
+	var f struct {
+		Encrypted int `json:"gac_encrypted"`
+	}
+	return json.Unmarshal(data, &f) == nil && f.Encrypted > 0
+}
+
+// openSealed decrypts data if it is an encrypted file and returns plaintext
+// files unchanged. Decrypting needs the key even if encryption has since
+// been disabled.
+func openSealed(data []byte, purpose string) ([]byte, error) {
+	if !isSealed(data) {
+		return data, nil
+	}
+
+	var f sealedFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("invalid encrypted file: %w", err)
+	}
+	if f.Encrypted != encryptionVersion || f.Algorithm != encryptionAlgorithm {
+		return nil, fmt.Errorf("unsupported encrypted file format (version %d, %s)", f.Encrypted, f.Algorithm)
+	}
+
+	s, err := loadEncryptionSecret()
+	if err != nil {
+		return nil, err
+	}
+	salt, err := base64.StdEncoding.DecodeString(f.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encrypted file salt: %w", err)
+	}
+	nonce, err := base64.StdEncoding.DecodeString(f.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encrypted file nonce: %w", err)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(f.Data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encrypted file data: %w", err)
+	}
+
+	key, err := s.keyFor(f.KDF, salt)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return nil, errors.New("invalid encrypted file nonce")
+	}
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, []byte("gac:"+purpose))
+	if err != nil {
+		return nil, errors.New("failed to decrypt (wrong key or passphrase, or the file was modified)")
+	}
+	return plaintext, nil
+}
+
+// newGCM returns an AES-GCM AEAD for a 256-bit key
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"golang.org/x/oauth2"
+)
+
+// withEncryption configures the encryption settings for one test and resets
+// the key material resolved by earlier tests
+func withEncryption(t *testing.T, enabled bool, key, keyFile string) {
+	t.Helper()
+	originalEnabled := viper.Get("encryption.enabled")
+	originalKey := viper.Get("encryption.key")
+	originalKeyFile := viper.Get("encryption.key-file")
+	originalPrompt := readPassphrase
+	reset := func() {
+		secretOnce = sync.Once{}
+		secret, secretErr, writeSalt = nil, nil, nil
+	}
+	t.Cleanup(func() {
+		viper.Set("encryption.enabled", originalEnabled)
+		viper.Set("encryption.key", originalKey)
+		viper.Set("encryption.key-file", originalKeyFile)
+		readPassphrase = originalPrompt
+		reset()
+	})
+
+	reset()
+	viper.Set("encryption.enabled", enabled)
+	viper.Set("encryption.key", key)
+	viper.Set("encryption.key-file", keyFile)
+}
+
+var testEncryptionKey = base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+
+func TestSealAndOpen(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		kdf  string
+	}{
+		{name: "raw key", key: testEncryptionKey, kdf: kdfNone},
+		{name: "passphrase", key: "correct horse battery staple", kdf: kdfPBKDF2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withEncryption(t, false, tt.key, "")
+			if !encryptionEnabled() {
+				t.Fatal("encryptionEnabled() = false, want true when a key is set")
+			}
+
+			plaintext := []byte(`{"refresh_token":"secret"}`)
+			sealed, err := sealIfEnabled(plaintext, sealPurposeToken)
+			if err != nil {
+				t.Fatalf("sealIfEnabled() error = %v", err)
+			}
+			if !isSealed(sealed) || strings.Contains(string(sealed), "secret") {
+				t.Fatalf("sealIfEnabled() = %s, want an encrypted envelope", sealed)
+			}
+			if !strings.Contains(string(sealed), tt.kdf) {
+				t.Errorf("sealed file %s does not use kdf %s", sealed, tt.kdf)
+			}
+
+			got, err := openSealed(sealed, sealPurposeToken)
+			if err != nil {
+				t.Fatalf("openSealed() error = %v", err)
+			}
+			if string(got) != string(plaintext) {
+				t.Errorf("openSealed() = %s, want %s", got, plaintext)
+			}
+
+			// The purpose is authenticated, so files cannot be swapped
+			if _, err := openSealed(sealed, sealPurposeCache); err == nil {
+				t.Error("openSealed() with another purpose succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestOpenSealedErrors(t *testing.T) {
+	withEncryption(t, false, testEncryptionKey, "")
+
+	// Plaintext files from before encryption was enabled are still read
+	plain := []byte(`{"timestamp":"2026-01-01T00:00:00Z","data":[]}`)
+	if got, err := openSealed(plain, sealPurposeCache); err != nil || string(got) != string(plain) {
+		t.Errorf("openSealed(plaintext) = %s, %v, want it unchanged", got, err)
+	}
+
+	sealed, err := sealIfEnabled([]byte("data"), sealPurposeCache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := strings.Replace(string(sealed), `"data":"`, `"data":"AA`, 1)
+	if _, err := openSealed([]byte(tampered), sealPurposeCache); err == nil {
+		t.Error("openSealed(tampered) succeeded, want error")
+	}
+
+	// A passphrase cannot open a file sealed with a raw key
+	withEncryption(t, false, "a passphrase", "")
+	if _, err := openSealed(sealed, sealPurposeCache); err == nil {
+		t.Error("openSealed() with the wrong secret succeeded, want error")
+	}
+}
+
+func TestEncryptionKeyFile(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "gac.key")
+	if err := os.WriteFile(keyFile, []byte(testEncryptionKey+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	withEncryption(t, false, "", keyFile)
+
+	if got := encryptionKeySource(); got != keySourceFile {
+		t.Errorf("encryptionKeySource() = %q, want %q", got, keySourceFile)
+	}
+	s, err := loadEncryptionSecret()
+	if err != nil {
+		t.Fatalf("loadEncryptionSecret() error = %v", err)
+	}
+	if len(s.key) != keySize {
+		t.Errorf("key length = %d, want %d", len(s.key), keySize)
+	}
+
+	if err := os.WriteFile(keyFile, []byte("too short"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readKeyFile(keyFile); err == nil {
+		t.Error("readKeyFile() with an invalid key succeeded, want error")
+	}
+}
+
+func TestEncryptionPassphrasePrompt(t *testing.T) {
+	withEncryption(t, true, "", "")
+	prompts := 0
+	readPassphrase = func(string) (string, error) {
+		prompts++
+		return "prompted passphrase", nil
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := sealIfEnabled([]byte("data"), sealPurposeCache); err != nil {
+			t.Fatalf("sealIfEnabled() error = %v", err)
+		}
+	}
+	if prompts != 1 {
+		t.Errorf("passphrase prompted %d times, want once per process", prompts)
+	}
+}
+
+func TestEncryptedTokenAndCache(t *testing.T) {
+	withEncryption(t, true, testEncryptionKey, "")
+
+	tokenFile := filepath.Join(t.TempDir(), "gac.json")
+	tok := &oauth2.Token{AccessToken: "access", RefreshToken: "refresh-secret", Expiry: time.Now().Add(time.Hour)}
+	if err := saveToken(tokenFile, tok, nil); err != nil {
+		t.Fatalf("saveToken() error = %v", err)
+	}
+	raw, _ := os.ReadFile(tokenFile)
+	if strings.Contains(string(raw), "refresh-secret") {
+		t.Errorf("token file stored in plaintext: %s", raw)
+	}
+	got, _, err := tokenFromFile(tokenFile)
+	if err != nil || got.RefreshToken != "refresh-secret" {
+		t.Errorf("tokenFromFile() = %+v, %v, want decrypted token", got, err)
+	}
+
+	originalDir := viper.Get("cache.directory")
+	originalEnabled := viper.Get("cache.enabled")
+	defer func() {
+		viper.Set("cache.directory", originalDir)
+		viper.Set("cache.enabled", originalEnabled)
+	}()
+	dir := t.TempDir()
+	viper.Set("cache.directory", dir)
+	viper.Set("cache.enabled", true)
+
+	key := getCacheKey("users", "example.com", nil)
+	if err := writeToCache(key, []string{"recovery@example.com"}, time.Hour); err != nil {
+		t.Fatalf("writeToCache() error = %v", err)
+	}
+	raw, _ = os.ReadFile(filepath.Join(dir, key))
+	if strings.Contains(string(raw), "recovery@example.com") {
+		t.Errorf("cache entry stored in plaintext: %s", raw)
+	}
+	data, err := readFromCache[[]string](key, time.Hour)
+	if err != nil {
+		t.Fatalf("readFromCache() error = %v", err)
+	}
+	if len(data) != 1 || data[0] != "recovery@example.com" {
+		t.Errorf("readFromCache() = %v, want decrypted entry", data)
+	}
+
+	stats, err := getCacheStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stats.EncryptionEnabled || stats.KeySource != keySourceEnv || stats.EncryptedEntries != 1 {
+		t.Errorf("getCacheStats() encryption = %v/%s/%d, want enabled/env/1", stats.EncryptionEnabled, stats.KeySource, stats.EncryptedEntries)
+	}
+}
@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	admin "google.golang.org/api/admin/directory/v1"
+)
+
+// withEndpointSettings sets the endpoint flags and config for one test
+func withEndpointSettings(t *testing.T, flags []string, config map[string]interface{}) {
+	t.Helper()
+	originalFlags := apiEndpointFlags
+	originalConfig := viper.Get("api-endpoint")
+	originalNoAuth := viper.Get("no-auth")
+	t.Cleanup(func() {
+		apiEndpointFlags = originalFlags
+		viper.Set("api-endpoint", originalConfig)
+		viper.Set("no-auth", originalNoAuth)
+	})
+
+	apiEndpointFlags = flags
+	viper.Set("api-endpoint", config)
+}
+
+func TestParseEndpointFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:   "default only",
+			values: []string{"http://localhost:8080"},
+			want:   map[string]string{"default": "http://localhost:8080"},
+		},
+		{
+			name:   "per service",
+			values: []string{"calendar=http://localhost:9000", "http://localhost:8080"},
+			want:   map[string]string{"calendar": "http://localhost:9000", "default": "http://localhost:8080"},
+		},
+		{
+			name:   "query string with equals is a url",
+			values: []string{"http://localhost:8080/?a=b"},
+			want:   map[string]string{"default": "http://localhost:8080/?a=b"},
+		},
+		{
+			name:    "unknown service",
+			values:  []string{"drive=http://localhost:8080"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseEndpointFlags(tt.values)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseEndpointFlags() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("parseEndpointFlags()[%q] = %q, want %q", k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestNormalizeEndpoint(t *testing.T) {
+	tests := []struct {
+		service string
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{"admin", "http://localhost:8080", "http://localhost:8080/", false},
+		{"calendar", "http://localhost:8080", "http://localhost:8080/calendar/v3/", false},
+		{"groupssettings", "http://localhost:8080/", "http://localhost:8080/groups/v1/groups/", false},
+		{"calendar", "https://emulator.internal/cal", "https://emulator.internal/cal/", false},
+		{"admin", "localhost:8080", "", true},
+		{"admin", "ftp://localhost", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.service+" "+tt.raw, func(t *testing.T) {
+			got, err := normalizeEndpoint(tt.service, tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalizeEndpoint() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("normalizeEndpoint() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIEndpointPrecedence(t *testing.T) {
+	tests := []struct {
+		name    string
+		flags   []string
+		config  map[string]interface{}
+		service string
+		want    string
+	}{
+		{
+			name:    "no override",
+			service: "admin",
+			want:    "",
+		},
+		{
+			name:    "config default",
+			config:  map[string]interface{}{"default": "http://config:1"},
+			service: "reports",
+			want:    "http://config:1/",
+		},
+		{
+			name:    "config service beats config default",
+			config:  map[string]interface{}{"default": "http://config:1", "calendar": "http://config:2"},
+			service: "calendar",
+			want:    "http://config:2/calendar/v3/",
+		},
+		{
+			name:    "flag beats config",
+			flags:   []string{"http://flag:1"},
+			config:  map[string]interface{}{"admin": "http://config:1"},
+			service: "admin",
+			want:    "http://flag:1/",
+		},
+		{
+			name:    "flag service beats flag default",
+			flags:   []string{"http://flag:1", "admin=http://flag:2"},
+			service: "admin",
+			want:    "http://flag:2/",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withEndpointSettings(t, tt.flags, tt.config)
+			got, err := apiEndpoint(tt.service)
+			if err != nil {
+				t.Fatalf("apiEndpoint() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("apiEndpoint(%q) = %q, want %q", tt.service, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthDisabled(t *testing.T) {
+	withEndpointSettings(t, nil, nil)
+	viper.Set("no-auth", true)
+	if _, err := authDisabled("admin"); err == nil || !strings.Contains(err.Error(), "api-endpoint") {
+		t.Errorf("authDisabled() without override error = %v, want api-endpoint error", err)
+	}
+
+	// Services without an override would reach Google unauthenticated
+	apiEndpointFlags = []string{"admin=http://localhost:8080"}
+	if disabled, err := authDisabled("admin"); err != nil || !disabled {
+		t.Errorf("authDisabled(admin) = %v, %v, want true, nil", disabled, err)
+	}
+	if _, err := authDisabled("reports"); err == nil || !strings.Contains(err.Error(), "reports") {
+		t.Errorf("authDisabled(reports) error = %v, want an error naming reports", err)
+	}
+	if _, err := newReportsClient(); err == nil {
+		t.Error("newReportsClient() with only an admin override succeeded, want error")
+	}
+
+	apiEndpointFlags = []string{"http://localhost:8080"}
+	if disabled, err := authDisabled(apiServiceNames()...); err != nil || !disabled {
+		t.Errorf("authDisabled() = %v, %v, want true, nil", disabled, err)
+	}
+}
+
+func TestAdminClientEndpointOverride(t *testing.T) {
+	server := newMockServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			t.Errorf("request sent with Authorization header")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(&admin.User{PrimaryEmail: "test@example.com"})
+	})
+	defer server.Close()
+
+	withEndpointSettings(t, []string{"admin=" + server.URL}, nil)
+	viper.Set("no-auth", true)
+
+	srv, err := newAdminClient()
+	if err != nil {
+		t.Fatalf("newAdminClient() error = %v", err)
+	}
+	user, err := srv.Users.Get("test@example.com").Context(apiContext()).Do()
+	if err != nil {
+		t.Fatalf("Users.Get() error = %v", err)
+	}
+	if user.PrimaryEmail != "test@example.com" {
+		t.Errorf("PrimaryEmail = %q, want test@example.com", user.PrimaryEmail)
+	}
+	if got := server.getLastRequest().URL.Path; got != "/admin/directory/v1/users/test@example.com" {
+		t.Errorf("request path = %q", got)
+	}
+}
@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/acockrell/google-admin-client/pkg/gac"
+	"google.golang.org/api/googleapi"
+)
+
+// Exit codes for failed commands. Scripts rely on them, so they must not
+// change; exitCodeTimeout and exitCodeCancelled are in context.go.
+const (
+	exitCodeError       = 1
+	exitCodeInvalid     = 2
+	exitCodeNotFound    = 3
+	exitCodeConflict    = 4
+	exitCodeForbidden   = 5
+	exitCodeRateLimited = 6
+)
+
+// errorKind names a class of failure in JSON error output
+type errorKind struct {
+	name string
+	code int
+}
+
+var (
+	kindError       = errorKind{"error", exitCodeError}
+	kindInvalid     = errorKind{"invalid", exitCodeInvalid}
+	kindNotFound    = errorKind{"not_found", exitCodeNotFound}
+	kindConflict    = errorKind{"conflict", exitCodeConflict}
+	kindForbidden   = errorKind{"forbidden", exitCodeForbidden}
+	kindRateLimited = errorKind{"rate_limited", exitCodeRateLimited}
+	kindTimeout     = errorKind{"timeout", exitCodeTimeout}
+	kindCancelled   = errorKind{"cancelled", exitCodeCancelled}
+)
+
+// gacKinds maps the library's error kinds onto the CLI's
+var gacKinds = map[error]errorKind{
+	gac.ErrInvalid:     kindInvalid,
+	gac.ErrNotFound:    kindNotFound,
+	gac.ErrConflict:    kindConflict,
+	gac.ErrForbidden:   kindForbidden,
+	gac.ErrRateLimited: kindRateLimited,
+}
+
+// commandStarted is set once flags and arguments have been accepted; errors
+// before that are usage errors
+var commandStarted bool
+
+// kindedError gives an error one of the gac error kinds, so it gets that
+// kind's exit code
+type kindedError struct {
+	err  error
+	kind error
+}
+
+func (e *kindedError) Error() string { return e.err.Error() }
+
+func (e *kindedError) Unwrap() error { return e.err }
+
+func (e *kindedError) Is(target error) bool { return target == e.kind }
+
+// withKind returns err as an error of kind, e.g. gac.ErrNotFound
+func withKind(err, kind error) error {
+	return &kindedError{err: err, kind: kind}
+}
+
+// invalidInputf reports a problem with the command's arguments, flags or
+// input; the error is a gac.ErrInvalid
+func invalidInputf(format string, a ...interface{}) error {
+	return withKind(fmt.Errorf(format, a...), gac.ErrInvalid)
+}
+
+// hintError carries advice printed after the error message
+type hintError struct {
+	err  error
+	hint string
+}
+
+func (e *hintError) Error() string { return e.err.Error() }
+
+func (e *hintError) Unwrap() error { return e.err }
+
+// withHint attaches hint to err
+func withHint(err error, hint string) error {
+	return &hintError{err: err, hint: hint}
+}
+
+// failureReasons formats a hint listing the usual causes of a failure
+func failureReasons(reasons ...string) string {
+	return "Common reasons for failure:\n  - " + strings.Join(reasons, "\n  - ")
+}
+
+// classifyError returns the kind of err. Interrupted commands are reported
+// as such whatever the error, and errors before the command ran are usage
+// errors.
+func classifyError(err error) errorKind {
+	if code, _, ok := cancellation(); ok {
+		if code == exitCodeTimeout {
+			return kindTimeout
+		}
+		return kindCancelled
+	}
+	if kind, ok := gacKinds[gac.Classify(err)]; ok {
+		return kind
+	}
+	if !commandStarted {
+		return kindInvalid
+	}
+	return kindError
+}
+
+// errorReport is the error object written to stderr with --format json
+type errorReport struct {
+	Error errorDetail `json:"error"`
+}
+
+type errorDetail struct {
+	Kind     string `json:"kind"`
+	Message  string `json:"message"`
+	ExitCode int    `json:"exit_code"`
+	Status   int    `json:"status,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+	Hint     string `json:"hint,omitempty"`
+}
+
+// reportError writes err, or the reason the command was interrupted, to w
+// and returns the exit code. It returns 0 without writing anything if the
+// command succeeded.
+func reportError(w io.Writer, err error) int {
+	_, cancelMsg, cancelled := cancellation()
+	if err == nil && !cancelled {
+		return 0
+	}
+
+	kind := classifyError(err)
+	detail := errorDetail{Kind: kind.name, ExitCode: kind.code, Message: cancelMsg}
+	if err != nil {
+		detail.Message = err.Error()
+	}
+	var gerr *googleapi.Error
+	if errors.As(err, &gerr) {
+		detail.Status = gerr.Code
+		if len(gerr.Errors) > 0 {
+			detail.Reason = gerr.Errors[0].Reason
+		}
+	}
+	var herr *hintError
+	if errors.As(err, &herr) {
+		detail.Hint = herr.hint
+	}
+
+	LogDebug("Command failed", map[string]interface{}{
+		"kind":      detail.Kind,
+		"exit_code": detail.ExitCode,
+	})
+
+	if machineReadableErrors() {
+		// Nothing more can be reported if stderr fails
+		_ = json.NewEncoder(w).Encode(errorReport{Error: detail})
+		return kind.code
+	}
+
+	if err != nil {
+		fmt.Fprintf(w, "Error: %s\n", err)
+		if detail.Hint != "" {
+			fmt.Fprintf(w, "\n%s\n", detail.Hint)
+		}
+	}
+	if cancelled {
+		fmt.Fprintln(w, cancelMsg)
+	}
+	return kind.code
+}
+
+// machineReadableErrors reports whether errors are written as a JSON
+// object, which they are for --format json and ndjson
+func machineReadableErrors() bool {
+	switch OutputFormat(formatFlag) {
+	case OutputFormatJSON, OutputFormatNDJSON:
+		return true
+	}
+	return outputFormat == OutputFormatJSON || outputFormat == OutputFormatNDJSON
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/acockrell/google-admin-client/pkg/gac"
+	"google.golang.org/api/googleapi"
+)
+
+// withErrorGlobals resets the state reportError reads for one test
+func withErrorGlobals(t *testing.T) {
+	t.Helper()
+	withCommandContext(t, context.Background())
+	originalStarted, originalFormat, originalFlag := commandStarted, outputFormat, formatFlag
+	t.Cleanup(func() {
+		commandStarted, outputFormat, formatFlag = originalStarted, originalFormat, originalFlag
+	})
+	commandStarted, outputFormat, formatFlag = true, OutputFormatPlain, ""
+}
+
+func apiError(code int, reason string) error {
+	gerr := &googleapi.Error{Code: code, Message: "api error"}
+	if reason != "" {
+		gerr.Errors = []googleapi.ErrorItem{{Reason: reason}}
+	}
+	return fmt.Errorf("failed to get user: %w", gerr)
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		notStarted bool
+		want       errorKind
+	}{
+		{name: "plain error", err: errors.New("boom"), want: kindError},
+		{name: "usage error", err: errors.New("unknown flag: --bogus"), notStarted: true, want: kindInvalid},
+		{name: "invalid input", err: invalidInputf("must provide --from and --to"), want: kindInvalid},
+		{name: "bad request", err: apiError(400, "invalid"), want: kindInvalid},
+		{name: "not found", err: apiError(404, "notFound"), want: kindNotFound},
+		{name: "conflict", err: apiError(409, "duplicate"), want: kindConflict},
+		{name: "forbidden", err: apiError(403, "forbidden"), want: kindForbidden},
+		{name: "quota", err: apiError(403, "rateLimitExceeded"), want: kindRateLimited},
+		{name: "too many requests", err: apiError(429, ""), want: kindRateLimited},
+		{name: "not authenticated", err: errNotAuthenticated, want: kindForbidden},
+		{name: "library error", err: gac.ErrNoChanges, want: kindInvalid},
+		{name: "hinted", err: withHint(apiError(404, ""), "check the address"), want: kindNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withErrorGlobals(t)
+			commandStarted = !tt.notStarted
+			if got := classifyError(tt.err); got != tt.want {
+				t.Errorf("classifyError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifyErrorCancelled(t *testing.T) {
+	withErrorGlobals(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	commandCtx = ctx
+
+	// The API error is a symptom of the cancellation
+	if got := classifyError(apiError(404, "")); got != kindCancelled {
+		t.Errorf("classifyError() = %v, want %v", got, kindCancelled)
+	}
+}
+
+func TestReportError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+		want     []string
+	}{
+		{name: "success", err: nil, wantCode: 0},
+		{name: "not found", err: apiError(404, "notFound"), wantCode: exitCodeNotFound, want: []string{"Error: failed to get user: googleapi: Error 404: api error"}},
+		{
+			name:     "hint",
+			err:      withHint(invalidInputf("no settings specified to update"), "Use --help to see available flags."),
+			wantCode: exitCodeInvalid,
+			want:     []string{"Error: no settings specified to update\n", "\nUse --help to see available flags.\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withErrorGlobals(t)
+			var buf bytes.Buffer
+			if code := reportError(&buf, tt.err); code != tt.wantCode {
+				t.Errorf("reportError() = %d, want %d", code, tt.wantCode)
+			}
+			if tt.want == nil && buf.Len() > 0 {
+				t.Errorf("reportError() wrote %q, want nothing", buf.String())
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("reportError() wrote %q, want it to contain %q", buf.String(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestReportErrorJSON(t *testing.T) {
+	withErrorGlobals(t)
+	formatFlag = "json"
+
+	var buf bytes.Buffer
+	err := withHint(apiError(403, "quotaExceeded"), "retry later")
+	if code := reportError(&buf, err); code != exitCodeRateLimited {
+		t.Errorf("reportError() = %d, want %d", code, exitCodeRateLimited)
+	}
+
+	var report errorReport
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, buf.String())
+	}
+	want := errorDetail{
+		Kind:     "rate_limited",
+		Message:  err.Error(),
+		ExitCode: exitCodeRateLimited,
+		Status:   403,
+		Reason:   "quotaExceeded",
+		Hint:     "retry later",
+	}
+	if report.Error != want {
+		t.Errorf("error object = %+v, want %+v", report.Error, want)
+	}
+}
+
+func TestReportErrorCancelledWithoutError(t *testing.T) {
+	withErrorGlobals(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	commandCtx = ctx
+
+	var buf bytes.Buffer
+	if code := reportError(&buf, nil); code != exitCodeCancelled {
+		t.Errorf("reportError() = %d, want %d", code, exitCodeCancelled)
+	}
+	if got := buf.String(); got != "Cancelled\n" {
+		t.Errorf("reportError() wrote %q, want %q", got, "Cancelled\n")
+	}
+}
@@ -0,0 +1,436 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Global output selection settings (set by the --fields and --query flags)
+var (
+	fieldsFlag string
+	queryFlag  string
+
+	outputFields []jsonPath
+	outputQuery  *jsonPath
+)
+
+// stepKind is the type of one step in a jsonPath
+type stepKind int
+
+const (
+	stepKey       stepKind = iota // .name or ['name']
+	stepIndex                     // [n]; negative counts from the end
+	stepWildcard                  // .* or [*]
+	stepRecursive                 // ..name
+)
+
+type pathStep struct {
+	kind  stepKind
+	key   string
+	index int
+}
+
+// jsonPath is a parsed JSONPath-style expression such as
+// organizations[0].department, $[*].primaryEmail or $..email. It is
+// evaluated against the JSON form of command output.
+type jsonPath struct {
+	expr  string
+	steps []pathStep
+}
+
+// parseJSONPath parses expr. The leading "$" and the dot before the first
+// key are optional.
+func parseJSONPath(expr string) (jsonPath, error) {
+	p := jsonPath{expr: expr}
+	s := strings.TrimSpace(expr)
+	s = strings.TrimPrefix(s, "$")
+	if s == "" {
+		if expr == "" {
+			return p, errors.New("empty path")
+		}
+		return p, nil
+	}
+	if s[0] != '.' && s[0] != '[' {
+		s = "." + s
+	}
+
+	for s != "" {
+		switch {
+		case strings.HasPrefix(s, ".."):
+			name, rest := splitKey(s[2:])
+			if name == "" {
+				return p, fmt.Errorf("missing key after '..' in %q", expr)
+			}
+			p.steps = append(p.steps, pathStep{kind: stepRecursive, key: name})
+			s = rest
+		case s[0] == '.':
+			name, rest := splitKey(s[1:])
+			switch name {
+			case "":
+				return p, fmt.Errorf("missing key after '.' in %q", expr)
+			case "*":
+				p.steps = append(p.steps, pathStep{kind: stepWildcard})
+			default:
+				p.steps = append(p.steps, pathStep{kind: stepKey, key: name})
+			}
+			s = rest
+		case s[0] == '[':
+			end := closingBracket(s)
+			if end < 0 {
+				return p, fmt.Errorf("unclosed '[' in %q", expr)
+			}
+			step, err := parseBracket(s[1:end])
+			if err != nil {
+				return p, fmt.Errorf("%w in %q", err, expr)
+			}
+			p.steps = append(p.steps, step)
+			s = s[end+1:]
+		default:
+			return p, fmt.Errorf("unexpected %q in %q", s[0], expr)
+		}
+	}
+	return p, nil
+}
+
+// splitKey splits a bare key off the front of s
+func splitKey(s string) (string, string) {
+	end := strings.IndexAny(s, ".[")
+	if end < 0 {
+		return s, ""
+	}
+	return s[:end], s[end:]
+}
+
+// closingBracket returns the index of the ']' closing the '[' at s[0],
+// skipping brackets inside quotes, or -1
+func closingBracket(s string) int {
+	var quote byte
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == ']':
+			return i
+		}
+	}
+	return -1
+}
+
+// parseBracket parses the inside of [...]
+func parseBracket(s string) (pathStep, error) {
+	s = strings.TrimSpace(s)
+	if s == "*" {
+		return pathStep{kind: stepWildcard}, nil
+	}
+	if len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		return pathStep{kind: stepKey, key: s[1 : len(s)-1]}, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return pathStep{}, fmt.Errorf("invalid index [%s]", s)
+	}
+	return pathStep{kind: stepIndex, index: n}, nil
+}
+
+// multiple reports whether the path can match more than one value
+func (p jsonPath) multiple() bool {
+	for _, step := range p.steps {
+		if step.kind == stepWildcard || step.kind == stepRecursive {
+			return true
+		}
+	}
+	return false
+}
+
+// eval returns the values p matches in doc, which must be decoded JSON
+func (p jsonPath) eval(doc interface{}) []interface{} {
+	values := []interface{}{doc}
+	for _, step := range p.steps {
+		var next []interface{}
+		for _, v := range values {
+			next = append(next, step.apply(v)...)
+		}
+		values = next
+	}
+	return values
+}
+
+func (s pathStep) apply(v interface{}) []interface{} {
+	switch s.kind {
+	case stepKey:
+		if m, ok := v.(map[string]interface{}); ok {
+			if child, ok := lookupKey(m, s.key); ok {
+				return []interface{}{child}
+			}
+		}
+	case stepIndex:
+		if list, ok := v.([]interface{}); ok {
+			i := s.index
+			if i < 0 {
+				i += len(list)
+			}
+			if i >= 0 && i < len(list) {
+				return []interface{}{list[i]}
+			}
+		}
+	case stepWildcard:
+		switch v := v.(type) {
+		case []interface{}:
+			return v
+		case map[string]interface{}:
+			var children []interface{}
+			for _, k := range sortedKeys(v) {
+				children = append(children, v[k])
+			}
+			return children
+		}
+	case stepRecursive:
+		return findKey(v, s.key)
+	}
+	return nil
+}
+
+// lookupKey finds key in m, falling back to a case-insensitive match so
+// header-style names such as "PrimaryEmail" work
+func lookupKey(m map[string]interface{}, key string) (interface{}, bool) {
+	if v, ok := m[key]; ok {
+		return v, true
+	}
+	for _, k := range sortedKeys(m) {
+		if strings.EqualFold(k, key) {
+			return m[k], true
+		}
+	}
+	return nil, false
+}
+
+// findKey returns the values of key at any depth in v, outermost first
+func findKey(v interface{}, key string) []interface{} {
+	var found []interface{}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if child, ok := lookupKey(v, key); ok {
+			found = append(found, child)
+		}
+		for _, k := range sortedKeys(v) {
+			found = append(found, findKey(v[k], key)...)
+		}
+	case []interface{}:
+		for _, child := range v {
+			found = append(found, findKey(child, key)...)
+		}
+	}
+	return found
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// parseOutputSelection parses the --fields and --query flags
+func parseOutputSelection(fields, query string) error {
+	outputFields, outputQuery = nil, nil
+	for _, expr := range splitFields(fields) {
+		p, err := parseJSONPath(expr)
+		if err != nil {
+			return fmt.Errorf("invalid --fields: %w", err)
+		}
+		outputFields = append(outputFields, p)
+	}
+	if strings.TrimSpace(query) != "" {
+		p, err := parseJSONPath(query)
+		if err != nil {
+			return fmt.Errorf("invalid --query: %w", err)
+		}
+		outputQuery = &p
+	}
+	return nil
+}
+
+// splitFields splits a --fields value on commas outside brackets
+func splitFields(s string) []string {
+	var fields []string
+	depth, start := 0, 0
+	for i := 0; i <= len(s); i++ {
+		if i < len(s) {
+			switch s[i] {
+			case '[':
+				depth++
+			case ']':
+				depth--
+			}
+			if s[i] != ',' || depth > 0 {
+				continue
+			}
+		}
+		if field := strings.TrimSpace(s[start:i]); field != "" {
+			fields = append(fields, field)
+		}
+		start = i + 1
+	}
+	return fields
+}
+
+// hasOutputSelection reports whether --fields or --query is set
+func hasOutputSelection() bool {
+	return outputQuery != nil || len(outputFields) > 0
+}
+
+// selectOutput applies --query and then --fields to data. It returns data
+// and headers unchanged when neither flag is set; otherwise the headers
+// describe the selected values.
+func selectOutput(data interface{}, headers []string) (interface{}, []string, error) {
+	if !hasOutputSelection() {
+		return data, headers, nil
+	}
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode output for selection: %w", err)
+	}
+	var doc interface{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode output for selection: %w", err)
+	}
+
+	if outputQuery != nil {
+		matches := outputQuery.eval(doc)
+		switch {
+		case outputQuery.multiple():
+			doc = matches
+		case len(matches) == 1:
+			doc = matches[0]
+		default:
+			doc = nil
+		}
+	}
+
+	// CSV and table output flatten the selected objects into columns
+	if len(outputFields) == 0 {
+		return doc, nil, nil
+	}
+
+	headers = make([]string, len(outputFields))
+	for i, field := range outputFields {
+		headers[i] = field.expr
+	}
+	list, ok := doc.([]interface{})
+	if !ok {
+		return newFieldRecord(doc), headers, nil
+	}
+	records := make([]fieldRecord, len(list))
+	for i, item := range list {
+		records[i] = newFieldRecord(item)
+	}
+	return records, headers, nil
+}
+
+// fieldRecord holds the --fields values for one item, in flag order. It
+// also holds decoded objects whose key order matters; see decodeOrdered.
+type fieldRecord struct {
+	keys   []string
+	values []interface{}
+}
+
+func newFieldRecord(item interface{}) fieldRecord {
+	r := fieldRecord{}
+	for _, field := range outputFields {
+		var value interface{}
+		matches := field.eval(item)
+		switch {
+		case field.multiple():
+			value = matches
+		case len(matches) == 1:
+			value = matches[0]
+		}
+		r.keys = append(r.keys, field.expr)
+		r.values = append(r.values, value)
+	}
+	return r
+}
+
+// MarshalJSON writes the record as an object with keys in --fields order
+func (r fieldRecord) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range r.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(r.values[i])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// MarshalYAML writes the record as a mapping with keys in --fields order
+func (r fieldRecord) MarshalYAML() (interface{}, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for i, key := range r.keys {
+		var k, v yaml.Node
+		if err := k.Encode(key); err != nil {
+			return nil, err
+		}
+		if err := v.Encode(r.values[i]); err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, &k, &v)
+	}
+	return node, nil
+}
+
+// row returns the record's values as CSV or table cells
+func (r fieldRecord) row() []string {
+	row := make([]string, len(r.values))
+	for i, v := range r.values {
+		row[i] = tableCell(v)
+	}
+	return row
+}
+
+// formatCell renders a decoded JSON value as a single CSV or table cell;
+// objects and lists are written as compact JSON
+func formatCell(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case map[string]interface{}, []interface{}:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		return string(b)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	admin "google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/googleapi"
+)
+
+// withOutputSelection sets --fields and --query for one test
+func withOutputSelection(t *testing.T, fields, query string) {
+	t.Helper()
+	originalFields, originalQuery := outputFields, outputQuery
+	t.Cleanup(func() { outputFields, outputQuery = originalFields, originalQuery })
+	if err := parseOutputSelection(fields, query); err != nil {
+		t.Fatalf("parseOutputSelection(%q, %q) error = %v", fields, query, err)
+	}
+}
+
+func TestParseJSONPath(t *testing.T) {
+	tests := []struct {
+		expr    string
+		want    []pathStep
+		wantErr bool
+	}{
+		{expr: "name.fullName", want: []pathStep{{kind: stepKey, key: "name"}, {kind: stepKey, key: "fullName"}}},
+		{expr: "$.organizations[0].department", want: []pathStep{{kind: stepKey, key: "organizations"}, {kind: stepIndex}, {kind: stepKey, key: "department"}}},
+		{expr: "$[*].primaryEmail", want: []pathStep{{kind: stepWildcard}, {kind: stepKey, key: "primaryEmail"}}},
+		{expr: "emails[-1]", want: []pathStep{{kind: stepKey, key: "emails"}, {kind: stepIndex, index: -1}}},
+		{expr: "customSchemas['Employee Type']", want: []pathStep{{kind: stepKey, key: "customSchemas"}, {kind: stepKey, key: "Employee Type"}}},
+		{expr: "$..email", want: []pathStep{{kind: stepRecursive, key: "email"}}},
+		{expr: "name.*", want: []pathStep{{kind: stepKey, key: "name"}, {kind: stepWildcard}}},
+		{expr: "$", want: nil},
+		{expr: "", wantErr: true},
+		{expr: "name.", wantErr: true},
+		{expr: "emails[0", wantErr: true},
+		{expr: "emails[first]", wantErr: true},
+		{expr: "$..", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := parseJSONPath(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseJSONPath(%q) error = %v, wantErr %v", tt.expr, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got.steps) != len(tt.want) {
+				t.Fatalf("parseJSONPath(%q) = %+v, want %+v", tt.expr, got.steps, tt.want)
+			}
+			for i := range tt.want {
+				if got.steps[i] != tt.want[i] {
+					t.Errorf("parseJSONPath(%q) step %d = %+v, want %+v", tt.expr, i, got.steps[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSplitFields(t *testing.T) {
+	got := splitFields(" primaryEmail, customSchemas['a,b'] ,,name.fullName")
+	want := []string{"primaryEmail", "customSchemas['a,b']", "name.fullName"}
+	if len(got) != len(want) {
+		t.Fatalf("splitFields() = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("splitFields()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func testUsers() []*admin.User {
+	return []*admin.User{
+		{
+			PrimaryEmail:  "alice@example.com",
+			Name:          &admin.UserName{FullName: "Alice Smith"},
+			Organizations: []interface{}{map[string]interface{}{"department": "Engineering"}},
+			CustomSchemas: map[string]googleapi.RawMessage{"Employee_Type": googleapi.RawMessage(`"FTE"`)},
+		},
+		{
+			PrimaryEmail: "bob@example.com",
+			Name:         &admin.UserName{FullName: "Bob Jones"},
+			IsAdmin:      true,
+		},
+	}
+}
+
+func TestOutputSelection(t *testing.T) {
+	const fields = "primaryEmail,name.fullName,organizations[0].department,customSchemas.Employee_Type"
+
+	tests := []struct {
+		name   string
+		format OutputFormat
+		fields string
+		query  string
+		data   interface{}
+		want   string
+	}{
+		{
+			name:   "json fields",
+			format: OutputFormatJSON,
+			fields: "primaryEmail,name.fullName",
+			data:   testUsers(),
+			want: `[
+  {
+    "primaryEmail": "alice@example.com",
+    "name.fullName": "Alice Smith"
+  },
+  {
+    "primaryEmail": "bob@example.com",
+    "name.fullName": "Bob Jones"
+  }
+]
+`,
+		},
+		{
+			name:   "yaml fields",
+			format: OutputFormatYAML,
+			fields: "name.fullName,isAdmin",
+			data:   testUsers()[1],
+			want:   "name.fullName: Bob Jones\nisAdmin: true\n",
+		},
+		{
+			name:   "csv fields",
+			format: OutputFormatCSV,
+			fields: fields,
+			data:   testUsers(),
+			want: "primaryEmail,name.fullName,organizations[0].department,customSchemas.Employee_Type\n" +
+				"alice@example.com,Alice Smith,Engineering,FTE\n" +
+				"bob@example.com,Bob Jones,,\n",
+		},
+		{
+			name:   "plain fields",
+			format: OutputFormatPlain,
+			fields: "PrimaryEmail,isAdmin",
+			data:   testUsers(),
+			want:   "PrimaryEmail: alice@example.com\nisAdmin: \n\nPrimaryEmail: bob@example.com\nisAdmin: true\n\n",
+		},
+		{
+			name:   "query",
+			format: OutputFormatPlain,
+			query:  "$[*].primaryEmail",
+			data:   testUsers(),
+			want:   "alice@example.com\nbob@example.com\n",
+		},
+		{
+			name:   "query single value",
+			format: OutputFormatJSON,
+			query:  "[1].name",
+			data:   testUsers(),
+			want:   "{\n  \"fullName\": \"Bob Jones\"\n}\n",
+		},
+		{
+			name:   "query csv uses object keys",
+			format: OutputFormatCSV,
+			query:  "$[*].name",
+			data:   testUsers(),
+			want:   "fullName\nAlice Smith\nBob Jones\n",
+		},
+		{
+			name:   "query then fields",
+			format: OutputFormatCSV,
+			fields: "primaryEmail,isAdmin",
+			query:  "[1]",
+			data:   testUsers(),
+			want:   "primaryEmail,isAdmin\nbob@example.com,true\n",
+		},
+		{
+			name:   "recursive query",
+			format: OutputFormatJSON,
+			query:  "$..fullName",
+			data:   testUsers(),
+			want:   "[\n  \"Alice Smith\",\n  \"Bob Jones\"\n]\n",
+		},
+		{
+			name:   "query without match",
+			format: OutputFormatJSON,
+			query:  "[5]",
+			data:   testUsers(),
+			want:   "No data to display\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withRunnerGlobals(t, tt.format)
+			withOutputSelection(t, tt.fields, tt.query)
+
+			var buf bytes.Buffer
+			if err := FormatOutputWithWriter(&buf, tt.data, []string{"Email"}); err != nil {
+				t.Fatalf("FormatOutputWithWriter() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseOutputSelectionErrors(t *testing.T) {
+	withOutputSelection(t, "", "")
+	for _, tt := range []struct{ fields, query string }{
+		{fields: "primaryEmail,emails[0"},
+		{query: "$.."},
+	} {
+		if err := parseOutputSelection(tt.fields, tt.query); err == nil {
+			t.Errorf("parseOutputSelection(%q, %q) succeeded, want an error", tt.fields, tt.query)
+		}
+	}
+}
+
+func TestFormatCell(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{nil, ""},
+		{"text", "text"},
+		{float64(1234567), "1234567"},
+		{true, "true"},
+		{[]interface{}{"a", "b"}, `["a","b"]`},
+		{map[string]interface{}{"type": "work"}, `{"type":"work"}`},
+	}
+	for _, tt := range tests {
+		if got := formatCell(tt.value); got != tt.want {
+			t.Errorf("formatCell(%v) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package cmd
+
+// lockFile is a no-op on platforms without flock; atomic renames still
+// prevent partially written files.
+func lockFile(path string, exclusive bool) (func(), error) {
+	return func() {}, nil
+}
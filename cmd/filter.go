@@ -0,0 +1,381 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Global list settings (set by the --filter and --sort-by flags)
+var (
+	filterFlags []string
+	sortByFlag  string
+
+	outputFilters []listFilter
+	outputSort    *sortKey
+)
+
+// filterOp is the comparison in a listFilter
+type filterOp string
+
+const (
+	opEqual     filterOp = "="
+	opNotEqual  filterOp = "!="
+	opMatch     filterOp = "=~"
+	opNotMatch  filterOp = "!~"
+	opContains  filterOp = ":"
+	opLess      filterOp = "<"
+	opLessEq    filterOp = "<="
+	opGreater   filterOp = ">"
+	opGreaterEq filterOp = ">="
+)
+
+// filterOpChars are the characters operators are made of
+const filterOpChars = "=!~:<>"
+
+// filterOps is ordered so two-character operators are tried first
+var filterOps = []filterOp{opNotEqual, opMatch, opNotMatch, opLessEq, opGreaterEq, opEqual, opContains, opLess, opGreater}
+
+// listFilter is one --filter expression, such as suspended=true,
+// orgUnitPath=~^/Engineering or lastLoginTime<2025-01-01
+type listFilter struct {
+	expr  string
+	path  jsonPath
+	op    filterOp
+	value string
+
+	re   *regexp.Regexp // for =~ and !~
+	num  *float64       // for ordering against a number
+	date time.Time      // for ordering against a date
+}
+
+// parseFilter parses a --filter expression: a field path, an operator and
+// a value
+func parseFilter(expr string) (listFilter, error) {
+	f := listFilter{expr: expr}
+
+	at := operatorIndex(expr)
+	if at < 0 {
+		return f, fmt.Errorf("missing operator in %q (use =, !=, =~, !~, :, <, <=, > or >=)", expr)
+	}
+	for _, op := range filterOps {
+		if strings.HasPrefix(expr[at:], string(op)) {
+			f.op = op
+			break
+		}
+	}
+	if f.op == "" {
+		return f, fmt.Errorf("unknown operator in %q", expr)
+	}
+
+	field := strings.TrimSpace(expr[:at])
+	if field == "" {
+		return f, fmt.Errorf("missing field in %q", expr)
+	}
+	path, err := parseJSONPath(field)
+	if err != nil {
+		return f, err
+	}
+	f.path = path
+	f.value = strings.TrimSpace(expr[at+len(f.op):])
+
+	switch f.op {
+	case opMatch, opNotMatch:
+		if f.re, err = regexp.Compile(f.value); err != nil {
+			return f, fmt.Errorf("invalid regular expression in %q: %w", expr, err)
+		}
+	case opLess, opLessEq, opGreater, opGreaterEq:
+		if n, err := strconv.ParseFloat(f.value, 64); err == nil {
+			f.num = &n
+		} else if f.date, err = parseDate(f.value); err != nil {
+			return f, fmt.Errorf("%s in %q needs a number or a date (YYYY-MM-DD or RFC 3339)", f.op, expr)
+		}
+	case opEqual, opNotEqual:
+		if n, err := strconv.ParseFloat(f.value, 64); err == nil {
+			f.num = &n
+		}
+	}
+	return f, nil
+}
+
+// operatorIndex returns the index of the first operator character in expr
+// outside brackets and quotes, or -1
+func operatorIndex(expr string) int {
+	var quote byte
+	depth := 0
+	for i := 0; i < len(expr); i++ {
+		switch c := expr[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case depth == 0 && strings.IndexByte(filterOpChars, c) >= 0:
+			return i
+		}
+	}
+	return -1
+}
+
+// parseDate parses a date as YYYY-MM-DD or RFC 3339
+func parseDate(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", s)
+}
+
+// match reports whether doc, the decoded JSON form of an item, passes the
+// filter. A field holding a list passes if any element does; the negated
+// operators pass only if no element matches.
+func (f listFilter) match(doc interface{}) bool {
+	var values []interface{}
+	for _, v := range f.path.eval(doc) {
+		if list, ok := v.([]interface{}); ok {
+			values = append(values, list...)
+		} else {
+			values = append(values, v)
+		}
+	}
+	if len(values) == 0 {
+		values = []interface{}{nil}
+	}
+
+	op, negate := f.op, false
+	switch f.op {
+	case opNotEqual:
+		op, negate = opEqual, true
+	case opNotMatch:
+		op, negate = opMatch, true
+	}
+	for _, v := range values {
+		if f.matchValue(op, v) {
+			return !negate
+		}
+	}
+	return negate
+}
+
+func (f listFilter) matchValue(op filterOp, v interface{}) bool {
+	s := formatCell(v)
+	switch op {
+	case opEqual:
+		// The Google APIs omit false, zero and empty values
+		if v == nil {
+			return f.value == "" || f.value == "false" || f.value == "0"
+		}
+		if f.num != nil {
+			if n, err := strconv.ParseFloat(s, 64); err == nil {
+				return n == *f.num
+			}
+		}
+		return strings.EqualFold(s, f.value)
+	case opMatch:
+		return f.re.MatchString(s)
+	case opContains:
+		return strings.Contains(strings.ToLower(s), strings.ToLower(f.value))
+	}
+
+	var cmp int
+	if f.num != nil {
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return false
+		}
+		cmp = compareFloats(n, *f.num)
+	} else {
+		t, err := parseDate(s)
+		if err != nil {
+			return false
+		}
+		cmp = t.Compare(f.date)
+	}
+	switch op {
+	case opLess:
+		return cmp < 0
+	case opLessEq:
+		return cmp <= 0
+	case opGreater:
+		return cmp > 0
+	default:
+		return cmp >= 0
+	}
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// sortKey is the parsed --sort-by flag
+type sortKey struct {
+	path jsonPath
+	desc bool
+}
+
+// parseSortBy parses "field" or "field,desc"
+func parseSortBy(s string) (*sortKey, error) {
+	parts := splitFields(s)
+	if len(parts) == 0 {
+		return nil, nil
+	}
+	if len(parts) > 2 {
+		return nil, fmt.Errorf("expected field[,asc|desc], got %q", s)
+	}
+	path, err := parseJSONPath(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	key := &sortKey{path: path}
+	if len(parts) == 2 {
+		switch strings.ToLower(parts[1]) {
+		case "asc":
+		case "desc":
+			key.desc = true
+		default:
+			return nil, fmt.Errorf("sort order must be asc or desc, got %q", parts[1])
+		}
+	}
+	return key, nil
+}
+
+// less orders a before b. Numbers and dates compare by value and other
+// values as case-insensitive text; items without the field sort last.
+func (k *sortKey) less(a, b interface{}) bool {
+	va, vb := k.value(a), k.value(b)
+	switch {
+	case va == nil || vb == nil:
+		return va != nil && vb == nil
+	case k.desc:
+		return compareValues(vb, va) < 0
+	default:
+		return compareValues(va, vb) < 0
+	}
+}
+
+func (k *sortKey) value(doc interface{}) interface{} {
+	matches := k.path.eval(doc)
+	if len(matches) == 0 {
+		return nil
+	}
+	return matches[0]
+}
+
+func compareValues(a, b interface{}) int {
+	sa, sb := formatCell(a), formatCell(b)
+	if na, err := strconv.ParseFloat(sa, 64); err == nil {
+		if nb, err := strconv.ParseFloat(sb, 64); err == nil {
+			return compareFloats(na, nb)
+		}
+	}
+	if ta, err := time.Parse(time.RFC3339, sa); err == nil {
+		if tb, err := time.Parse(time.RFC3339, sb); err == nil {
+			return ta.Compare(tb)
+		}
+	}
+	return strings.Compare(strings.ToLower(sa), strings.ToLower(sb))
+}
+
+// parseListOptions parses the --filter and --sort-by flags
+func parseListOptions(filters []string, sortBy string) error {
+	outputFilters, outputSort = nil, nil
+	for _, expr := range filters {
+		f, err := parseFilter(expr)
+		if err != nil {
+			return fmt.Errorf("invalid --filter: %w", err)
+		}
+		outputFilters = append(outputFilters, f)
+	}
+	key, err := parseSortBy(sortBy)
+	if err != nil {
+		return fmt.Errorf("invalid --sort-by: %w", err)
+	}
+	outputSort = key
+	return nil
+}
+
+// hasListOptions reports whether --filter or --sort-by is set
+func hasListOptions() bool {
+	return len(outputFilters) > 0 || outputSort != nil
+}
+
+// matchesFilters reports whether doc passes every --filter
+func matchesFilters(doc interface{}) bool {
+	for _, f := range outputFilters {
+		if !f.match(doc) {
+			return false
+		}
+	}
+	return true
+}
+
+// filterAndSort applies --filter and --sort-by to a list, evaluating them
+// against the JSON form of each item. The result keeps the element type of
+// data; anything other than a list is returned unchanged.
+func filterAndSort(data interface{}) (interface{}, error) {
+	v := reflect.ValueOf(data)
+	if !hasListOptions() || (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) {
+		return data, nil
+	}
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode output for filtering: %w", err)
+	}
+	var docs []interface{}
+	if err := json.Unmarshal(b, &docs); err != nil {
+		return nil, fmt.Errorf("failed to decode output for filtering: %w", err)
+	}
+	if len(docs) != v.Len() {
+		return data, nil
+	}
+
+	var keep []int
+	for i, doc := range docs {
+		if matchesFilters(doc) {
+			keep = append(keep, i)
+		}
+	}
+	if outputSort != nil {
+		sort.SliceStable(keep, func(i, j int) bool {
+			return outputSort.less(docs[keep[i]], docs[keep[j]])
+		})
+	}
+
+	result := reflect.MakeSlice(reflect.SliceOf(v.Type().Elem()), 0, len(keep))
+	for _, i := range keep {
+		result = reflect.Append(result, v.Index(i))
+	}
+	return result.Interface(), nil
+}
+
+// matchesRecord reports whether one streamed record passes every --filter
+func matchesRecord(item interface{}) (bool, error) {
+	if len(outputFilters) == 0 {
+		return true, nil
+	}
+	b, err := json.Marshal(item)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode output for filtering: %w", err)
+	}
+	var doc interface{}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return false, fmt.Errorf("failed to decode output for filtering: %w", err)
+	}
+	return matchesFilters(doc), nil
+}
@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	admin "google.golang.org/api/admin/directory/v1"
+)
+
+// withListOptions sets --filter and --sort-by for one test
+func withListOptions(t *testing.T, filters []string, sortBy string) {
+	t.Helper()
+	originalFilters, originalSort := outputFilters, outputSort
+	t.Cleanup(func() { outputFilters, outputSort = originalFilters, originalSort })
+	if err := parseListOptions(filters, sortBy); err != nil {
+		t.Fatalf("parseListOptions(%q, %q) error = %v", filters, sortBy, err)
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		expr    string
+		path    string
+		op      filterOp
+		value   string
+		wantErr bool
+	}{
+		{expr: "suspended=true", path: "suspended", op: opEqual, value: "true"},
+		{expr: "orgUnitPath=~^/Engineering", path: "orgUnitPath", op: opMatch, value: "^/Engineering"},
+		{expr: "orgUnitPath!~Former", path: "orgUnitPath", op: opNotMatch, value: "Former"},
+		{expr: "primaryEmail != bob@example.com", path: "primaryEmail", op: opNotEqual, value: "bob@example.com"},
+		{expr: "aliases:sales", path: "aliases", op: opContains, value: "sales"},
+		{expr: "capacity>=10", path: "capacity", op: opGreaterEq, value: "10"},
+		{expr: "lastLoginTime<2025-01-01", path: "lastLoginTime", op: opLess, value: "2025-01-01"},
+		{expr: "customSchemas['a=b'].c=d", path: "customSchemas['a=b'].c", op: opEqual, value: "d"},
+		{expr: "suspended", wantErr: true},
+		{expr: "=true", wantErr: true},
+		{expr: "name~x", wantErr: true},
+		{expr: "orgUnitPath=~[", wantErr: true},
+		{expr: "lastLoginTime<yesterday", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			f, err := parseFilter(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseFilter(%q) error = %v, wantErr %v", tt.expr, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if f.path.expr != tt.path || f.op != tt.op || f.value != tt.value {
+				t.Errorf("parseFilter(%q) = %q %q %q, want %q %q %q", tt.expr, f.path.expr, f.op, f.value, tt.path, tt.op, tt.value)
+			}
+		})
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	var doc interface{}
+	if err := json.Unmarshal([]byte(`{
+		"primaryEmail": "alice@example.com",
+		"orgUnitPath": "/Engineering/Platform",
+		"aliases": ["al@example.com", "sales@example.com"],
+		"isAdmin": true,
+		"capacity": "12",
+		"lastLoginTime": "2024-11-05T10:00:00.000Z"
+	}`), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"primaryEmail=ALICE@example.com", true},
+		{"primaryEmail!=alice@example.com", false},
+		{"isAdmin=true", true},
+		{"suspended=false", true},
+		{"suspended=true", false},
+		{"suspended!=true", true},
+		{"orgUnitPath=~^/Engineering", true},
+		{"orgUnitPath!~^/Engineering", false},
+		{"aliases=sales@example.com", true},
+		{"aliases:SALES", true},
+		{"aliases!~^al@", false},
+		{"capacity=12.0", true},
+		{"capacity>10", true},
+		{"capacity<=10", false},
+		{"lastLoginTime<2025-01-01", true},
+		{"lastLoginTime>=2024-11-05T10:00:00Z", true},
+		{"creationTime>2020-01-01", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			f, err := parseFilter(tt.expr)
+			if err != nil {
+				t.Fatalf("parseFilter(%q) error = %v", tt.expr, err)
+			}
+			if got := f.match(doc); got != tt.want {
+				t.Errorf("match(%q) = %v, want %v", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSortBy(t *testing.T) {
+	tests := []struct {
+		value    string
+		wantPath string
+		wantDesc bool
+		wantErr  bool
+	}{
+		{value: "name.fullName", wantPath: "name.fullName"},
+		{value: "lastLoginTime,desc", wantPath: "lastLoginTime", wantDesc: true},
+		{value: "orgUnitPath, ASC", wantPath: "orgUnitPath"},
+		{value: "name,sideways", wantErr: true},
+		{value: "a,b,desc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		key, err := parseSortBy(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseSortBy(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && (key.path.expr != tt.wantPath || key.desc != tt.wantDesc) {
+			t.Errorf("parseSortBy(%q) = %q desc=%v, want %q desc=%v", tt.value, key.path.expr, key.desc, tt.wantPath, tt.wantDesc)
+		}
+	}
+}
+
+func TestFilterAndSortOutput(t *testing.T) {
+	users := []*admin.User{
+		{PrimaryEmail: "carol@example.com", OrgUnitPath: "/Engineering", LastLoginTime: "2025-03-01T00:00:00Z"},
+		{PrimaryEmail: "alice@example.com", OrgUnitPath: "/Engineering/Platform", LastLoginTime: "2025-05-01T00:00:00Z"},
+		{PrimaryEmail: "bob@example.com", OrgUnitPath: "/Sales", Suspended: true},
+		{PrimaryEmail: "dave@example.com", OrgUnitPath: "/Engineering", LastLoginTime: "2024-12-01T00:00:00Z"},
+	}
+
+	tests := []struct {
+		name    string
+		filters []string
+		sortBy  string
+		want    string
+	}{
+		{name: "no options", want: "carol,alice,bob,dave"},
+		{name: "regex", filters: []string{"orgUnitPath=~^/Engineering"}, want: "carol,alice,dave"},
+		{name: "filters combine", filters: []string{"orgUnitPath=~^/Engineering", "lastLoginTime>=2025-01-01"}, want: "carol,alice"},
+		{name: "omitted false", filters: []string{"suspended=false"}, want: "carol,alice,dave"},
+		{name: "sort", sortBy: "primaryEmail", want: "alice,bob,carol,dave"},
+		{name: "sort dates descending, missing last", sortBy: "lastLoginTime,desc", want: "alice,carol,dave,bob"},
+		{name: "filter and sort", filters: []string{"suspended!=true"}, sortBy: "lastLoginTime", want: "dave,carol,alice"},
+		{name: "nothing matches", filters: []string{"orgUnitPath=/Legal"}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withRunnerGlobals(t, OutputFormatCSV)
+			withListOptions(t, tt.filters, tt.sortBy)
+			withOutputSelection(t, "", "")
+			originalQuiet := quietMode
+			defer func() { quietMode = originalQuiet }()
+			quietMode = true
+
+			var buf bytes.Buffer
+			if err := FormatOutputWithWriter(&buf, users, []string{"PrimaryEmail"}); err != nil {
+				t.Fatalf("FormatOutputWithWriter() error = %v", err)
+			}
+			got := bytes.ReplaceAll(bytes.TrimSpace(buf.Bytes()), []byte("@example.com\n"), []byte(","))
+			got = bytes.TrimSuffix(got, []byte("@example.com"))
+			if string(got) != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
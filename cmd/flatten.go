@@ -0,0 +1,260 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Global flattening settings (set by the --flatten-depth and --flatten-join
+// flags)
+var (
+	flattenDepth int
+	flattenJoin  = "; "
+)
+
+// flattenRows turns each item of data into a row of CSV or table cells,
+// with nested fields as dotted columns such as name.fullName and
+// phones.0.value. Lists of plain values are joined into one cell with
+// --flatten-join. Columns keep the order of the items' fields. ok is false
+// if the items are not objects.
+func flattenRows(data interface{}) (headers []string, rows [][]string, ok bool, err error) {
+	var items []interface{}
+	v := reflect.ValueOf(data)
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		for i := 0; i < v.Len(); i++ {
+			items = append(items, v.Index(i).Interface())
+		}
+	} else {
+		items = []interface{}{data}
+	}
+
+	var columns orderedColumns
+	cells := make([]map[string]string, len(items))
+	for i, item := range items {
+		obj, isObject, err := decodeObject(item)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		if !isObject {
+			return nil, nil, false, nil
+		}
+		var keys []string
+		cells[i] = map[string]string{}
+		flattenValue("", obj, 0, func(key, cell string) {
+			keys = append(keys, key)
+			cells[i][key] = cell
+		})
+		columns.merge(keys)
+	}
+
+	for _, c := range cells {
+		row := make([]string, len(columns))
+		for j, key := range columns {
+			row[j] = c[key]
+		}
+		rows = append(rows, row)
+	}
+	return columns, rows, true, nil
+}
+
+// orderedColumns collects column names across items, placing a column
+// first seen in a later item before the known column that follows it there
+type orderedColumns []string
+
+func (c *orderedColumns) merge(keys []string) {
+	known := make(map[string]bool, len(*c))
+	for _, key := range *c {
+		known[key] = true
+	}
+	var pending []string
+	for _, key := range keys {
+		if !known[key] {
+			pending = append(pending, key)
+			continue
+		}
+		if len(pending) > 0 {
+			at := c.index(key)
+			*c = append((*c)[:at], append(pending, (*c)[at:]...)...)
+			pending = nil
+		}
+	}
+	*c = append(*c, pending...)
+}
+
+func (c orderedColumns) index(key string) int {
+	for i, k := range c {
+		if k == key {
+			return i
+		}
+	}
+	return len(c)
+}
+
+// flattenValue emits a cell for each plain value in v, named by its dotted
+// path. Below --flatten-depth levels, nested values become compact JSON.
+func flattenValue(prefix string, v interface{}, level int, emit func(key, cell string)) {
+	atDepth := flattenDepth > 0 && level >= flattenDepth
+	switch v := v.(type) {
+	case fieldRecord:
+		if atDepth {
+			emit(prefix, compactJSON(v))
+			return
+		}
+		for i, key := range v.keys {
+			flattenValue(joinKey(prefix, key), v.values[i], level+1, emit)
+		}
+	case []interface{}:
+		if plainList(v) {
+			emit(prefix, tableCell(v))
+			return
+		}
+		if atDepth {
+			emit(prefix, compactJSON(v))
+			return
+		}
+		for i, elem := range v {
+			flattenValue(joinKey(prefix, strconv.Itoa(i)), elem, level+1, emit)
+		}
+	default:
+		emit(prefix, tableCell(v))
+	}
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// plainList reports whether list holds no objects or lists
+func plainList(list []interface{}) bool {
+	for _, v := range list {
+		switch v.(type) {
+		case fieldRecord, []interface{}:
+			return false
+		}
+	}
+	return true
+}
+
+// tableCell renders a value as a CSV or table cell: lists of plain values
+// are joined with --flatten-join, objects are written as compact JSON
+func tableCell(v interface{}) string {
+	switch v := v.(type) {
+	case fieldRecord:
+		return compactJSON(v)
+	case []interface{}:
+		if !plainList(v) {
+			return compactJSON(v)
+		}
+		parts := make([]string, len(v))
+		for i, elem := range v {
+			parts[i] = formatCell(elem)
+		}
+		return strings.Join(parts, flattenJoin)
+	case json.Number:
+		return v.String()
+	default:
+		return formatCell(v)
+	}
+}
+
+// fieldCell renders a struct field as a CSV or table cell
+func fieldCell(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Struct, reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Array:
+		if v.Kind() != reflect.Struct && v.IsNil() {
+			return ""
+		}
+		decoded, err := decodeOrdered(v.Interface())
+		if err != nil {
+			return fmt.Sprintf("%v", v.Interface())
+		}
+		return tableCell(decoded)
+	}
+	return fmt.Sprintf("%v", v.Interface())
+}
+
+func compactJSON(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
+
+// decodeObject returns the JSON form of item with its keys in order, and
+// whether it is an object
+func decodeObject(item interface{}) (fieldRecord, bool, error) {
+	decoded, err := decodeOrdered(item)
+	if err != nil {
+		return fieldRecord{}, false, err
+	}
+	obj, ok := decoded.(fieldRecord)
+	return obj, ok, nil
+}
+
+// decodeOrdered converts v to its JSON form like a json.Marshal and
+// json.Unmarshal round trip, except that objects are fieldRecords that
+// keep their keys in order and numbers are json.Numbers
+func decodeOrdered(v interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode output for flattening: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	decoded, err := decodeOrderedValue(dec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode output for flattening: %w", err)
+	}
+	return decoded, nil
+}
+
+func decodeOrderedValue(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		obj := fieldRecord{}
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, ok := keyTok.(string)
+			if !ok {
+				return nil, errors.New("object key is not a string")
+			}
+			value, err := decodeOrderedValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj.keys = append(obj.keys, key)
+			obj.values = append(obj.values, value)
+		}
+		_, err := dec.Token()
+		return obj, err
+	case json.Delim('['):
+		list := []interface{}{}
+		for dec.More() {
+			value, err := decodeOrderedValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		_, err := dec.Token()
+		return list, err
+	default:
+		return tok, nil
+	}
+}
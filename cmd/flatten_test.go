@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	admin "google.golang.org/api/admin/directory/v1"
+)
+
+// withFlattenSettings sets --flatten-depth and --flatten-join for one test
+func withFlattenSettings(t *testing.T, depth int, join string) {
+	t.Helper()
+	originalDepth, originalJoin := flattenDepth, flattenJoin
+	t.Cleanup(func() { flattenDepth, flattenJoin = originalDepth, originalJoin })
+	flattenDepth, flattenJoin = depth, join
+}
+
+func TestFlattenRows(t *testing.T) {
+	users := []*admin.User{
+		{
+			PrimaryEmail: "alice@example.com",
+			Name:         &admin.UserName{FullName: "Alice Smith"},
+			Aliases:      []string{"al@example.com", "asmith@example.com"},
+			Phones:       []interface{}{map[string]interface{}{"type": "work", "value": "555-0100"}},
+		},
+		{
+			PrimaryEmail: "bob@example.com",
+			Name:         &admin.UserName{FullName: "Bob Jones"},
+			IsAdmin:      true,
+		},
+	}
+
+	tests := []struct {
+		name        string
+		depth       int
+		join        string
+		wantHeaders []string
+		wantRows    [][]string
+	}{
+		{
+			name:        "no limit",
+			join:        "; ",
+			wantHeaders: []string{"aliases", "isAdmin", "name.fullName", "phones.0.type", "phones.0.value", "primaryEmail"},
+			wantRows: [][]string{
+				{"al@example.com; asmith@example.com", "", "Alice Smith", "work", "555-0100", "alice@example.com"},
+				{"", "true", "Bob Jones", "", "", "bob@example.com"},
+			},
+		},
+		{
+			name:        "depth and join",
+			depth:       1,
+			join:        "|",
+			wantHeaders: []string{"aliases", "isAdmin", "name", "phones", "primaryEmail"},
+			wantRows: [][]string{
+				{"al@example.com|asmith@example.com", "", `{"fullName":"Alice Smith"}`, `[{"type":"work","value":"555-0100"}]`, "alice@example.com"},
+				{"", "true", `{"fullName":"Bob Jones"}`, "", "bob@example.com"},
+			},
+		},
+		{
+			name:        "depth two",
+			depth:       2,
+			join:        "; ",
+			wantHeaders: []string{"aliases", "isAdmin", "name.fullName", "phones.0", "primaryEmail"},
+			wantRows: [][]string{
+				{"al@example.com; asmith@example.com", "", "Alice Smith", `{"type":"work","value":"555-0100"}`, "alice@example.com"},
+				{"", "true", "Bob Jones", "", "bob@example.com"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withFlattenSettings(t, tt.depth, tt.join)
+			headers, rows, ok, err := flattenRows(users)
+			if err != nil || !ok {
+				t.Fatalf("flattenRows() ok = %v, error = %v", ok, err)
+			}
+			if !reflect.DeepEqual(headers, tt.wantHeaders) {
+				t.Errorf("headers = %q, want %q", headers, tt.wantHeaders)
+			}
+			if !reflect.DeepEqual(rows, tt.wantRows) {
+				t.Errorf("rows = %q, want %q", rows, tt.wantRows)
+			}
+		})
+	}
+}
+
+func TestFlattenRowsNotObjects(t *testing.T) {
+	if _, _, ok, err := flattenRows([]string{"a", "b"}); ok || err != nil {
+		t.Errorf("flattenRows([]string) ok = %v, error = %v, want false, nil", ok, err)
+	}
+}
+
+func TestOrderedColumnsMerge(t *testing.T) {
+	var columns orderedColumns
+	columns.merge([]string{"email", "name", "orgUnitPath"})
+	columns.merge([]string{"aliases", "email", "isAdmin", "orgUnitPath", "suspended"})
+	want := orderedColumns{"aliases", "email", "name", "isAdmin", "orgUnitPath", "suspended"}
+	if !reflect.DeepEqual(columns, want) {
+		t.Errorf("columns = %q, want %q", columns, want)
+	}
+}
+
+func TestFormatCSVFlattened(t *testing.T) {
+	withRunnerGlobals(t, OutputFormatCSV)
+	withFlattenSettings(t, 0, "; ")
+
+	type member struct {
+		Email  string   `json:"email"`
+		Groups []string `json:"groups"`
+	}
+	type summary struct {
+		Name            string
+		InactiveMembers bool
+		Members         []member
+	}
+	data := []summary{{Name: "eng", Members: []member{{Email: "a@example.com", Groups: []string{"x", "y"}}}}}
+
+	tests := []struct {
+		name    string
+		headers []string
+		want    string
+	}{
+		{
+			name: "nil headers",
+			want: "Name,InactiveMembers,Members.0.email,Members.0.groups\neng,false,a@example.com,x; y\n",
+		},
+		{
+			name:    "headers match fields ignoring spaces",
+			headers: []string{"Name", "Inactive Members", "Members"},
+			want:    "Name,Inactive Members,Members\neng,false,\"[{\"\"email\"\":\"\"a@example.com\"\",\"\"groups\"\":[\"\"x\"\",\"\"y\"\"]}]\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := FormatOutputWithWriter(&buf, data, tt.headers); err != nil {
+				t.Fatalf("FormatOutputWithWriter() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
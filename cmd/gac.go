@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/acockrell/google-admin-client/pkg/gac"
+	admin "google.golang.org/api/admin/directory/v1"
+	groupssettings "google.golang.org/api/groupssettings/v1"
+)
+
+// newDirectoryClient creates the gac.Directory commands use; replaced in
+// tests
+var newDirectoryClient = newRealDirectoryClient
+
+// newRealDirectoryClient creates the Directory and Groups Settings services
+// over one authenticated HTTP client
+func newRealDirectoryClient() (gac.Directory, error) {
+	client, err := newHTTPClient("admin", "groupssettings")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP client for admin service: %w", err)
+	}
+
+	adminSrv, err := newDirectoryService(client)
+	if err != nil {
+		return nil, err
+	}
+
+	opts, err := serviceOptions("groupssettings", client)
+	if err != nil {
+		return nil, err
+	}
+	settingsSrv, err := groupssettings.NewService(apiContext(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create groups settings service: %w", err)
+	}
+
+	LogDebug("Created directory client", map[string]interface{}{
+		"service": "admin",
+	})
+	return gac.NewDirectory(adminSrv, settingsSrv, getCustomerID()), nil
+}
+
+// newDirectoryService creates an admin directory service over client
+func newDirectoryService(client *http.Client) (*admin.Service, error) {
+	opts, err := serviceOptions("admin", client)
+	if err != nil {
+		return nil, err
+	}
+
+	srv, err := admin.NewService(apiContext(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create admin directory service: %w", err)
+	}
+	return srv, nil
+}
+
+// gacConfig returns the library settings for the active profile
+func gacConfig() gac.Config {
+	return gac.Config{
+		Domain:     getDomain(),
+		CustomerID: getCustomerID(),
+	}
+}
+
+// newGACClient returns a gac.Client over the Directory commands use
+func newGACClient() (*gac.Client, error) {
+	dir, err := newDirectoryClient()
+	if err != nil {
+		return nil, err
+	}
+	return gac.NewWithServices(gacConfig(), gac.Services{Directory: dir}), nil
+}
+
+// newAuditClient returns a gac.Client over the Reports API
+func newAuditClient() (*gac.Client, error) {
+	srv, err := newReportsClient()
+	if err != nil {
+		return nil, err
+	}
+	return gac.NewWithServices(gacConfig(), gac.Services{Reports: srv}), nil
+}
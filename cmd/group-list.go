@@ -1,36 +1,15 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
+	"iter"
 	"strings"
-	"sync"
 
+	"github.com/acockrell/google-admin-client/pkg/gac"
 	"github.com/spf13/cobra"
 	admin "google.golang.org/api/admin/directory/v1"
 )
 
-// staffOU lists Google Directory OUs which contain FTE vs
-// contractors. This list is used to determine if a given
-// G Suite user is an "internal" or "external" member when
-// doing group audits.
-//
-// https://stackoverflow.com/questions/10485743/contains-method-for-a-slice
-var staffOU = map[string]struct{}{
-	"/":                   {},
-	"/Customer Education": {},
-	"/Customer Success":   {},
-	"/Engineering":        {},
-	"/Marketing":          {},
-	"/Product":            {},
-	"/Sales":              {},
-	"/Sales Engineering":  {},
-}
-
-var formerEmployeesOU = map[string]struct{}{
-	"/Former employees": {},
-}
-
 type groupInfo struct {
 	Name            string
 	Description     string
@@ -50,7 +29,7 @@ var (
 var listGroupCmd = &cobra.Command{
 	Use:   "list",
 	Short: "list groups",
-	Run:   listGroupRunFunc,
+	RunE:  listGroupRunFunc,
 	Long: `
 List user(s).
 
@@ -66,88 +45,25 @@ $ gac group list operations-group@example.com --get-members
 
 func init() {
 	groupCmd.AddCommand(listGroupCmd)
+	requireScopes(listGroupCmd,
+		admin.AdminDirectoryGroupReadonlyScope,
+		admin.AdminDirectoryUserReadonlyScope,
+	)
 
 	listGroupCmd.Flags().BoolVarP(&getMembers, "get-members", "m", getMembers, "lists the group members")
 	listGroupCmd.Flags().BoolVarP(&inactiveOnly, "contains-former-employees", "i", inactiveOnly, "shows only groups with inactive members")
 }
 
-// displayGroupInfo is no longer needed with unified formatter
-
-func getGroupInfo(wg *sync.WaitGroup, client *admin.Service, group *admin.Group, results chan<- groupInfo) {
-	defer wg.Done()
-
-	var owners []string
-	externalMembers := false
-	formerEmployees := false
-
-	r, err := client.Members.List(group.Id).Do()
-	if err != nil {
-		Logger.Error().Err(err).Str("group", group.Email).Msg("Failed to list group members")
-		return
-	}
-
-	for _, m := range r.Members {
-		configuredDomain := getDomain()
-		if configuredDomain != "" && !strings.HasSuffix(m.Email, "@"+configuredDomain) {
-			externalMembers = true
-			continue
-		}
-
-		if m.Role != "MEMBER" {
-			owners = append(owners, m.Email)
-		}
-
-		if m.Type == "USER" {
-			u, err := client.Users.Get(m.Email).Do()
-			if err != nil {
-				Logger.Error().Err(err).Str("user", m.Email).Msg("Failed to get user details")
-				continue
-			}
-			if _, ok := staffOU[u.OrgUnitPath]; !ok {
-				externalMembers = true
-			}
-
-			if _, ok := formerEmployeesOU[u.OrgUnitPath]; ok {
-				formerEmployees = true
-			}
-		}
-	}
-
-	gInfo := groupInfo{
-		Name:            group.Name,
-		Description:     group.Description,
-		Email:           group.Email,
-		Owners:          strings.Join(owners, ","),
-		InactiveMembers: inactiveOnly,
-		ExternalMembers: externalMembers,
-		FormerEmployees: formerEmployees,
-	}
-
-	// Skip if filtering for former employees only and this group doesn't have any
-	if inactiveOnly && !formerEmployees {
-		return
-	}
-
-	results <- gInfo
-}
-
-// groupMember represents a group member for list output
-type groupMember struct {
-	Email  string `json:"email"`
-	Type   string `json:"type"`
-	Status string `json:"status"`
-}
-
-func listGroupRunFunc(cmd *cobra.Command, args []string) {
+func listGroupRunFunc(cmd *cobra.Command, args []string) error {
 	var group string
 
 	if len(args) > 0 {
 		group = args[0]
 	}
 
-	client, err := newAdminClient()
+	client, err := newGACClient()
 	if err != nil {
-		exitWithError(fmt.Sprintf("unable to create client: %s", err))
+		return fmt.Errorf("unable to create client: %w", err)
 	}
 
 	// if a group is supplied, display that group. otherwise, display a list of all groups
@@ -162,51 +78,23 @@ func listGroupRunFunc(cmd *cobra.Command, args []string) {
 			cacheKey := getCacheKey("group-members", groupEmail, nil)
 			cacheTTL := getCacheTTL()
 
-			var members []groupMember
+			var members []gac.MemberStatus
 
 			// Try to read from cache first
-			cachedData, err := readFromCache(cacheKey, cacheTTL)
+			members, err = readFromCache[[]gac.MemberStatus](cacheKey, cacheTTL)
 			if err == nil {
-				// Cache hit
-				if membersData, ok := cachedData.([]interface{}); ok {
-					for _, memberInterface := range membersData {
-						memberBytes, _ := json.Marshal(memberInterface)
-						var member groupMember
-						if err := json.Unmarshal(memberBytes, &member); err == nil {
-							members = append(members, member)
-						}
-					}
-					Logger.Debug().Str("key", cacheKey).Int("count", len(members)).Msg("Using cached group members")
-				}
+				Logger.Debug().Str("key", cacheKey).Int("count", len(members)).Msg("Using cached group members")
 			} else {
 				// Cache miss - fetch from API
 				Logger.Debug().Str("key", cacheKey).Err(err).Msg("Cache miss, fetching from API")
 
-				m, err := client.Members.List(groupEmail).Do()
+				// Members whose user lookup fails are left out and logged
+				members, err = client.Groups.MemberStatuses(apiContext(), groupEmail)
 				if err != nil {
-					exitWithError(err.Error())
-				}
-
-				// Build list of members with status
-				for _, i := range m.Members {
-					status := "active"
-					if i.Type == "GROUP" {
-						status = "group"
-					} else if i.Type == "USER" {
-						u, err := client.Users.Get(i.Email).Do()
-						if err != nil {
-							Logger.Error().Err(err).Str("user", i.Email).Msg("Failed to get user details")
-							continue
-						}
-						if _, ok := formerEmployeesOU[u.OrgUnitPath]; ok {
-							status = "former"
-						}
+					if members == nil {
+						return err
 					}
-					members = append(members, groupMember{
-						Email:  i.Email,
-						Type:   i.Type,
-						Status: status,
-					})
+					Logger.Error().Err(err).Str("group", groupEmail).Msg("Failed to get user details")
 				}
 
 				// Write to cache
@@ -217,20 +105,16 @@ func listGroupRunFunc(cmd *cobra.Command, args []string) {
 
 			headers := []string{"Email", "Type", "Status"}
 			if err := FormatOutput(members, headers); err != nil {
-				exitWithError(fmt.Sprintf("Failed to format output: %s", err))
+				return fmt.Errorf("failed to format output: %w", err)
 			}
 		} else {
-			groupEmail := group
-			if !strings.Contains(group, "@") {
-				groupEmail = group + "@" + getDomain()
-			}
-			g, err := client.Groups.Get(groupEmail).Do()
+			g, err := client.Groups.Get(apiContext(), group)
 			if err != nil {
-				exitWithError(err.Error())
+				return err
 			}
 
 			if err := FormatOutput(g, nil); err != nil {
-				exitWithError(fmt.Sprintf("Failed to format output: %s", err))
+				return fmt.Errorf("failed to format output: %w", err)
 			}
 		}
 	} else {
@@ -243,63 +127,112 @@ func listGroupRunFunc(cmd *cobra.Command, args []string) {
 		cacheKey := getCacheKey("groups", domain, filters)
 		cacheTTL := getCacheTTL()
 
-		var r *admin.Groups
+		var groups []*admin.Group
+		auditOpts := gac.GroupAuditOptions{FormerEmployeesOnly: inactiveOnly}
+
+		// --inactive-only drops groups after auditing, so every group is
+		// audited; otherwise only the groups --limit outputs are audited
+		limit := fetchLimit()
+		if inactiveOnly {
+			limit = 0
+		}
 
 		// Try to read from cache first
-		cachedData, err := readFromCache(cacheKey, cacheTTL)
+		groups, err = readFromCache[[]*admin.Group](cacheKey, cacheTTL)
 		if err == nil {
-			// Cache hit - unmarshal the data
-			if groupsData, ok := cachedData.([]interface{}); ok {
-				r = &admin.Groups{}
-				for _, groupInterface := range groupsData {
-					groupBytes, _ := json.Marshal(groupInterface)
-					var group admin.Group
-					if err := json.Unmarshal(groupBytes, &group); err == nil {
-						r.Groups = append(r.Groups, &group)
-					}
-				}
-				Logger.Debug().Str("key", cacheKey).Int("count", len(r.Groups)).Msg("Using cached group list")
-			}
+			Logger.Debug().Str("key", cacheKey).Int("count", len(groups)).Msg("Using cached group list")
+		} else if streamingOutput() {
+			// Stream groups as they are audited; streamed listings are not cached
+			Logger.Debug().Str("key", cacheKey).Err(err).Msg("Cache miss, streaming from API")
+			_, err := streamNDJSON(outputWriter(), auditGroups(client, client.Groups.All(apiContext()), auditOpts), "groups")
+			return err
 		} else {
 			// Cache miss - fetch from API
 			Logger.Debug().Str("key", cacheKey).Err(err).Msg("Cache miss, fetching from API")
 
-			r, err = client.Groups.List().Customer("my_customer").Do()
+			groups, err = collectList(client.Groups.All(apiContext()), limit)
 			if err != nil {
-				exitWithError(err.Error())
+				return err
 			}
 
-			// Write to cache
-			if err := writeToCache(cacheKey, r.Groups, cacheTTL); err != nil {
-				Logger.Warn().Err(err).Msg("Failed to write to cache")
+			// Write to cache, unless --limit cut the list short
+			if limit == 0 {
+				if err := writeToCache(cacheKey, groups, cacheTTL); err != nil {
+					Logger.Warn().Err(err).Msg("Failed to write to cache")
+				}
 			}
 		}
 
-		// Collect group info concurrently
-		results := make(chan groupInfo, len(r.Groups))
-		wg := new(sync.WaitGroup)
+		// A cached list is complete, so cut it down before auditing too
+		if limit > 0 && len(groups) > limit {
+			groups = groups[:limit]
+		}
+		groupInfos := auditGroupBatch(client, groups, auditOpts)
 
-		for _, g := range r.Groups {
-			wg.Add(1)
-			go getGroupInfo(wg, client, g, results)
+		// Output using unified formatter
+		headers := []string{"Name", "Description", "Email", "Owners", "Inactive Members", "External Members", "Former Employees"}
+		if err := FormatOutput(groupInfos, headers); err != nil {
+			return fmt.Errorf("failed to format output: %w", err)
 		}
+		warnPartialOutput(len(groupInfos), "groups")
+	}
 
-		// Close results channel when all goroutines are done
-		go func() {
-			wg.Wait()
-			close(results)
-		}()
+	return nil
+}
 
-		// Collect all results
-		var groupInfos []groupInfo
-		for info := range results {
-			groupInfos = append(groupInfos, info)
+// groupAuditBatchSize is how many streamed groups are audited concurrently
+const groupAuditBatchSize = 50
+
+// auditGroups audits the groups of seq in batches, yielding each batch's
+// results before the next batch is fetched
+func auditGroups(client *gac.Client, seq iter.Seq2[*admin.Group, error], opts gac.GroupAuditOptions) iter.Seq2[groupInfo, error] {
+	return func(yield func(groupInfo, error) bool) {
+		var batch []*admin.Group
+		flush := func() bool {
+			for _, info := range auditGroupBatch(client, batch, opts) {
+				if !yield(info, nil) {
+					return false
+				}
+			}
+			batch = batch[:0]
+			return true
 		}
 
-		// Output using unified formatter
-		headers := []string{"Name", "Description", "Email", "Owners", "Inactive Members", "External Members", "Former Employees"}
-		if err := FormatOutput(groupInfos, headers); err != nil {
-			exitWithError(fmt.Sprintf("Failed to format output: %s", err))
+		for g, err := range seq {
+			if err != nil {
+				if flush() {
+					yield(groupInfo{}, err)
+				}
+				return
+			}
+			batch = append(batch, g)
+			if len(batch) == groupAuditBatchSize && !flush() {
+				return
+			}
 		}
+		flush()
+	}
+}
+
+// auditGroupBatch audits the membership of groups concurrently
+func auditGroupBatch(client *gac.Client, groups []*admin.Group, opts gac.GroupAuditOptions) []groupInfo {
+	audits, err := client.Groups.Audit(apiContext(), groups, opts)
+	// Cancellation is reported once when the command exits, not per group
+	if err != nil && apiContext().Err() == nil {
+		Logger.Error().Err(err).Msg("Failed to audit some groups")
+	}
+
+	var groupInfos []groupInfo
+	for _, a := range audits {
+		groupInfos = append(groupInfos, groupInfo{
+			Name:            a.Group.Name,
+			Description:     a.Group.Description,
+			Email:           a.Group.Email,
+			Owners:          strings.Join(a.Owners, ","),
+			InactiveMembers: opts.FormerEmployeesOnly,
+			ExternalMembers: a.ExternalMembers,
+			FormerEmployees: a.FormerEmployees,
+		})
 	}
+	return groupInfos
 }
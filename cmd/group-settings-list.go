@@ -2,10 +2,10 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
+	groupssettings "google.golang.org/api/groupssettings/v1"
 )
 
 // groupSettingsListCmd represents the group-settings list command
@@ -44,6 +44,7 @@ will be automatically appended.
 
 func init() {
 	groupSettingsCmd.AddCommand(groupSettingsListCmd)
+	requireScopes(groupSettingsListCmd, groupssettings.AppsGroupsSettingsScope)
 }
 
 func groupSettingsListRunFunc(cmd *cobra.Command, args []string) error {
@@ -54,20 +55,17 @@ func groupSettingsListRunFunc(cmd *cobra.Command, args []string) error {
 
 	// Validate email
 	if err := ValidateEmail(groupEmail); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Invalid group email: %v\n", err)
-		return err
+		return invalidInputf("invalid group email: %w", err)
 	}
 
-	client, err := newGroupsSettingsClient()
+	client, err := newDirectoryClient()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating client: %v\n", err)
-		return err
+		return fmt.Errorf("failed to create client: %w", err)
 	}
 
-	settings, err := client.Groups.Get(groupEmail).Do()
+	settings, err := client.GetGroupSettings(apiContext(), groupEmail)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error getting group settings for %s: %v\n", groupEmail, err)
-		return err
+		return fmt.Errorf("failed to get group settings for %s: %w", groupEmail, err)
 	}
 
 	// Use the unified formatter for output
@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -101,6 +100,7 @@ will be automatically appended.
 
 func init() {
 	groupSettingsCmd.AddCommand(groupSettingsUpdateCmd)
+	requireScopes(groupSettingsUpdateCmd, groupssettings.AppsGroupsSettingsScope)
 
 	// Access control flags
 	groupSettingsUpdateCmd.Flags().StringVar(&whoCanJoin, "who-can-join", "", "who can join the group")
@@ -149,22 +149,19 @@ func groupSettingsUpdateRunFunc(cmd *cobra.Command, args []string) error {
 
 	// Validate email
 	if err := ValidateEmail(groupEmail); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Invalid group email: %v\n", err)
-		return err
+		return invalidInputf("invalid group email: %w", err)
 	}
 
 	// Validate custom reply-to email if provided
 	if customReplyTo != "" {
 		if err := ValidateEmail(customReplyTo); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: Invalid custom reply-to email: %v\n", err)
-			return err
+			return invalidInputf("invalid custom reply-to email: %w", err)
 		}
 	}
 
-	client, err := newGroupsSettingsClient()
+	client, err := newDirectoryClient()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating client: %v\n", err)
-		return err
+		return fmt.Errorf("failed to create client: %w", err)
 	}
 
 	// Build the update object with only the fields that were specified
@@ -318,15 +315,13 @@ func groupSettingsUpdateRunFunc(cmd *cobra.Command, args []string) error {
 	}
 
 	if !hasUpdates {
-		fmt.Fprintf(os.Stderr, "Error: No settings specified to update. Use --help to see available flags.\n")
-		return fmt.Errorf("no settings specified")
+		return withHint(invalidInputf("no settings specified to update"), "Use --help to see available flags.")
 	}
 
 	// Update the group settings
-	result, err := client.Groups.Update(groupEmail, groups).Do()
+	result, err := client.UpdateGroupSettings(apiContext(), groupEmail, groups)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error updating group settings for %s: %v\n", groupEmail, err)
-		return err
+		return fmt.Errorf("failed to update group settings for %s: %w", groupEmail, err)
 	}
 
 	fmt.Printf("Successfully updated settings for group: %s\n", result.Email)
@@ -5,15 +5,19 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
+	admin "google.golang.org/api/admin/directory/v1"
 )
 
-var force bool
+var (
+	force         bool
+	initAllScopes bool
+)
 
 // initCmd represents the init command
 var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Initialize client authentication configuration",
-	Run:   initRunFunc,
+	RunE:  initRunFunc,
 	Long: `
 Initialize client authentication configuration.
 
@@ -21,6 +25,9 @@ Usage
 -----
 
   $ gac init [-f]
+  $ gac init --no-browser
+  $ gac init --auth-timeout 10m
+  $ gac init --all-scopes
 
 Overview
 --------
@@ -29,10 +36,23 @@ This app uses a OAuth2 to authenticate with Google.  A client ID and key need
 need to be created as part of a Google application, and the client_secret.json
 file made available to this CLI.
 
-Upon first execution, an OAuth2 authentication is performed, in which a URL is
-displayed to stdout.  One must navigate to the URL and copy/paste the resulting
-key back to the CLI.  This is a one-time process, as the credential is cached
-~/.credentials/gac.json and used for subsequent executions.
+Upon first execution, an OAuth2 authentication is performed.  gac starts a
+temporary listener on 127.0.0.1, opens the consent page in your browser and
+receives the authorization code when you approve (PKCE and state are verified).
+If the browser does not open, the URL is printed to stderr.  This is a one-time
+process, as the credential is cached ~/.credentials/gac.json and used for
+subsequent executions.
+
+Each command requests only the OAuth2 scopes it needs.  When a later command
+needs access the cached token lacks, gac asks for consent to the additional
+scopes only.  Use --all-scopes to grant every scope gac can use up front, e.g.
+before working on a machine where a browser is not at hand.
+
+On headless systems (e.g. over SSH) use --no-browser.  gac prints the consent
+URL; open it on any machine, approve, then copy the URL of the page the browser
+fails to load and paste it back into the terminal.
+
+Service account key files do not need initialization; see docs/authentication.md.
 
 
 Google Setup Instructions
@@ -45,6 +65,7 @@ TBD
 
 func init() {
 	rootCmd.AddCommand(initCmd)
+	requireScopes(initCmd, admin.AdminDirectoryUserReadonlyScope)
 
 	// Here you will define your flags and configuration settings.
 
@@ -55,27 +76,35 @@ func init() {
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	initCmd.Flags().BoolVarP(&force, "force", "f", false, "Force reauthentication")
+	initCmd.Flags().BoolVar(&noBrowser, "no-browser", false, "do not open a browser; paste the redirect URL instead (for SSH sessions)")
+	initCmd.Flags().DurationVar(&authTimeout, "auth-timeout", defaultAuthTimeout, "how long to wait for browser authorization")
+	initCmd.Flags().BoolVar(&initAllScopes, "all-scopes", false, "request every OAuth2 scope gac uses instead of only the minimum")
 
 }
 
-func initRunFunc(cmd *cobra.Command, args []string) {
+func initRunFunc(cmd *cobra.Command, args []string) error {
 	if force {
 		fmt.Fprintf(os.Stderr, "removing cache file\n")
 		// remove ~/.credentials/google-admin.json
 		cacheFile, err := tokenCacheFile()
 		if err != nil {
-			exitWithError(err.Error())
+			return err
 		}
 
 		err = os.Remove(cacheFile)
 		if err != nil && !os.IsNotExist(err) {
-			exitWithError(err.Error())
+			return err
 		}
 	}
 
+	if initAllScopes {
+		requiredScopes = allScopes
+	}
+
 	_, err := newAdminClient()
 	if err != nil {
-		exitWithError(fmt.Sprintf("unable to create client: %s", err))
+		return fmt.Errorf("unable to create client: %w", err)
 	}
 
+	return nil
 }
@@ -15,6 +15,7 @@ import (
 	"sync/atomic"
 	"testing"
 
+	"github.com/acockrell/google-admin-client/pkg/gac"
 	admin "google.golang.org/api/admin/directory/v1"
 	calendar "google.golang.org/api/calendar/v3"
 	"google.golang.org/api/option"
@@ -156,12 +157,12 @@ func TestUserOperations_Integration(t *testing.T) {
 		client := createMockAdminClient(t, mockServer.Server)
 
 		// Test user creation
-		testUser := &admin.User{
-			PrimaryEmail:              "testuser@example.com",
-			ChangePasswordAtNextLogin: true,
-			Password:                  randomPassword(12),
-		}
-		updateUser(testUser, "personal@example.com", "Test", "User")
+		testUser := gac.NewUser(gac.CreateUserOptions{
+			Email:         "testuser@example.com",
+			FirstName:     "Test",
+			LastName:      "User",
+			PersonalEmail: "personal@example.com",
+		})
 
 		result, err := client.Users.Insert(testUser).Do()
 		if err != nil {
@@ -860,7 +861,7 @@ func TestHelperFunctions_Integration(t *testing.T) {
 		os.Stdout = w
 
 		// Generate and print output
-		password := randomPassword(12)
+		password := gac.RandomPassword(12)
 		fmt.Println(password)
 
 		// Restore stdout
@@ -883,11 +884,12 @@ func TestHelperFunctions_Integration(t *testing.T) {
 	})
 
 	t.Run("UserUpdateHelper", func(t *testing.T) {
-		user := &admin.User{
-			PrimaryEmail: "test@example.com",
-		}
-
-		updateUser(user, "personal@example.com", "Test", "User")
+		user := gac.NewUser(gac.CreateUserOptions{
+			Email:         "test@example.com",
+			FirstName:     "Test",
+			LastName:      "User",
+			PersonalEmail: "personal@example.com",
+		})
 
 		// Verify user was updated correctly
 		if user.Name == nil {
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"iter"
+	"reflect"
+)
+
+// limitFlag is the --limit flag: the most list items to output, 0 for all
+var limitFlag int
+
+// validateLimit checks the --limit flag
+func validateLimit(n int) error {
+	if n < 0 {
+		return fmt.Errorf("invalid --limit %d: must be 0 (no limit) or more", n)
+	}
+	return nil
+}
+
+// fetchLimit returns how many items list commands need to fetch: --limit
+// when items are output as they are fetched, or 0 for every page when
+// --filter or --sort-by must see the whole list first
+func fetchLimit() int {
+	if hasListOptions() {
+		return 0
+	}
+	return limitFlag
+}
+
+// collectList gathers the items of seq, stopping after n items so later
+// pages are never requested; n <= 0 means every page. If seq yields an
+// error, the items before it are returned with the error.
+func collectList[T any](seq iter.Seq2[T, error], n int) ([]T, error) {
+	var items []T
+	for item, err := range seq {
+		if err != nil {
+			return items, err
+		}
+		items = append(items, item)
+		if n > 0 && len(items) >= n {
+			break
+		}
+	}
+	return items, nil
+}
+
+// limitList truncates a list to --limit items, after --filter and
+// --sort-by have been applied. Anything other than a list is returned
+// unchanged.
+func limitList(data interface{}) interface{} {
+	v := reflect.ValueOf(data)
+	if limitFlag <= 0 || v.Kind() != reflect.Slice || v.Len() <= limitFlag {
+		return data
+	}
+	return v.Slice(0, limitFlag).Interface()
+}
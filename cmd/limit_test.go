@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"reflect"
+	"strings"
+	"testing"
+
+	admin "google.golang.org/api/admin/directory/v1"
+)
+
+// withLimit sets --limit for one test
+func withLimit(t *testing.T, n int) {
+	t.Helper()
+	original := limitFlag
+	t.Cleanup(func() { limitFlag = original })
+	limitFlag = n
+}
+
+// countingSeq yields 1..n and records how many items were requested
+func countingSeq(n int, requested *int) iter.Seq2[int, error] {
+	return func(yield func(int, error) bool) {
+		for i := 1; i <= n; i++ {
+			*requested = i
+			if !yield(i, nil) {
+				return
+			}
+		}
+	}
+}
+
+func TestCollectList(t *testing.T) {
+	tests := []struct {
+		name          string
+		limit         int
+		want          []int
+		wantRequested int
+	}{
+		{name: "no limit", limit: 0, want: []int{1, 2, 3, 4, 5}, wantRequested: 5},
+		{name: "limit", limit: 2, want: []int{1, 2}, wantRequested: 2},
+		{name: "limit above length", limit: 10, want: []int{1, 2, 3, 4, 5}, wantRequested: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requested int
+			got, err := collectList(countingSeq(5, &requested), tt.limit)
+			if err != nil {
+				t.Fatalf("collectList() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("collectList() = %v, want %v", got, tt.want)
+			}
+			if requested != tt.wantRequested {
+				t.Errorf("requested %d items, want %d", requested, tt.wantRequested)
+			}
+		})
+	}
+
+	failed := errors.New("page failed")
+	seq := func(yield func(int, error) bool) {
+		if yield(1, nil) {
+			yield(0, failed)
+		}
+	}
+	got, err := collectList(seq, 0)
+	if !errors.Is(err, failed) || !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("collectList() = %v, %v, want the items before the error", got, err)
+	}
+}
+
+func TestFetchLimit(t *testing.T) {
+	withLimit(t, 3)
+	if got := fetchLimit(); got != 3 {
+		t.Errorf("fetchLimit() = %d, want 3", got)
+	}
+
+	withListOptions(t, nil, "name")
+	if got := fetchLimit(); got != 0 {
+		t.Errorf("fetchLimit() with --sort-by = %d, want 0 to fetch every page", got)
+	}
+}
+
+func TestFormatOutputLimit(t *testing.T) {
+	withLimit(t, 2)
+	withListOptions(t, nil, "name,desc")
+
+	data := []map[string]string{{"name": "alpha"}, {"name": "charlie"}, {"name": "bravo"}}
+	var buf bytes.Buffer
+	if err := formatOutputAs(&buf, OutputFormatJSON, data, nil); err != nil {
+		t.Fatalf("formatOutputAs() error = %v", err)
+	}
+	var got []map[string]string
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not JSON: %v", err)
+	}
+	want := []map[string]string{{"name": "charlie"}, {"name": "bravo"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("output = %v, want the first 2 after sorting %v", got, want)
+	}
+
+	// A single object is not a list and is never limited
+	if got := limitList(map[string]string{"name": "alpha"}); !reflect.DeepEqual(got, map[string]string{"name": "alpha"}) {
+		t.Errorf("limitList() of an object = %v, want it unchanged", got)
+	}
+}
+
+func TestStreamNDJSONLimit(t *testing.T) {
+	withLimit(t, 2)
+
+	var requested int
+	var buf bytes.Buffer
+	if _, err := streamNDJSON(&buf, countingSeq(5, &requested), "numbers"); err != nil {
+		t.Fatalf("streamNDJSON() error = %v", err)
+	}
+	if buf.String() != "1\n2\n" {
+		t.Errorf("streamNDJSON() wrote %q, want the first 2 records", buf.String())
+	}
+	if requested != 2 {
+		t.Errorf("requested %d records, want reading to stop at the limit", requested)
+	}
+}
+
+func TestListUserRunFuncLimit(t *testing.T) {
+	fake := withFakeDirectory(t)
+	withRunnerGlobals(t, OutputFormatJSON)
+	withLimit(t, 3)
+
+	fake.pageSize = 2
+	for i := 0; i < 5; i++ {
+		fake.addUser(&admin.User{PrimaryEmail: fmt.Sprintf("user%d@example.com", i), Name: &admin.UserName{FullName: "User"}})
+	}
+
+	out := captureStdout(t, func() {
+		if err := listUserRunFunc(listUserCmd, nil); err != nil {
+			t.Fatalf("listUserRunFunc() error = %v", err)
+		}
+	})
+	var users []admin.User
+	if err := json.Unmarshal([]byte(out), &users); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out)
+	}
+	if len(users) != 3 || !strings.HasPrefix(users[2].PrimaryEmail, "user2@") {
+		t.Errorf("listed %d users, want the first 3 across pages", len(users))
+	}
+}
+
+func TestListGroupRunFuncLimitCached(t *testing.T) {
+	fake := withFakeDirectory(t)
+	withRunnerGlobals(t, OutputFormatJSON)
+	withTestCacheDir(t)
+	withLimit(t, 1)
+
+	var groups []*admin.Group
+	for i := 0; i < 3; i++ {
+		g := &admin.Group{Email: fmt.Sprintf("group%d@example.com", i), Name: "Group"}
+		fake.addGroup(g)
+		groups = append(groups, g)
+	}
+	if err := writeToCache(getCacheKey("groups", getDomain(), map[string]string{}), groups, getCacheTTL()); err != nil {
+		t.Fatalf("writeToCache() error = %v", err)
+	}
+
+	out := captureStdout(t, func() {
+		if err := listGroupRunFunc(listGroupCmd, nil); err != nil {
+			t.Fatalf("listGroupRunFunc() error = %v", err)
+		}
+	})
+	var infos []groupInfo
+	if err := json.Unmarshal([]byte(out), &infos); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out)
+	}
+	if len(infos) != 1 {
+		t.Errorf("listed %d groups, want 1", len(infos))
+	}
+	if fake.memberLists != 1 {
+		t.Errorf("ListMembers called %d times on a cache hit, want 1 for the one group output", fake.memberLists)
+	}
+}
+
+func TestValidateLimit(t *testing.T) {
+	if err := validateLimit(0); err != nil {
+		t.Errorf("validateLimit(0) error = %v", err)
+	}
+	if err := validateLimit(-1); err == nil {
+		t.Error("validateLimit(-1) succeeded, want error")
+	}
+}
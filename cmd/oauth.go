@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// defaultAuthTimeout bounds how long gac waits for the user to finish the
+// browser consent screen
+const defaultAuthTimeout = 5 * time.Minute
+
+// headlessRedirectURL is used by --no-browser. Nothing listens there, so the
+// browser shows a connection error and the user copies the URL from the
+// address bar back into the terminal.
+const headlessRedirectURL = "http://127.0.0.1:1/"
+
+// includeGrantedScopes asks Google to merge newly requested scopes with those
+// granted earlier, so incremental consent never drops existing access
+var includeGrantedScopes = oauth2.SetAuthURLParam("include_granted_scopes", "true")
+
+// errAuthTimeout distinguishes --auth-timeout expiring from the command
+// being cancelled or hitting --timeout
+var errAuthTimeout = errors.New("authorization timeout")
+
+var (
+	noBrowser   bool
+	authTimeout = defaultAuthTimeout
+
+	// openBrowser launches the system browser; replaced in tests
+	openBrowser = openURLInBrowser
+
+	// authInput is where the --no-browser flow reads the pasted redirect URL
+	authInput io.Reader = os.Stdin
+)
+
+// authResult carries the outcome of the OAuth2 redirect back to the caller
+type authResult struct {
+	code string
+	err  error
+}
+
+// getTokenFromWeb runs the interactive OAuth2 authorization code flow with
+// PKCE and state validation. By default it receives the code on a loopback
+// listener; with --no-browser the user pastes the redirect URL instead.
+func getTokenFromWeb(ctx context.Context, config *oauth2.Config) (*oauth2.Token, error) {
+	if authTimeout <= 0 {
+		authTimeout = defaultAuthTimeout
+	}
+	ctx, cancel := context.WithTimeoutCause(ctx, authTimeout, errAuthTimeout)
+	defer cancel()
+
+	state, err := randomState()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate OAuth2 state: %w", err)
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	var code string
+	cfg := *config
+	if noBrowser {
+		cfg.RedirectURL = headlessRedirectURL
+		code, err = receiveCodeManually(ctx, &cfg, state, verifier)
+	} else {
+		code, err = receiveCodeViaLoopback(ctx, &cfg, state, verifier)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tok, err := cfg.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+	return tok, nil
+}
+
+// receiveCodeViaLoopback starts a one-shot HTTP listener on 127.0.0.1,
+// opens the consent page in the browser and waits for the redirect
+func receiveCodeViaLoopback(ctx context.Context, cfg *oauth2.Config, state, verifier string) (string, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", fmt.Errorf("failed to start loopback listener (try --no-browser): %w", err)
+	}
+	cfg.RedirectURL = fmt.Sprintf("http://%s/", listener.Addr().String())
+
+	results := make(chan authResult, 1)
+	server := &http.Server{
+		Handler:           loopbackHandler(state, results),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			results <- authResult{err: fmt.Errorf("loopback listener failed: %w", err)}
+		}
+	}()
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			LogDebug("Failed to shut down loopback listener", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}()
+
+	authURL := cfg.AuthCodeURL(state, oauth2.AccessTypeOffline, includeGrantedScopes, oauth2.S256ChallengeOption(verifier))
+	fmt.Fprintf(os.Stderr, "Opening your browser to authorize gac. If it does not open, visit:\n\n  %s\n\n", authURL)
+	if err := openBrowser(authURL); err != nil {
+		LogWarn("Unable to open browser automatically", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	select {
+	case res := <-results:
+		return res.code, res.err
+	case <-ctx.Done():
+		if errors.Is(context.Cause(ctx), errAuthTimeout) {
+			return "", fmt.Errorf("timed out waiting for authorization after %s (use --no-browser on headless systems): %w", authTimeout, ctx.Err())
+		}
+		return "", fmt.Errorf("authorization aborted: %w", ctx.Err())
+	}
+}
+
+// loopbackHandler validates the redirect and reports the authorization code
+func loopbackHandler(state string, results chan<- authResult) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		code, err := parseAuthRedirect(r.URL.Query(), state)
+		if err != nil {
+			http.Error(w, "Authorization failed: "+html.EscapeString(err.Error()), http.StatusBadRequest)
+		} else {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_, _ = fmt.Fprint(w, "<html><body><h3>gac is authorized.</h3><p>You may close this window and return to the terminal.</p></body></html>")
+		}
+
+		// Only the first redirect counts; later requests (e.g. favicon) are ignored
+		select {
+		case results <- authResult{code: code, err: err}:
+		default:
+		}
+	})
+}
+
+// receiveCodeManually prints the consent URL and reads the redirect URL
+// (or bare authorization code) that the user pastes back
+func receiveCodeManually(ctx context.Context, cfg *oauth2.Config, state, verifier string) (string, error) {
+	authURL := cfg.AuthCodeURL(state, oauth2.AccessTypeOffline, includeGrantedScopes, oauth2.S256ChallengeOption(verifier))
+	fmt.Fprintf(os.Stderr, "Visit the following URL in a browser on any machine:\n\n  %s\n\n", authURL)
+	fmt.Fprintf(os.Stderr, "After approving, the browser will fail to load %s.\n", headlessRedirectURL)
+	fmt.Fprint(os.Stderr, "Copy the full URL from the address bar and paste it here: ")
+
+	lines := make(chan authResult, 1)
+	go func() {
+		line, err := bufio.NewReader(authInput).ReadString('\n')
+		if err != nil && line == "" {
+			lines <- authResult{err: fmt.Errorf("failed to read authorization response: %w", err)}
+			return
+		}
+		lines <- authResult{code: strings.TrimSpace(line)}
+	}()
+
+	select {
+	case res := <-lines:
+		if res.err != nil {
+			return "", res.err
+		}
+		return parseAuthResponse(res.code, state)
+	case <-ctx.Done():
+		if errors.Is(context.Cause(ctx), errAuthTimeout) {
+			return "", fmt.Errorf("timed out waiting for authorization after %s: %w", authTimeout, ctx.Err())
+		}
+		return "", fmt.Errorf("authorization aborted: %w", ctx.Err())
+	}
+}
+
+// parseAuthResponse extracts the authorization code from a pasted redirect
+// URL. A bare code is accepted as well, in which case state cannot be checked.
+func parseAuthResponse(input, state string) (string, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return "", errors.New("no authorization response provided")
+	}
+
+	if !strings.Contains(input, "://") && !strings.Contains(input, "?") {
+		LogDebug("Bare authorization code supplied, state not verified", nil)
+		return input, nil
+	}
+
+	u, err := url.Parse(input)
+	if err != nil {
+		return "", fmt.Errorf("invalid redirect URL: %w", err)
+	}
+	return parseAuthRedirect(u.Query(), state)
+}
+
+// parseAuthRedirect checks the redirect query parameters for errors and a
+// matching state before returning the authorization code
+func parseAuthRedirect(query url.Values, state string) (string, error) {
+	if e := query.Get("error"); e != "" {
+		return "", fmt.Errorf("authorization denied: %s", e)
+	}
+	if query.Get("state") != state {
+		return "", errors.New("state mismatch in authorization response (possible CSRF); please retry")
+	}
+	code := query.Get("code")
+	if code == "" {
+		return "", errors.New("authorization response did not include a code")
+	}
+	return code, nil
+}
+
+// randomState returns a URL-safe random value for the OAuth2 state parameter
+func randomState() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// openURLInBrowser opens a URL with the platform's default browser
+func openURLInBrowser(u string) error {
+	var cmd *exec.Cmd
+	// #nosec G204 - URL is generated by oauth2.Config.AuthCodeURL
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", u)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", u)
+	default:
+		cmd = exec.Command("xdg-open", u)
+	}
+	return cmd.Start()
+}
@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// newTestOAuthServer returns a token endpoint that only accepts the expected
+// code together with a PKCE verifier
+func newTestOAuthServer(t *testing.T, wantCode string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("Failed to parse token request: %v", err)
+		}
+		if r.Form.Get("code") != wantCode {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		if r.Form.Get("code_verifier") == "" {
+			t.Error("token request is missing the PKCE code_verifier")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  "access-123",
+			"refresh_token": "refresh-456",
+			"token_type":    "Bearer",
+			"expires_in":    3600,
+		})
+	}))
+}
+
+func testOAuthConfig(tokenURL string) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  "https://accounts.example.com/auth",
+			TokenURL: tokenURL,
+		},
+	}
+}
+
+func TestGetTokenFromWebLoopback(t *testing.T) {
+	tokenServer := newTestOAuthServer(t, "good-code")
+	defer tokenServer.Close()
+
+	originalOpen, originalNoBrowser := openBrowser, noBrowser
+	defer func() {
+		openBrowser, noBrowser = originalOpen, originalNoBrowser
+	}()
+	noBrowser = false
+
+	tests := []struct {
+		name        string
+		tamperState bool
+		wantErr     string
+	}{
+		{name: "successful authorization"},
+		{name: "state mismatch is rejected", tamperState: true, wantErr: "state mismatch"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Simulate the browser: follow the consent URL straight to the redirect
+			openBrowser = func(authURL string) error {
+				u, err := url.Parse(authURL)
+				if err != nil {
+					return err
+				}
+				q := u.Query()
+				if q.Get("code_challenge") == "" || q.Get("code_challenge_method") != "S256" {
+					t.Error("auth URL is missing the PKCE challenge")
+				}
+				state := q.Get("state")
+				if tt.tamperState {
+					state = "forged"
+				}
+				redirect := fmt.Sprintf("%s?code=good-code&state=%s", q.Get("redirect_uri"), url.QueryEscape(state))
+				go func() {
+					resp, err := http.Get(redirect)
+					if err == nil {
+						_ = resp.Body.Close()
+					}
+				}()
+				return nil
+			}
+
+			tok, err := getTokenFromWeb(context.Background(), testOAuthConfig(tokenServer.URL))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("getTokenFromWeb() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("getTokenFromWeb() unexpected error: %v", err)
+			}
+			if tok.AccessToken != "access-123" || tok.RefreshToken != "refresh-456" {
+				t.Errorf("unexpected token: %+v", tok)
+			}
+		})
+	}
+}
+
+func TestGetTokenFromWebTimeout(t *testing.T) {
+	originalOpen, originalTimeout := openBrowser, authTimeout
+	defer func() {
+		openBrowser, authTimeout = originalOpen, originalTimeout
+	}()
+
+	openBrowser = func(string) error { return nil }
+	authTimeout = 50 * time.Millisecond
+
+	_, err := getTokenFromWeb(context.Background(), testOAuthConfig("http://127.0.0.1:1/token"))
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("expected timeout error, got %v", err)
+	}
+}
+
+func TestGetTokenFromWebNoBrowser(t *testing.T) {
+	tokenServer := newTestOAuthServer(t, "pasted-code")
+	defer tokenServer.Close()
+
+	originalInput, originalNoBrowser := authInput, noBrowser
+	defer func() {
+		authInput, noBrowser = originalInput, originalNoBrowser
+	}()
+	noBrowser = true
+
+	// A bare code skips state validation, which lets the test avoid
+	// predicting the random state value
+	authInput = strings.NewReader("pasted-code\n")
+
+	tok, err := getTokenFromWeb(context.Background(), testOAuthConfig(tokenServer.URL))
+	if err != nil {
+		t.Fatalf("getTokenFromWeb() unexpected error: %v", err)
+	}
+	if tok.AccessToken != "access-123" {
+		t.Errorf("unexpected access token %q", tok.AccessToken)
+	}
+}
+
+func TestParseAuthResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantCode string
+		wantErr  bool
+	}{
+		{
+			name:     "full redirect URL",
+			input:    "http://127.0.0.1:1/?state=abc&code=4/xyz&scope=email",
+			wantCode: "4/xyz",
+		},
+		{
+			name:     "bare code",
+			input:    "  4/xyz  ",
+			wantCode: "4/xyz",
+		},
+		{
+			name:    "wrong state",
+			input:   "http://127.0.0.1:1/?state=other&code=4/xyz",
+			wantErr: true,
+		},
+		{
+			name:    "access denied",
+			input:   "http://127.0.0.1:1/?error=access_denied&state=abc",
+			wantErr: true,
+		},
+		{
+			name:    "missing code",
+			input:   "http://127.0.0.1:1/?state=abc",
+			wantErr: true,
+		},
+		{
+			name:    "empty input",
+			input:   "",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := parseAuthResponse(tt.input, "abc")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseAuthResponse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if code != tt.wantCode {
+				t.Errorf("parseAuthResponse() = %q, want %q", code, tt.wantCode)
+			}
+		})
+	}
+}
@@ -2,9 +2,9 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 	"strings"
 
+	"github.com/acockrell/google-admin-client/pkg/gac"
 	"github.com/spf13/cobra"
 	admin "google.golang.org/api/admin/directory/v1"
 )
@@ -57,57 +57,34 @@ Examples:
 
 func init() {
 	ouCmd.AddCommand(ouCreateCmd)
+	requireScopes(ouCreateCmd, admin.AdminDirectoryOrgunitScope)
 	ouCreateCmd.Flags().StringVarP(&ouDescription, "description", "d", "", "organizational unit description")
 	ouCreateCmd.Flags().StringVarP(&ouParent, "parent", "p", "", "parent OU path (auto-detected from path if not specified)")
 	ouCreateCmd.Flags().BoolVarP(&ouBlockInheritance, "block-inheritance", "b", false, "block policy inheritance from parent")
 }
 
 func ouCreateRunFunc(cmd *cobra.Command, args []string) error {
-	client, err := newAdminClient()
+	client, err := newGACClient()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating client: %v\n", err)
-		return err
+		return fmt.Errorf("failed to create client: %w", err)
 	}
 
 	ouPath := args[0]
 
 	// Validate OU path format
 	if !strings.HasPrefix(ouPath, "/") {
-		fmt.Fprintf(os.Stderr, "Error: OU path must start with '/'\n")
-		return fmt.Errorf("invalid OU path format")
+		return invalidInputf("OU path must start with '/'")
 	}
 
-	// Extract OU name from path
-	pathSegments := strings.Split(strings.Trim(ouPath, "/"), "/")
-	ouName := pathSegments[len(pathSegments)-1]
-
-	// Determine parent path
-	parentPath := ouParent
-	if parentPath == "" {
-		if len(pathSegments) == 1 {
-			// Top-level OU, parent is root
-			parentPath = "/"
-		} else {
-			// Nested OU, extract parent from path
-			parentSegments := pathSegments[:len(pathSegments)-1]
-			parentPath = "/" + strings.Join(parentSegments, "/")
-		}
-	}
-
-	// Create the organizational unit
-	ou := &admin.OrgUnit{
-		Name:              ouName,
-		ParentOrgUnitPath: parentPath,
-		Description:       ouDescription,
-		BlockInheritance:  ouBlockInheritance,
-	}
-
-	customerID := "my_customer"
-
-	result, err := client.Orgunits.Insert(customerID, ou).Do()
+	// Create the organizational unit; the parent defaults to the path's
+	result, err := client.OrgUnits.Create(apiContext(), gac.CreateOrgUnitOptions{
+		Path:             ouPath,
+		Parent:           ouParent,
+		Description:      ouDescription,
+		BlockInheritance: ouBlockInheritance,
+	})
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating organizational unit: %v\n", err)
-		return err
+		return fmt.Errorf("failed to create organizational unit: %w", err)
 	}
 
 	fmt.Printf("Successfully created organizational unit:\n\n")
@@ -2,9 +2,9 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/spf13/cobra"
+	admin "google.golang.org/api/admin/directory/v1"
 )
 
 var (
@@ -54,18 +54,17 @@ Before deleting an OU:
 
 func init() {
 	ouCmd.AddCommand(ouDeleteCmd)
+	requireScopes(ouDeleteCmd, admin.AdminDirectoryOrgunitScope)
 	ouDeleteCmd.Flags().BoolVarP(&ouDeleteForce, "force", "f", false, "skip confirmation prompt")
 }
 
 func ouDeleteRunFunc(cmd *cobra.Command, args []string) error {
-	client, err := newAdminClient()
+	client, err := newGACClient()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating client: %v\n", err)
-		return err
+		return fmt.Errorf("failed to create client: %w", err)
 	}
 
 	ouPath := args[0]
-	customerID := "my_customer"
 
 	// Show warning and prompt for confirmation unless --force or --yes is used
 	additionalInfo := "The OU must be empty (no users) to be deleted."
@@ -74,14 +73,14 @@ func ouDeleteRunFunc(cmd *cobra.Command, args []string) error {
 	}
 
 	// Delete the organizational unit
-	err = client.Orgunits.Delete(customerID, ouPath).Do()
+	err = client.OrgUnits.Delete(apiContext(), ouPath)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error deleting organizational unit: %v\n", err)
-		fmt.Fprintf(os.Stderr, "\nCommon reasons for failure:\n")
-		fmt.Fprintf(os.Stderr, "  - OU contains users (move them first)\n")
-		fmt.Fprintf(os.Stderr, "  - OU contains sub-OUs (delete them first)\n")
-		fmt.Fprintf(os.Stderr, "  - OU path is incorrect\n")
-		return err
+		return withHint(fmt.Errorf("failed to delete organizational unit: %w", err),
+			failureReasons(
+				"OU contains users (move them first)",
+				"OU contains sub-OUs (delete them first)",
+				"OU path is incorrect",
+			))
 	}
 
 	fmt.Printf("Successfully deleted organizational unit: %s\n", ouPath)
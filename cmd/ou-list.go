@@ -2,9 +2,10 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 
+	"github.com/acockrell/google-admin-client/pkg/gac"
 	"github.com/spf13/cobra"
+	admin "google.golang.org/api/admin/directory/v1"
 )
 
 var (
@@ -41,6 +42,7 @@ The --type flag controls what to display:
 
 func init() {
 	ouCmd.AddCommand(ouListCmd)
+	requireScopes(ouListCmd, admin.AdminDirectoryOrgunitReadonlyScope)
 	ouListCmd.Flags().StringVarP(&ouListType, "type", "t", "all", "list type: all or children")
 }
 
@@ -55,10 +57,9 @@ type ouListItem struct {
 }
 
 func ouListRunFunc(cmd *cobra.Command, args []string) error {
-	client, err := newAdminClient()
+	client, err := newGACClient()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating client: %v\n", err)
-		return err
+		return fmt.Errorf("failed to create client: %w", err)
 	}
 
 	// Determine the OU path to list
@@ -67,39 +68,22 @@ func ouListRunFunc(cmd *cobra.Command, args []string) error {
 		ouPath = args[0]
 	}
 
-	// Get the customer ID (my_customer for the current domain)
-	customerID := "my_customer"
-
-	// List organizational units
-	listCall := client.Orgunits.List(customerID)
-
-	if ouPath != "" {
-		// List specific OU and optionally its children
-		listCall = listCall.OrgUnitPath(ouPath)
-	}
-
-	if ouListType == "children" {
-		listCall = listCall.Type("children")
-	} else {
-		listCall = listCall.Type("all")
-	}
-
-	result, err := listCall.Do()
+	// List the specific OU (and its children) if a path was given
+	orgUnits, err := client.OrgUnits.List(apiContext(), gac.ListOrgUnitsOptions{Path: ouPath, Type: ouListType})
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error listing organizational units: %v\n", err)
-		return err
+		return fmt.Errorf("failed to list organizational units: %w", err)
 	}
 
-	if len(result.OrganizationUnits) == 0 {
+	if len(orgUnits) == 0 {
 		QuietPrintln("No organizational units found.")
 		return nil
 	}
 
-	QuietPrintf("Found %d organizational unit(s):\n\n", len(result.OrganizationUnits))
+	QuietPrintf("Found %d organizational unit(s):\n\n", len(orgUnits))
 
 	// Convert to simplified list items
 	var items []ouListItem
-	for _, ou := range result.OrganizationUnits {
+	for _, ou := range orgUnits {
 		blockInheritance := "No"
 		if ou.BlockInheritance {
 			blockInheritance = "Yes"
@@ -117,10 +101,11 @@ func ouListRunFunc(cmd *cobra.Command, args []string) error {
 
 	headers := []string{"Name", "Path", "Description", "ParentPath", "ID", "BlockInheritance"}
 
-	// For JSON/YAML, output full OU data
+	// For JSON/YAML, --fields/--query and --filter/--sort-by, output full OU data
 	var outputData interface{}
-	if outputFormat == OutputFormatJSON || outputFormat == OutputFormatYAML {
-		outputData = result.OrganizationUnits
+	if structuredOutput() {
+		outputData = orgUnits
+		headers = nil // flattened into a column per field
 	} else {
 		outputData = items
 	}
@@ -1,9 +1,10 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
-	"os"
 
+	"github.com/acockrell/google-admin-client/pkg/gac"
 	"github.com/spf13/cobra"
 	admin "google.golang.org/api/admin/directory/v1"
 )
@@ -61,6 +62,7 @@ Examples:
 
 func init() {
 	ouCmd.AddCommand(ouUpdateCmd)
+	requireScopes(ouUpdateCmd, admin.AdminDirectoryOrgunitScope)
 	ouUpdateCmd.Flags().StringVarP(&ouUpdateName, "name", "n", "", "new name for the organizational unit")
 	ouUpdateCmd.Flags().StringVarP(&ouUpdateDescription, "description", "d", "", "new description")
 	ouUpdateCmd.Flags().StringVarP(&ouUpdateParent, "parent", "p", "", "new parent OU path")
@@ -68,60 +70,39 @@ func init() {
 }
 
 func ouUpdateRunFunc(cmd *cobra.Command, args []string) error {
-	client, err := newAdminClient()
+	client, err := newGACClient()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating client: %v\n", err)
-		return err
+		return fmt.Errorf("failed to create client: %w", err)
 	}
 
 	ouPath := args[0]
-	customerID := "my_customer"
 
 	// Create update request with only the fields that were specified
-	ou := &admin.OrgUnit{}
-	updated := false
-
-	if ouUpdateName != "" {
-		ou.Name = ouUpdateName
-		updated = true
-	}
-
-	if ouUpdateDescription != "" {
-		ou.Description = ouUpdateDescription
-		updated = true
-	}
-
-	if ouUpdateParent != "" {
-		ou.ParentOrgUnitPath = ouUpdateParent
-		updated = true
+	opts := gac.UpdateOrgUnitOptions{
+		Name:        ouUpdateName,
+		Description: ouUpdateDescription,
+		Parent:      ouUpdateParent,
 	}
 
 	if ouUpdateBlock != "" {
+		var block bool
 		switch ouUpdateBlock {
 		case "true":
-			ou.BlockInheritance = true
-			ou.ForceSendFields = append(ou.ForceSendFields, "BlockInheritance")
+			block = true
 		case "false":
-			ou.BlockInheritance = false
-			ou.ForceSendFields = append(ou.ForceSendFields, "BlockInheritance")
+			block = false
 		default:
-			fmt.Fprintf(os.Stderr, "Error: --block-inheritance must be 'true' or 'false'\n")
-			return fmt.Errorf("invalid block-inheritance value")
+			return invalidInputf("--block-inheritance must be 'true' or 'false'")
 		}
-		updated = true
+		opts.BlockInheritance = &block
 	}
 
-	if !updated {
-		fmt.Fprintf(os.Stderr, "Error: No update fields specified. Use --name, --description, --parent, or --block-inheritance\n")
-		return fmt.Errorf("no update fields specified")
+	result, err := client.OrgUnits.Update(apiContext(), ouPath, opts)
+	if errors.Is(err, gac.ErrNoChanges) {
+		return withHint(err, "Use --name, --description, --parent, or --block-inheritance")
 	}
-
-	// Extract OU path components for the API call
-	// The API uses orgUnitPath in the form of OrgUnitId or the full path
-	result, err := client.Orgunits.Update(customerID, ouPath, ou).Do()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error updating organizational unit: %v\n", err)
-		return err
+		return fmt.Errorf("failed to update organizational unit: %w", err)
 	}
 
 	fmt.Printf("Successfully updated organizational unit:\n\n")
@@ -5,11 +5,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"os"
+	"iter"
 	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/olekukonko/tablewriter"
+	"github.com/olekukonko/tablewriter/tw"
 	"gopkg.in/yaml.v3"
 )
 
@@ -17,11 +19,16 @@ import (
 type OutputFormat string
 
 const (
-	OutputFormatJSON  OutputFormat = "json"
-	OutputFormatCSV   OutputFormat = "csv"
-	OutputFormatYAML  OutputFormat = "yaml"
-	OutputFormatTable OutputFormat = "table"
-	OutputFormatPlain OutputFormat = "plain"
+	OutputFormatJSON     OutputFormat = "json"
+	OutputFormatCSV      OutputFormat = "csv"
+	OutputFormatYAML     OutputFormat = "yaml"
+	OutputFormatTable    OutputFormat = "table"
+	OutputFormatPlain    OutputFormat = "plain"
+	OutputFormatTemplate OutputFormat = "template"
+	OutputFormatNDJSON   OutputFormat = "ndjson"
+	OutputFormatMarkdown OutputFormat = "markdown"
+	OutputFormatHTML     OutputFormat = "html"
+	OutputFormatXLSX     OutputFormat = "xlsx"
 )
 
 // Global output settings (set by root command flags)
@@ -33,22 +40,66 @@ var (
 // ValidateOutputFormat checks if the given format string is valid
 func ValidateOutputFormat(format string) error {
 	switch OutputFormat(format) {
-	case OutputFormatJSON, OutputFormatCSV, OutputFormatYAML, OutputFormatTable, OutputFormatPlain:
+	case OutputFormatJSON, OutputFormatCSV, OutputFormatYAML, OutputFormatTable, OutputFormatPlain, OutputFormatTemplate, OutputFormatNDJSON,
+		OutputFormatMarkdown, OutputFormatHTML, OutputFormatXLSX:
 		return nil
 	default:
-		return fmt.Errorf("invalid output format: %s (must be json, csv, yaml, table, or plain)", format)
+		return fmt.Errorf("invalid output format: %s (must be json, ndjson, csv, yaml, table, plain, template, markdown, html, or xlsx)", format)
 	}
 }
 
+// structuredOutput reports whether commands should output whole objects
+// rather than summary rows: for JSON, YAML and templates, and whenever
+// --fields, --query, --filter or --sort-by may refer to their fields
+func structuredOutput() bool {
+	switch outputFormat {
+	case OutputFormatJSON, OutputFormatNDJSON, OutputFormatYAML, OutputFormatTemplate:
+		return true
+	}
+	return hasOutputSelection() || hasListOptions()
+}
+
+// streamingOutput reports whether list commands should write records as
+// pages arrive instead of collecting them first
+func streamingOutput() bool {
+	return outputFormat == OutputFormatNDJSON
+}
+
 // FormatOutput formats and outputs data according to the global output settings
 func FormatOutput(data interface{}, headers []string) error {
-	return FormatOutputWithWriter(os.Stdout, data, headers)
+	return FormatOutputWithWriter(outputWriter(), data, headers)
 }
 
 // FormatOutputWithWriter formats and outputs data to the specified writer
 // This is useful for testing and for writing to different outputs
 func FormatOutputWithWriter(w io.Writer, data interface{}, headers []string) error {
-	if data == nil {
+	return formatOutputAs(w, outputFormat, data, headers)
+}
+
+// formatOutputAs formats data in format, applying --filter, --sort-by and
+// --limit and then --fields and --query
+func formatOutputAs(w io.Writer, format OutputFormat, data interface{}, headers []string) error {
+	if data != nil {
+		var err error
+		if data, err = filterAndSort(data); err != nil {
+			return err
+		}
+		data = limitList(data)
+	}
+
+	// NDJSON selects from each record, as when streaming
+	if format == OutputFormatNDJSON {
+		return formatNDJSON(w, data)
+	}
+
+	if data != nil {
+		var err error
+		if data, headers, err = selectOutput(data, headers); err != nil {
+			return err
+		}
+	}
+	// A workbook is written even when empty, since the output is binary
+	if data == nil && format != OutputFormatXLSX {
 		if !quietMode {
 			if _, err := fmt.Fprintln(w, "No data to display"); err != nil {
 				return fmt.Errorf("failed to write output: %w", err)
@@ -57,7 +108,7 @@ func FormatOutputWithWriter(w io.Writer, data interface{}, headers []string) err
 		return nil
 	}
 
-	switch outputFormat {
+	switch format {
 	case OutputFormatJSON:
 		return formatJSON(w, data)
 	case OutputFormatCSV:
@@ -68,8 +119,16 @@ func FormatOutputWithWriter(w io.Writer, data interface{}, headers []string) err
 		return formatTable(w, data, headers)
 	case OutputFormatPlain:
 		return formatPlain(w, data)
+	case OutputFormatTemplate:
+		return formatTemplate(w, data)
+	case OutputFormatMarkdown:
+		return formatMarkdown(w, data, headers)
+	case OutputFormatHTML:
+		return formatHTML(w, data, headers)
+	case OutputFormatXLSX:
+		return formatXLSX(w, data, headers)
 	default:
-		return fmt.Errorf("unsupported output format: %s", outputFormat)
+		return fmt.Errorf("unsupported output format: %s", format)
 	}
 }
 
@@ -80,6 +139,85 @@ func formatJSON(w io.Writer, data interface{}) error {
 	return encoder.Encode(data)
 }
 
+// formatNDJSON outputs each element of a list, or a single object, as one
+// line of compact JSON
+func formatNDJSON(w io.Writer, data interface{}) error {
+	enc := json.NewEncoder(w)
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		if data == nil {
+			return nil
+		}
+		return writeNDJSON(enc, data)
+	}
+	for i := 0; i < v.Len(); i++ {
+		if err := writeNDJSON(enc, v.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// streamNDJSON writes the records of seq that pass --filter as NDJSON as
+// they arrive, stopping after --limit records. With --sort-by, records are
+// collected and written once seq ends. If seq fails after Ctrl-C or
+// --timeout, the records read so far are written and a warning is logged
+// instead. It returns the number of records read.
+func streamNDJSON[T any](w io.Writer, seq iter.Seq2[T, error], what string) (int, error) {
+	enc := json.NewEncoder(w)
+	var sorted []T
+	count, written := 0, 0
+	for item, err := range seq {
+		if err != nil {
+			if _, _, cancelled := cancellation(); !cancelled || count == 0 {
+				return count, fmt.Errorf("failed to list %s: %w", what, err)
+			}
+			warnPartialOutput(count, what)
+			break
+		}
+		count++
+
+		if outputSort != nil {
+			sorted = append(sorted, item)
+			continue
+		}
+		if ok, err := matchesRecord(item); err != nil || !ok {
+			if err != nil {
+				return count, err
+			}
+			continue
+		}
+		if err := writeNDJSON(enc, item); err != nil {
+			return count, err
+		}
+		// Stopping here saves fetching the remaining pages
+		if written++; limitFlag > 0 && written >= limitFlag {
+			break
+		}
+	}
+
+	if outputSort != nil && len(sorted) > 0 {
+		return count, formatOutputAs(w, OutputFormatNDJSON, sorted, nil)
+	}
+	return count, nil
+}
+
+// writeNDJSON applies --fields and --query to one record and writes it.
+// Records the query does not match are skipped.
+func writeNDJSON(enc *json.Encoder, item interface{}) error {
+	item, _, err := selectOutput(item, nil)
+	if err != nil {
+		return err
+	}
+	if item == nil {
+		return nil
+	}
+	if err := enc.Encode(item); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
+
 // formatYAML outputs data as YAML
 func formatYAML(w io.Writer, data interface{}) error {
 	encoder := yaml.NewEncoder(w)
@@ -95,7 +233,12 @@ func formatYAML(w io.Writer, data interface{}) error {
 // formatCSV outputs data as CSV with headers
 func formatCSV(w io.Writer, data interface{}, headers []string) error {
 	writer := csv.NewWriter(w)
-	defer writer.Flush()
+
+	// Convert data to rows
+	headers, rows, err := tableRows(data, headers)
+	if err != nil {
+		return err
+	}
 
 	// Write headers
 	if len(headers) > 0 && !quietMode {
@@ -104,12 +247,6 @@ func formatCSV(w io.Writer, data interface{}, headers []string) error {
 		}
 	}
 
-	// Convert data to rows
-	rows, err := convertToRows(data, headers)
-	if err != nil {
-		return err
-	}
-
 	// Write rows
 	for _, row := range rows {
 		if err := writer.Write(row); err != nil {
@@ -117,12 +254,23 @@ func formatCSV(w io.Writer, data interface{}, headers []string) error {
 		}
 	}
 
+	writer.Flush()
 	return writer.Error()
 }
 
 // formatTable outputs data as a formatted table
 func formatTable(w io.Writer, data interface{}, headers []string) error {
 	table := tablewriter.NewWriter(w)
+	if hasOutputSelection() || len(headers) == 0 {
+		// Show field paths as given rather than reformatted
+		table.Options(tablewriter.WithHeaderAutoFormat(tw.Off))
+	}
+
+	// Convert data to rows
+	headers, rows, err := tableRows(data, headers)
+	if err != nil {
+		return err
+	}
 
 	// Set headers
 	if len(headers) > 0 && !quietMode {
@@ -134,12 +282,6 @@ func formatTable(w io.Writer, data interface{}, headers []string) error {
 		table.Header(headerAny...)
 	}
 
-	// Convert data to rows
-	rows, err := convertToRows(data, headers)
-	if err != nil {
-		return err
-	}
-
 	// Add rows to table
 	for _, row := range rows {
 		// Convert []string to []any for Append method
@@ -181,6 +323,18 @@ func formatPlain(w io.Writer, data interface{}) error {
 
 // formatPlainItem formats a single item in plain text
 func formatPlainItem(w io.Writer, item interface{}) error {
+	if r, ok := item.(fieldRecord); ok {
+		for i, key := range r.keys {
+			if _, err := fmt.Fprintf(w, "%s: %s\n", key, formatCell(r.values[i])); err != nil {
+				return fmt.Errorf("failed to write field: %w", err)
+			}
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return fmt.Errorf("failed to write newline: %w", err)
+		}
+		return nil
+	}
+
 	v := reflect.ValueOf(item)
 
 	// Handle pointer
@@ -224,8 +378,8 @@ func formatPlainItem(w io.Writer, item interface{}) error {
 
 	// Handle map
 	if v.Kind() == reflect.Map {
-		for _, key := range v.MapKeys() {
-			if _, err := fmt.Fprintf(w, "%v: %v\n", key.Interface(), v.MapIndex(key).Interface()); err != nil {
+		for _, key := range sortedMapKeys(v) {
+			if _, err := fmt.Fprintf(w, "%v: %s\n", key.Interface(), formatCell(v.MapIndex(key).Interface())); err != nil {
 				return fmt.Errorf("failed to write map entry: %w", err)
 			}
 		}
@@ -236,12 +390,28 @@ func formatPlainItem(w io.Writer, item interface{}) error {
 	}
 
 	// Fallback: just print the value
-	if _, err := fmt.Fprintln(w, item); err != nil {
+	if _, err := fmt.Fprintln(w, formatCell(item)); err != nil {
 		return fmt.Errorf("failed to write value: %w", err)
 	}
 	return nil
 }
 
+// tableRows returns the headers and rows for CSV and table output. Without
+// headers, objects are flattened into a column per nested field.
+func tableRows(data interface{}, headers []string) ([]string, [][]string, error) {
+	if len(headers) == 0 {
+		flatHeaders, rows, ok, err := flattenRows(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		if ok {
+			return flatHeaders, rows, nil
+		}
+	}
+	rows, err := convertToRows(data, headers)
+	return headers, rows, err
+}
+
 // convertToRows converts data to a slice of string slices for CSV/table output
 func convertToRows(data interface{}, headers []string) ([][]string, error) {
 	var rows [][]string
@@ -275,6 +445,10 @@ func convertToRows(data interface{}, headers []string) ([][]string, error) {
 
 // convertItemToRow converts a single item to a row of strings
 func convertItemToRow(item interface{}, headers []string) ([]string, error) {
+	if r, ok := item.(fieldRecord); ok {
+		return r.row(), nil
+	}
+
 	var row []string
 
 	v := reflect.ValueOf(item)
@@ -308,15 +482,13 @@ func convertItemToRow(item interface{}, headers []string) ([]string, error) {
 					}
 				}
 
-				fieldMap[strings.ToLower(fieldName)] = i
+				fieldMap[headerKey(fieldName)] = i
 			}
 
 			// Extract values in header order
 			for i, header := range headers {
-				headerLower := strings.ToLower(header)
-				if fieldIdx, ok := fieldMap[headerLower]; ok {
-					value := v.Field(fieldIdx)
-					row[i] = fmt.Sprintf("%v", value.Interface())
+				if fieldIdx, ok := fieldMap[headerKey(header)]; ok {
+					row[i] = fieldCell(v.Field(fieldIdx))
 				} else {
 					row[i] = ""
 				}
@@ -329,8 +501,7 @@ func convertItemToRow(item interface{}, headers []string) ([]string, error) {
 				if !field.IsExported() {
 					continue
 				}
-				value := v.Field(i)
-				row = append(row, fmt.Sprintf("%v", value.Interface()))
+				row = append(row, fieldCell(v.Field(i)))
 			}
 		}
 		return row, nil
@@ -345,35 +516,58 @@ func convertItemToRow(item interface{}, headers []string) ([]string, error) {
 				for _, key := range v.MapKeys() {
 					if strings.EqualFold(fmt.Sprintf("%v", key.Interface()), header) {
 						value := v.MapIndex(key)
-						row[i] = fmt.Sprintf("%v", value.Interface())
+						row[i] = tableCell(value.Interface())
 						break
 					}
 				}
 			}
 		} else {
 			// No headers, just dump all map values
-			for _, key := range v.MapKeys() {
+			for _, key := range sortedMapKeys(v) {
 				value := v.MapIndex(key)
-				row = append(row, fmt.Sprintf("%v", value.Interface()))
+				row = append(row, tableCell(value.Interface()))
 			}
 		}
 		return row, nil
 	}
 
 	// Fallback: convert to single string
-	return []string{fmt.Sprintf("%v", item)}, nil
+	return []string{formatCell(item)}, nil
 }
 
-// QuietPrintf prints output only if not in quiet mode
+// headerKey normalizes a header or field name for matching, so "Inactive
+// Members" finds the InactiveMembers field
+func headerKey(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, " ", ""))
+}
+
+// sortedMapKeys returns the keys of map v in a stable order
+func sortedMapKeys(v reflect.Value) []reflect.Value {
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprintf("%v", keys[i].Interface()) < fmt.Sprintf("%v", keys[j].Interface())
+	})
+	return keys
+}
+
+// QuietPrintf prints output only if not in quiet mode, for plain and table
+// output
 func QuietPrintf(format string, args ...interface{}) {
-	if !quietMode {
+	if !quietMode && humanOutput() {
 		fmt.Printf(format, args...)
 	}
 }
 
-// QuietPrintln prints output only if not in quiet mode
+// QuietPrintln prints output only if not in quiet mode, for plain and table
+// output
 func QuietPrintln(args ...interface{}) {
-	if !quietMode {
+	if !quietMode && humanOutput() {
 		fmt.Println(args...)
 	}
 }
+
+// humanOutput reports whether output is meant to be read in a terminal, so
+// that status lines around it do not corrupt documents and data formats
+func humanOutput() bool {
+	return outputFormat == OutputFormatPlain || outputFormat == OutputFormatTable
+}
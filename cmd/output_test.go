@@ -2,6 +2,9 @@ package cmd
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"iter"
 	"strings"
 	"testing"
 )
@@ -37,6 +40,31 @@ func TestValidateOutputFormat(t *testing.T) {
 			format:  "plain",
 			wantErr: false,
 		},
+		{
+			name:    "valid template",
+			format:  "template",
+			wantErr: false,
+		},
+		{
+			name:    "valid ndjson",
+			format:  "ndjson",
+			wantErr: false,
+		},
+		{
+			name:    "valid markdown",
+			format:  "markdown",
+			wantErr: false,
+		},
+		{
+			name:    "valid html",
+			format:  "html",
+			wantErr: false,
+		},
+		{
+			name:    "valid xlsx",
+			format:  "xlsx",
+			wantErr: false,
+		},
 		{
 			name:    "invalid format",
 			format:  "xml",
@@ -114,6 +142,104 @@ func TestFormatJSON(t *testing.T) {
 	}
 }
 
+func TestFormatNDJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   interface{}
+		fields string
+		query  string
+		want   string
+	}{
+		{
+			name: "list",
+			data: testUsers(),
+			want: `{"customSchemas":{"Employee_Type":"FTE"},"name":{"fullName":"Alice Smith"},"organizations":[{"department":"Engineering"}],"primaryEmail":"alice@example.com"}` + "\n" +
+				`{"isAdmin":true,"name":{"fullName":"Bob Jones"},"primaryEmail":"bob@example.com"}` + "\n",
+		},
+		{
+			name: "single object",
+			data: map[string]string{"status": "ok"},
+			want: `{"status":"ok"}` + "\n",
+		},
+		{
+			name: "empty list",
+			data: []string{},
+			want: "",
+		},
+		{
+			name:   "fields per record",
+			data:   testUsers(),
+			fields: "primaryEmail,isAdmin",
+			want:   `{"primaryEmail":"alice@example.com","isAdmin":null}` + "\n" + `{"primaryEmail":"bob@example.com","isAdmin":true}` + "\n",
+		},
+		{
+			name:  "query per record skips misses",
+			data:  testUsers(),
+			query: "organizations[0].department",
+			want:  `"Engineering"` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withRunnerGlobals(t, OutputFormatNDJSON)
+			withOutputSelection(t, tt.fields, tt.query)
+
+			var buf bytes.Buffer
+			if err := FormatOutputWithWriter(&buf, tt.data, nil); err != nil {
+				t.Fatalf("FormatOutputWithWriter() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStreamNDJSON(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	failAfter := func(n int) iter.Seq2[int, error] {
+		return func(yield func(int, error) bool) {
+			for i := 1; i <= n; i++ {
+				if !yield(i, nil) {
+					return
+				}
+			}
+			yield(0, errors.New("page failed"))
+		}
+	}
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		seq       iter.Seq2[int, error]
+		want      string
+		wantCount int
+		wantErr   bool
+	}{
+		{name: "error", ctx: context.Background(), seq: failAfter(2), want: "1\n2\n", wantCount: 2, wantErr: true},
+		{name: "cancelled keeps partial output", ctx: cancelled, seq: failAfter(2), want: "1\n2\n", wantCount: 2},
+		{name: "cancelled before any record", ctx: cancelled, seq: failAfter(0), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withRunnerGlobals(t, OutputFormatNDJSON)
+			withCommandContext(t, tt.ctx)
+
+			var buf bytes.Buffer
+			count, err := streamNDJSON(&buf, tt.seq, "numbers")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("streamNDJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if buf.String() != tt.want || count != tt.wantCount {
+				t.Errorf("streamNDJSON() wrote %q (%d), want %q (%d)", buf.String(), count, tt.want, tt.wantCount)
+			}
+		})
+	}
+}
+
 func TestFormatYAML(t *testing.T) {
 	type testData struct {
 		Name  string `json:"name"`
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Global output file settings (set by the --output-file flag)
+var (
+	outputFileFlag string
+	// outputDest receives command output while --output-file is set
+	outputDest *outputFile
+)
+
+// outputFile writes command output to a temp file next to path, which is
+// renamed over path once the command succeeds. The temp file is created on
+// the first write, so commands that output nothing leave path untouched.
+// A path ending in .gz is gzip-compressed.
+type outputFile struct {
+	path string
+	tmp  *os.File
+	gz   *gzip.Writer
+}
+
+// newOutputFile checks that path may be written and returns its outputFile
+func newOutputFile(path string) (*outputFile, error) {
+	path = expandHome(path)
+	if err := validateOutputPath(path); err != nil {
+		return nil, err
+	}
+	return &outputFile{path: path}, nil
+}
+
+func (o *outputFile) Write(p []byte) (int, error) {
+	if o.tmp == nil {
+		if err := o.open(); err != nil {
+			return 0, err
+		}
+	}
+	if o.gz != nil {
+		return o.gz.Write(p)
+	}
+	return o.tmp.Write(p)
+}
+
+func (o *outputFile) open() error {
+	tmp, err := os.CreateTemp(filepath.Dir(o.path), "."+filepath.Base(o.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	if err := tmp.Chmod(0600); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to set permissions on output file: %w", err)
+	}
+	o.tmp = tmp
+	if strings.HasSuffix(strings.ToLower(o.path), ".gz") {
+		o.gz = gzip.NewWriter(tmp)
+	}
+	return nil
+}
+
+// commit renames the temp file over path
+func (o *outputFile) commit() (err error) {
+	if o.tmp == nil {
+		return nil
+	}
+	tmpName := o.tmp.Name()
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmpName)
+		}
+	}()
+
+	if o.gz != nil {
+		if err = o.gz.Close(); err != nil {
+			_ = o.tmp.Close()
+			return fmt.Errorf("failed to compress output file: %w", err)
+		}
+	}
+	if err = o.tmp.Sync(); err != nil {
+		_ = o.tmp.Close()
+		return fmt.Errorf("failed to sync output file: %w", err)
+	}
+	if err = o.tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close output file: %w", err)
+	}
+	if err = os.Rename(tmpName, o.path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", o.path, err)
+	}
+
+	LogDebug("Wrote output file", map[string]interface{}{
+		"path": o.path,
+	})
+	return nil
+}
+
+// discard removes the temp file, leaving path as it was
+func (o *outputFile) discard() {
+	if o.tmp == nil {
+		return
+	}
+	_ = o.tmp.Close()
+	_ = os.Remove(o.tmp.Name())
+	LogWarn("Output file not written", map[string]interface{}{
+		"path": o.path,
+	})
+}
+
+// outputWriter returns where command output goes: the --output-file if
+// set, otherwise stdout
+func outputWriter() io.Writer {
+	if outputDest != nil {
+		return outputDest
+	}
+	return os.Stdout
+}
+
+// finishOutputFile writes the --output-file if the command succeeded and
+// discards it otherwise, including when it was cancelled or timed out
+// part way through. It returns the command's error, or the error writing
+// the file.
+func finishOutputFile(err error) error {
+	if outputDest == nil {
+		return err
+	}
+	dest := outputDest
+	outputDest = nil
+
+	if _, _, cancelled := cancellation(); err != nil || cancelled {
+		dest.discard()
+		return err
+	}
+	return dest.commit()
+}
+
+// validateOutputPath checks that path is within the user's home directory,
+// the temp directory or one of the output.directories in the config file
+func validateOutputPath(path string) error {
+	if path == "" {
+		return fmt.Errorf("file path cannot be empty")
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve absolute path: %w", err)
+	}
+
+	dirs := []string{"/tmp", os.TempDir()}
+	if usr, err := user.Current(); err == nil {
+		dirs = append(dirs, usr.HomeDir)
+	}
+	for _, dir := range viper.GetStringSlice("output.directories") {
+		dirs = append(dirs, expandHome(dir))
+	}
+
+	for _, dir := range dirs {
+		absDir, err := filepath.Abs(dir)
+		if err != nil || dir == "" {
+			continue
+		}
+		rel, err := filepath.Rel(absDir, absPath)
+		if err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%s is not within the home directory, the temp directory or an output.directories entry in the config file", path)
+}
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// withOutputFile directs command output to path for one test
+func withOutputFile(t *testing.T, path string) {
+	t.Helper()
+	dest, err := newOutputFile(path)
+	if err != nil {
+		t.Fatalf("newOutputFile(%q) error = %v", path, err)
+	}
+	t.Cleanup(func() { outputDest = nil })
+	outputDest = dest
+}
+
+func TestOutputFileCommit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.csv")
+	if err := os.WriteFile(path, []byte("old\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	withOutputFile(t, path)
+
+	if _, err := io.WriteString(outputWriter(), "new\n"); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "old\n" {
+		t.Errorf("file replaced before the command finished: %q", got)
+	}
+
+	if err := finishOutputFile(nil); err != nil {
+		t.Fatalf("finishOutputFile() error = %v", err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "new\n" {
+		t.Errorf("file = %q, want %q", got, "new\n")
+	}
+	if info, err := os.Stat(path); err == nil && info.Mode().Perm() != 0600 {
+		t.Errorf("file mode = %v, want 0600", info.Mode().Perm())
+	}
+	assertNoTempFiles(t, filepath.Dir(path))
+	if outputWriter() != os.Stdout {
+		t.Error("outputWriter() should return stdout after the file is written")
+	}
+}
+
+func TestOutputFileDiscardOnError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.json")
+	if err := os.WriteFile(path, []byte("old\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	withOutputFile(t, path)
+
+	if _, err := io.WriteString(outputWriter(), "partial"); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	cmdErr := errors.New("API failed")
+	if err := finishOutputFile(cmdErr); err != cmdErr {
+		t.Errorf("finishOutputFile() = %v, want the command's error", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "old\n" {
+		t.Errorf("file = %q, want it left unchanged", got)
+	}
+	assertNoTempFiles(t, filepath.Dir(path))
+}
+
+func TestOutputFileNoOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.csv")
+	withOutputFile(t, path)
+
+	if err := finishOutputFile(nil); err != nil {
+		t.Fatalf("finishOutputFile() error = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("file should not be created without output, stat error = %v", err)
+	}
+}
+
+func TestOutputFileGzip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.ndjson.gz")
+	withOutputFile(t, path)
+
+	if err := FormatOutput([]map[string]string{{"primaryEmail": "a@example.com"}}, nil); err != nil {
+		t.Fatalf("FormatOutput() error = %v", err)
+	}
+	if err := finishOutputFile(nil); err != nil {
+		t.Fatalf("finishOutputFile() error = %v", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("output is not gzip-compressed: %v", err)
+	}
+	got, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(got), "a@example.com") {
+		t.Errorf("decompressed output = %q, want it to contain the user", got)
+	}
+}
+
+func TestValidateOutputPath(t *testing.T) {
+	original := viper.Get("output.directories")
+	t.Cleanup(func() { viper.Set("output.directories", original) })
+
+	allowed := "/srv/gac-reports"
+	viper.Set("output.directories", []string{allowed})
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr bool
+	}{
+		{"temp directory", filepath.Join(os.TempDir(), "out.csv"), false},
+		{"configured directory", filepath.Join(allowed, "out.csv"), false},
+		{"configured subdirectory", filepath.Join(allowed, "daily", "out.csv"), false},
+		{"configured directory itself", allowed, true},
+		{"sibling of configured directory", "/srv/gac-reports-other/out.csv", true},
+		{"traversal out of configured directory", allowed + "/../etc/out.csv", true},
+		{"system directory", "/etc/out.csv", true},
+		{"empty", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateOutputPath(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateOutputPath(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// assertNoTempFiles fails if an output temp file was left in dir
+func assertNoTempFiles(t *testing.T, dir string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if strings.Contains(e.Name(), ".tmp-") {
+			t.Errorf("temp file %s left behind", e.Name())
+		}
+	}
+}
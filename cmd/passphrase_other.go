@@ -0,0 +1,11 @@
+//go:build !unix
+
+package cmd
+
+import "errors"
+
+// promptPassphrase is unsupported where echo cannot be disabled; use
+// GAC_ENCRYPTION_KEY or a key file instead
+func promptPassphrase(prompt string) (string, error) {
+	return "", errors.New("passphrase prompts are not supported on this platform")
+}
@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/acockrell/google-admin-client/pkg/gac"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultCustomerID addresses the customer of the authenticated admin
+const defaultCustomerID = "my_customer"
+
+var (
+	profileFlag string
+
+	// profileLoadErr records a problem selecting the active profile so that
+	// commands can refuse to run against the wrong tenant
+	profileLoadErr error
+
+	// Viper splits keys on dots, so profile names cannot contain them
+	profileNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+)
+
+// profileSetting maps a key inside a profile to the global configuration
+// key it overrides, along with the flag and env vars that take precedence
+type profileSetting struct {
+	key  string
+	flag string
+	envs []string
+}
+
+// profileSettings lists every per-profile setting
+var profileSettings = map[string]profileSetting{
+	"domain":          {key: "domain", flag: "domain", envs: []string{"GAC_DOMAIN", "GOOGLE_ADMIN_DOMAIN"}},
+	"client-secret":   {key: "client-secret", flag: "client-secret", envs: []string{"GAC_CLIENT_SECRET", "GOOGLE_ADMIN_CLIENT_SECRET"}},
+	"cache-file":      {key: "cache-file", flag: "cache-file", envs: []string{"GAC_CACHE_FILE", "GOOGLE_ADMIN_CACHE_FILE"}},
+	"impersonate":     {key: "impersonate", flag: "impersonate", envs: []string{"GAC_IMPERSONATE", "GOOGLE_ADMIN_IMPERSONATE"}},
+	"customer-id":     {key: "customer-id", flag: "customer-id", envs: []string{"GAC_CUSTOMER_ID", "GOOGLE_ADMIN_CUSTOMER_ID"}},
+	"cache-directory": {key: "cache.directory"},
+}
+
+// activeProfile returns the selected profile name: --profile, then
+// GAC_PROFILE, then default-profile from the config file
+func activeProfile() string {
+	if name := viper.GetString("profile"); name != "" {
+		return strings.ToLower(name)
+	}
+	return strings.ToLower(viper.GetString("default-profile"))
+}
+
+// listProfiles returns the configured profile names in sorted order
+func listProfiles() []string {
+	profiles := viper.GetStringMap("profiles")
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// getProfile returns the raw settings of a named profile
+func getProfile(name string) (*viper.Viper, error) {
+	if !profileNameRegex.MatchString(name) {
+		return nil, invalidInputf("invalid profile name %q (only alphanumeric, hyphens, and underscores allowed)", name)
+	}
+	sub := viper.Sub("profiles." + strings.ToLower(name))
+	if sub == nil {
+		return nil, withKind(fmt.Errorf("profile %q not found in config file (available: %s)", name, strings.Join(listProfiles(), ", ")), gac.ErrNotFound)
+	}
+	return sub, nil
+}
+
+// applyProfile copies the active profile's settings over the top-level
+// configuration. Explicit flags and environment variables still win.
+func applyProfile() error {
+	name := activeProfile()
+	if name == "" {
+		return nil
+	}
+
+	profile, err := getProfile(name)
+	if err != nil {
+		return err
+	}
+
+	for key, setting := range profileSettings {
+		if !profile.IsSet(key) || settingOverridden(setting) {
+			continue
+		}
+		viper.Set(setting.key, profile.Get(key))
+	}
+
+	// Keep each profile's token and cached data apart unless the profile
+	// names its own locations
+	if !profile.IsSet("cache-file") && !settingOverridden(profileSettings["cache-file"]) {
+		viper.Set("cache-file", "")
+	}
+	if !profile.IsSet("cache-directory") {
+		base := viper.GetString("cache.directory")
+		if base == "" {
+			base = "~/.cache/gac"
+		}
+		viper.Set("cache.directory", filepath.Join(base, name))
+	}
+
+	LogDebug("Using configuration profile", map[string]interface{}{
+		"profile": name,
+	})
+	return nil
+}
+
+// settingOverridden reports whether a flag or env var explicitly sets the
+// value, in which case the profile must not replace it
+func settingOverridden(setting profileSetting) bool {
+	if setting.flag != "" {
+		if f := rootCmd.PersistentFlags().Lookup(setting.flag); f != nil && f.Changed {
+			return true
+		}
+	}
+	for _, env := range setting.envs {
+		if os.Getenv(env) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// getCustomerID returns the configured Workspace customer ID, defaulting
+// to the authenticated admin's own customer
+func getCustomerID() string {
+	if id := viper.GetString("customer-id"); id != "" {
+		return id
+	}
+	return defaultCustomerID
+}
+
+// setDefaultProfile records the default profile in the config file,
+// editing the YAML document in place so comments are preserved
+func setDefaultProfile(configFile, name string) error {
+	if configFile == "" {
+		return errors.New("no config file in use (create $HOME/.google-admin.yaml or pass --config)")
+	}
+
+	// #nosec G304 - Path comes from viper's resolved config file
+	b, err := os.ReadFile(configFile)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		doc.Kind = yaml.DocumentNode
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return errors.New("config file is not a YAML mapping")
+	}
+
+	updated := false
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "default-profile" {
+			root.Content[i+1].Kind = yaml.ScalarNode
+			root.Content[i+1].Tag = "!!str"
+			root.Content[i+1].Value = name
+			updated = true
+			break
+		}
+	}
+	if !updated {
+		root.Content = append(root.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "default-profile"},
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: name},
+		)
+	}
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to encode config file: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to encode config file: %w", err)
+	}
+
+	info, err := os.Stat(configFile)
+	if err != nil {
+		return fmt.Errorf("failed to stat config file: %w", err)
+	}
+	return writeFileAtomic(configFile, out.Bytes(), info.Mode().Perm())
+}
+
+// isProfilesCommand reports whether cmd is part of "gac config profiles"
+func isProfilesCommand(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c == configProfilesCmd {
+			return true
+		}
+	}
+	return false
+}
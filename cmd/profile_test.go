@@ -0,0 +1,252 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// setupProfiles installs a profiles section in viper and restores every key
+// touched by applyProfile when the test finishes
+func setupProfiles(t *testing.T) {
+	t.Helper()
+
+	keys := []string{"profiles", "profile", "default-profile", "domain", "customer-id", "cache-file", "cache.directory", "client-secret", "impersonate"}
+	saved := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		saved[key] = viper.Get(key)
+	}
+	originalCacheFile := cacheFile
+	t.Cleanup(func() {
+		for _, key := range keys {
+			viper.Set(key, saved[key])
+		}
+		cacheFile = originalCacheFile
+	})
+
+	for _, env := range []string{"GAC_PROFILE", "GAC_DOMAIN", "GOOGLE_ADMIN_DOMAIN", "GAC_CUSTOMER_ID", "GOOGLE_ADMIN_CUSTOMER_ID", "GAC_CACHE_FILE", "GOOGLE_ADMIN_CACHE_FILE"} {
+		t.Setenv(env, "")
+	}
+
+	viper.Set("profiles", map[string]interface{}{
+		"production": map[string]interface{}{
+			"domain": "example.com",
+		},
+		"sandbox": map[string]interface{}{
+			"domain":          "sandbox.example.com",
+			"customer-id":     "C0123abcd",
+			"cache-directory": "/tmp/gac-sandbox-cache",
+		},
+	})
+	viper.Set("profile", "")
+	viper.Set("default-profile", "")
+	viper.Set("domain", "top-level.example.com")
+	viper.Set("customer-id", "")
+	viper.Set("cache-file", "")
+	viper.Set("cache.directory", "")
+	cacheFile = ""
+}
+
+func TestApplyProfile(t *testing.T) {
+	tests := []struct {
+		name           string
+		profile        string
+		defaultProfile string
+		wantDomain     string
+		wantCustomerID string
+		wantCacheDir   string
+		wantErr        bool
+	}{
+		{
+			name:           "no profile keeps top-level settings",
+			wantDomain:     "top-level.example.com",
+			wantCustomerID: defaultCustomerID,
+			wantCacheDir:   "",
+		},
+		{
+			name:           "profile flag",
+			profile:        "sandbox",
+			wantDomain:     "sandbox.example.com",
+			wantCustomerID: "C0123abcd",
+			wantCacheDir:   "/tmp/gac-sandbox-cache",
+		},
+		{
+			name:           "default profile from config",
+			defaultProfile: "production",
+			wantDomain:     "example.com",
+			wantCustomerID: defaultCustomerID,
+			wantCacheDir:   filepath.Join("~/.cache/gac", "production"),
+		},
+		{
+			name:           "profile flag wins over default profile",
+			profile:        "Sandbox",
+			defaultProfile: "production",
+			wantDomain:     "sandbox.example.com",
+			wantCustomerID: "C0123abcd",
+			wantCacheDir:   "/tmp/gac-sandbox-cache",
+		},
+		{
+			name:    "unknown profile",
+			profile: "staging",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupProfiles(t)
+			viper.Set("profile", tt.profile)
+			viper.Set("default-profile", tt.defaultProfile)
+
+			err := applyProfile()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyProfile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if got := getDomain(); got != tt.wantDomain {
+				t.Errorf("getDomain() = %q, want %q", got, tt.wantDomain)
+			}
+			if got := getCustomerID(); got != tt.wantCustomerID {
+				t.Errorf("getCustomerID() = %q, want %q", got, tt.wantCustomerID)
+			}
+			if got := viper.GetString("cache.directory"); got != tt.wantCacheDir {
+				t.Errorf("cache.directory = %q, want %q", got, tt.wantCacheDir)
+			}
+		})
+	}
+}
+
+func TestApplyProfileEnvOverride(t *testing.T) {
+	setupProfiles(t)
+	t.Setenv("GAC_DOMAIN", "env.example.com")
+	viper.Set("domain", nil)
+	viper.Set("profile", "sandbox")
+
+	if err := applyProfile(); err != nil {
+		t.Fatalf("applyProfile() error = %v", err)
+	}
+	if got := getDomain(); got != "env.example.com" {
+		t.Errorf("getDomain() = %q, environment should override the profile", got)
+	}
+	if got := getCustomerID(); got != "C0123abcd" {
+		t.Errorf("getCustomerID() = %q, want profile value", got)
+	}
+}
+
+func TestTokenCacheFilePerProfile(t *testing.T) {
+	tests := []struct {
+		profile  string
+		expected string
+	}{
+		{profile: "", expected: "gac.json"},
+		{profile: "production", expected: "gac-production.json"},
+		{profile: "sandbox", expected: "gac-sandbox.json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			setupProfiles(t)
+			viper.Set("profile", tt.profile)
+			if err := applyProfile(); err != nil {
+				t.Fatalf("applyProfile() error = %v", err)
+			}
+
+			file, err := tokenCacheFile()
+			if err != nil {
+				t.Fatalf("tokenCacheFile() error = %v", err)
+			}
+			if got := filepath.Base(file); got != tt.expected {
+				t.Errorf("tokenCacheFile() = %q, want file name %q", file, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGetProfileInvalidName(t *testing.T) {
+	setupProfiles(t)
+
+	for _, name := range []string{"../etc", "prod.eu"} {
+		if _, err := getProfile(name); err == nil || !strings.Contains(err.Error(), "invalid profile name") {
+			t.Errorf("getProfile(%q) error = %v, want invalid profile name", name, err)
+		}
+	}
+	if _, err := getProfile("missing"); err == nil || !strings.Contains(err.Error(), "production, sandbox") {
+		t.Errorf("getProfile() error = %v, want list of available profiles", err)
+	}
+}
+
+func TestSetDefaultProfile(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, ".google-admin.yaml")
+	original := `# Shared settings
+domain: example.com # primary tenant
+profiles:
+  sandbox:
+    domain: sandbox.example.com
+`
+	if err := os.WriteFile(configFile, []byte(original), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	if err := setDefaultProfile(configFile, "sandbox"); err != nil {
+		t.Fatalf("setDefaultProfile() error = %v", err)
+	}
+	if err := setDefaultProfile(configFile, "production"); err != nil {
+		t.Fatalf("setDefaultProfile() second call error = %v", err)
+	}
+
+	b, err := os.ReadFile(configFile)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	content := string(b)
+
+	if strings.Count(content, "default-profile:") != 1 {
+		t.Errorf("expected exactly one default-profile key, got:\n%s", content)
+	}
+	if !strings.Contains(content, "default-profile: production") {
+		t.Errorf("default-profile not updated, got:\n%s", content)
+	}
+	for _, want := range []string{"# Shared settings", "# primary tenant", "    domain: sandbox.example.com"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("config lost %q, got:\n%s", want, content)
+		}
+	}
+
+	info, err := os.Stat(configFile)
+	if err != nil {
+		t.Fatalf("Failed to stat config: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("config permissions = %v, want 0600", info.Mode().Perm())
+	}
+
+	if err := setDefaultProfile("", "production"); err == nil {
+		t.Error("expected error when no config file is in use")
+	}
+}
+
+func TestProfileSummaryColumns(t *testing.T) {
+	headers := []string{"Name", "Domain", "CustomerID", "Active"}
+	row, err := convertItemToRow(profileSummary{
+		Name:       "sandbox",
+		Domain:     "sandbox.example.com",
+		CustomerID: "C0123abcd",
+		Active:     true,
+	}, headers)
+	if err != nil {
+		t.Fatalf("convertItemToRow() error = %v", err)
+	}
+	want := []string{"sandbox", "sandbox.example.com", "C0123abcd", "true"}
+	for i := range want {
+		if row[i] != want[i] {
+			t.Errorf("column %s = %q, want %q", headers[i], row[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// rateLimitedAPIs lists the APIs with their own limiter and the default
+// sustained rate (requests per second) and burst for each. The defaults stay
+// well under Google's published per-user quotas.
+var rateLimitedAPIs = map[string]rateLimit{
+	"admin":          {Rate: 20, Burst: 20},
+	"reports":        {Rate: 10, Burst: 10},
+	"calendar":       {Rate: 10, Burst: 10},
+	"groupssettings": {Rate: 5, Burst: 5},
+	"datatransfer":   {Rate: 2, Burst: 2},
+}
+
+// rateLimit is the configuration of a single API's token bucket
+type rateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+var (
+	// apiLimiters are shared by every client in the process so concurrent
+	// goroutines and services draw from the same quota
+	apiLimiters   = map[string]*tokenBucket{}
+	apiLimitersMu sync.Mutex
+)
+
+// getRateLimit returns the configured limit for an API; a rate of zero or
+// less disables limiting for it
+func getRateLimit(api string) rateLimit {
+	limit := rateLimitedAPIs[api]
+	if viper.IsSet("ratelimit." + api + ".rate") {
+		limit.Rate = viper.GetFloat64("ratelimit." + api + ".rate")
+	}
+	if viper.IsSet("ratelimit." + api + ".burst") {
+		limit.Burst = viper.GetInt("ratelimit." + api + ".burst")
+	}
+	if limit.Burst < 1 {
+		limit.Burst = 1
+	}
+	return limit
+}
+
+// limiterFor returns the shared token bucket for an API, or nil if the API
+// is not limited
+func limiterFor(api string) *tokenBucket {
+	if _, ok := rateLimitedAPIs[api]; !ok || !viper.GetBool("ratelimit.enabled") {
+		return nil
+	}
+
+	apiLimitersMu.Lock()
+	defer apiLimitersMu.Unlock()
+
+	if b, ok := apiLimiters[api]; ok {
+		return b
+	}
+	limit := getRateLimit(api)
+	if limit.Rate <= 0 {
+		apiLimiters[api] = nil
+		return nil
+	}
+	b := newTokenBucket(limit.Rate, limit.Burst)
+	apiLimiters[api] = b
+	return b
+}
+
+// tokenBucket is a token-bucket rate limiter. Callers reserve a token and
+// wait until it becomes available, so waiters are served in order.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+
+	// now returns the current time; replaced in tests
+	now func() time.Time
+}
+
+// newTokenBucket returns a full bucket refilling at rate tokens per second
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+		now:    time.Now,
+	}
+}
+
+// reserve takes a token and returns how long the caller must wait before
+// using it
+func (b *tokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	b.last = now
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// rateLimitTransport delays requests so each API stays under its limit
+type rateLimitTransport struct {
+	base http.RoundTripper
+}
+
+// newRateLimitTransport wraps base with per-API rate limiting
+func newRateLimitTransport(base http.RoundTripper) *rateLimitTransport {
+	return &rateLimitTransport{base: base}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	api := apiForRequest(req)
+	if b := limiterFor(api); b != nil {
+		if delay := b.reserve(); delay > 0 {
+			LogDebug("Rate limit delay", map[string]interface{}{
+				"api":   api,
+				"delay": delay.String(),
+			})
+			if err := sleepContext(req.Context(), delay); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return t.base.RoundTrip(req)
+}
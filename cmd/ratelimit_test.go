@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestTokenBucketReserve(t *testing.T) {
+	now := time.Now()
+	b := newTokenBucket(2, 3)
+	b.now = func() time.Time { return now }
+	b.last = now
+
+	// The burst is available immediately
+	for i := 0; i < 3; i++ {
+		if d := b.reserve(); d != 0 {
+			t.Fatalf("reserve() #%d = %v, want 0 within burst", i+1, d)
+		}
+	}
+
+	// Further requests queue behind each other at 2 per second
+	if d := b.reserve(); d != 500*time.Millisecond {
+		t.Errorf("reserve() = %v, want 500ms", d)
+	}
+	if d := b.reserve(); d != time.Second {
+		t.Errorf("reserve() = %v, want 1s", d)
+	}
+
+	// After enough idle time the bucket refills, but never beyond the burst
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		if d := b.reserve(); d != 0 {
+			t.Fatalf("reserve() after refill #%d = %v, want 0", i+1, d)
+		}
+	}
+	if d := b.reserve(); d == 0 {
+		t.Error("reserve() beyond burst should wait")
+	}
+}
+
+func TestGetRateLimit(t *testing.T) {
+	defer func() {
+		viper.Set("ratelimit.admin.rate", nil)
+		viper.Set("ratelimit.admin.burst", nil)
+	}()
+
+	if got := getRateLimit("admin"); got != rateLimitedAPIs["admin"] {
+		t.Errorf("getRateLimit(admin) = %+v, want default %+v", got, rateLimitedAPIs["admin"])
+	}
+
+	viper.Set("ratelimit.admin.rate", 2.5)
+	viper.Set("ratelimit.admin.burst", 0)
+	want := rateLimit{Rate: 2.5, Burst: 1}
+	if got := getRateLimit("admin"); got != want {
+		t.Errorf("getRateLimit(admin) = %+v, want %+v", got, want)
+	}
+}
+
+func TestRateLimitTransport(t *testing.T) {
+	originalEnabled := viper.Get("ratelimit.enabled")
+	defer func() {
+		viper.Set("ratelimit.enabled", originalEnabled)
+		apiLimitersMu.Lock()
+		apiLimiters = map[string]*tokenBucket{}
+		apiLimitersMu.Unlock()
+	}()
+	viper.Set("ratelimit.enabled", true)
+
+	// Exhaust a slow bucket for the admin API only
+	apiLimitersMu.Lock()
+	apiLimiters = map[string]*tokenBucket{
+		"admin": newTokenBucket(0.001, 1),
+	}
+	apiLimitersMu.Unlock()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	client := &http.Client{Transport: newRateLimitTransport(http.DefaultTransport)}
+
+	get := func(ctx context.Context, path string) error {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+path, nil)
+		resp, err := client.Do(req)
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+		return err
+	}
+
+	if err := get(context.Background(), "/admin/directory/v1/users"); err != nil {
+		t.Fatalf("first admin request error = %v", err)
+	}
+
+	// The second admin request has to wait and gives up with the context
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := get(ctx, "/admin/directory/v1/users"); err == nil {
+		t.Error("second admin request should have been held by the limiter")
+	}
+
+	// Other APIs have their own buckets
+	if err := get(context.Background(), "/calendar/v3/calendars/primary/events"); err != nil {
+		t.Errorf("calendar request error = %v", err)
+	}
+}
@@ -0,0 +1,456 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	recordDir string
+	replayDir string
+)
+
+// recordedHeaders are the only headers kept in fixtures; everything else
+// (Authorization, cookies, Google tracing headers) is dropped
+var recordedHeaders = []string{"Content-Type", "Retry-After"}
+
+// secretKeys are redacted wherever they appear in bodies or query strings
+var secretKeys = map[string]bool{
+	"access_token":  true,
+	"refresh_token": true,
+	"id_token":      true,
+	"client_secret": true,
+	"assertion":     true,
+	"password":      true,
+}
+
+// paramSecretKeys are additionally redacted in query strings and form
+// bodies, where they carry OAuth2 codes and API keys. They are left alone in
+// JSON so fields such as an error's "code" survive.
+var paramSecretKeys = map[string]bool{
+	"code":          true,
+	"code_verifier": true,
+	"key":           true,
+}
+
+// isSecretParam reports whether a query or form parameter must be redacted
+func isSecretParam(name string) bool {
+	return secretKeys[name] || paramSecretKeys[name]
+}
+
+// piiKeys are JSON fields whose values identify a person and are replaced
+// with a placeholder in fixtures
+var piiKeys = map[string]bool{
+	"fullName":          true,
+	"givenName":         true,
+	"familyName":        true,
+	"displayName":       true,
+	"phones":            true,
+	"addresses":         true,
+	"recoveryPhone":     true,
+	"thumbnailPhotoUrl": true,
+	"ipAddress":         true,
+	"externalIds":       true,
+}
+
+const redacted = "REDACTED"
+
+var (
+	embeddedEmailRegex = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+	// pseudonymRegex matches local parts gac has already pseudonymized, so
+	// sanitizing is idempotent and replayed commands can use them as input
+	pseudonymRegex = regexp.MustCompile(`^user-[0-9a-f]{8}$`)
+
+	unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9.\-]+`)
+)
+
+// fixture is one recorded HTTP exchange
+type fixture struct {
+	Request  fixtureRequest  `json:"request"`
+	Response fixtureResponse `json:"response"`
+}
+
+type fixtureRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+type fixtureResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// pseudonymizeEmail replaces the local part of an address with a stable
+// hash, keeping the domain so domain-based logic behaves the same on replay
+func pseudonymizeEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	local, domain := email[:at], email[at+1:]
+	if pseudonymRegex.MatchString(local) {
+		return email
+	}
+	sum := sha256.Sum256([]byte(strings.ToLower(local)))
+	return "user-" + hex.EncodeToString(sum[:4]) + "@" + domain
+}
+
+// sanitizeString pseudonymizes every email address in s
+func sanitizeString(s string) string {
+	return embeddedEmailRegex.ReplaceAllStringFunc(s, pseudonymizeEmail)
+}
+
+// sanitizeURL pseudonymizes emails in the path and query and redacts
+// secrets. The result is canonical (sorted query) so it can be matched.
+func sanitizeURL(u *url.URL) string {
+	segments := strings.Split(u.EscapedPath(), "/")
+	for i, seg := range segments {
+		if unescaped, err := url.PathUnescape(seg); err == nil {
+			segments[i] = url.PathEscape(sanitizeString(unescaped))
+		}
+	}
+
+	query := u.Query()
+	for k, values := range query {
+		for i, v := range values {
+			if isSecretParam(k) {
+				values[i] = redacted
+			} else {
+				values[i] = sanitizeString(v)
+			}
+		}
+	}
+
+	path := strings.Join(segments, "/")
+	if encoded := query.Encode(); encoded != "" {
+		return path + "?" + encoded
+	}
+	return path
+}
+
+// sanitizeBody redacts secrets and PII from a JSON or form-encoded body
+func sanitizeBody(body []byte, contentType string) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err == nil {
+		b, err := json.Marshal(sanitizeJSON(v))
+		if err == nil {
+			return string(b)
+		}
+	}
+
+	if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		if form, err := url.ParseQuery(string(body)); err == nil {
+			for k := range form {
+				if isSecretParam(k) {
+					form.Set(k, redacted)
+				}
+			}
+			return sanitizeString(form.Encode())
+		}
+	}
+
+	return sanitizeString(string(body))
+}
+
+// sanitizeJSON walks a decoded JSON value redacting secrets and PII
+func sanitizeJSON(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, child := range val {
+			switch {
+			case secretKeys[k]:
+				val[k] = redacted
+			case piiKeys[k]:
+				if _, ok := child.(string); ok {
+					val[k] = redacted
+				} else {
+					delete(val, k)
+				}
+			default:
+				val[k] = sanitizeJSON(child)
+			}
+		}
+		return val
+	case []interface{}:
+		for i, child := range val {
+			val[i] = sanitizeJSON(child)
+		}
+		return val
+	case string:
+		return sanitizeString(val)
+	}
+	return v
+}
+
+// filterHeaders keeps only the headers safe and useful to record
+func filterHeaders(h http.Header) http.Header {
+	out := http.Header{}
+	for _, name := range recordedHeaders {
+		if v := h.Get(name); v != "" {
+			out.Set(name, v)
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// fixtureName builds a readable, sortable file name for an exchange
+func fixtureName(seq int, method string, u *url.URL) string {
+	path := strings.Trim(u.Path, "/")
+	path = unsafeNameChars.ReplaceAllString(sanitizeString(path), "_")
+	if len(path) > 80 {
+		path = path[:80]
+	}
+	return fmt.Sprintf("%04d-%s-%s.json", seq, method, path)
+}
+
+// recorder numbers and writes fixture files; it is shared by every client
+// in the process so fixtures from different services don't collide
+type recorder struct {
+	dir string
+
+	mu  sync.Mutex
+	seq int
+}
+
+// newRecorder records into dir, numbering fixtures after any already present
+func newRecorder(dir string) (*recorder, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create record directory: %w", err)
+	}
+	existing, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	return &recorder{dir: dir, seq: len(existing)}, nil
+}
+
+// save writes a fixture to the next numbered file
+func (r *recorder) save(req *http.Request, f fixture) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(f); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.seq++
+	name := fixtureName(r.seq, req.Method, req.URL)
+	r.mu.Unlock()
+
+	LogDebug("Recorded HTTP exchange", map[string]interface{}{
+		"file": name,
+	})
+	return writeFileAtomic(filepath.Join(r.dir, name), buf.Bytes(), 0600)
+}
+
+// recordingTransport saves every exchange as a sanitized fixture
+type recordingTransport struct {
+	base http.RoundTripper
+	rec  *recorder
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		b, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		reqBody = b
+		req.Body = io.NopCloser(bytes.NewReader(b))
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	f := fixture{
+		Request: fixtureRequest{
+			Method: req.Method,
+			URL:    sanitizeURL(req.URL),
+			Header: filterHeaders(req.Header),
+			Body:   sanitizeBody(reqBody, req.Header.Get("Content-Type")),
+		},
+		Response: fixtureResponse{
+			StatusCode: resp.StatusCode,
+			Header:     filterHeaders(resp.Header),
+			Body:       sanitizeBody(respBody, resp.Header.Get("Content-Type")),
+		},
+	}
+	if err := t.rec.save(req, f); err != nil {
+		LogWarn("Failed to record HTTP exchange", map[string]interface{}{
+			"url":   f.Request.URL,
+			"error": err.Error(),
+		})
+	}
+	return resp, nil
+}
+
+// replayTransport answers requests from recorded fixtures without touching
+// the network
+type replayTransport struct {
+	mu       sync.Mutex
+	fixtures []fixture
+	used     []bool
+}
+
+// newReplayTransport loads every fixture in dir in recorded order
+func newReplayTransport(dir string) (*replayTransport, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no recorded fixtures found in %s", dir)
+	}
+	sort.Strings(files)
+
+	t := &replayTransport{}
+	for _, file := range files {
+		// #nosec G304 - Fixture files are listed from the user-supplied replay directory
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fixture: %w", err)
+		}
+		var f fixture
+		if err := json.Unmarshal(b, &f); err != nil {
+			return nil, fmt.Errorf("invalid fixture %s: %w", filepath.Base(file), err)
+		}
+		t.fixtures = append(t.fixtures, f)
+	}
+	t.used = make([]bool, len(t.fixtures))
+	return t, nil
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+
+	f, err := t.match(req)
+	if err != nil {
+		return nil, err
+	}
+
+	LogAPICall(apiForRequest(req), req.Method+" "+req.URL.Path, map[string]interface{}{
+		"replayed": true,
+	})
+
+	header := f.Response.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.Response.StatusCode, http.StatusText(f.Response.StatusCode)),
+		StatusCode:    f.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(f.Response.Body)),
+		ContentLength: int64(len(f.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+// match returns the first unused fixture for the request, preferring an
+// exact URL match and falling back to the same method and path
+func (t *replayTransport) match(req *http.Request) (fixture, error) {
+	want := sanitizeURL(req.URL)
+	wantPath := strings.SplitN(want, "?", 2)[0]
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	candidate := -1
+	for i, f := range t.fixtures {
+		if t.used[i] || f.Request.Method != req.Method {
+			continue
+		}
+		if f.Request.URL == want {
+			candidate = i
+			break
+		}
+		if candidate == -1 && strings.SplitN(f.Request.URL, "?", 2)[0] == wantPath {
+			candidate = i
+		}
+	}
+	if candidate == -1 {
+		return fixture{}, errors.New("no recorded response for " + req.Method + " " + want)
+	}
+	t.used[candidate] = true
+	return t.fixtures[candidate], nil
+}
+
+var (
+	replayOnce      sync.Once
+	sharedReplay    *replayTransport
+	sharedReplayErr error
+
+	recordOnce      sync.Once
+	sharedRecorder  *recorder
+	sharedRecordErr error
+)
+
+// newReplayClient returns an http.Client that serves --replay fixtures.
+// All clients in a process share one transport so each fixture is used once.
+func newReplayClient() (*http.Client, error) {
+	replayOnce.Do(func() {
+		sharedReplay, sharedReplayErr = newReplayTransport(replayDir)
+	})
+	if sharedReplayErr != nil {
+		return nil, sharedReplayErr
+	}
+	LogDebug("Replaying recorded HTTP exchanges", map[string]interface{}{
+		"dir": replayDir,
+	})
+	return &http.Client{Transport: sharedReplay}, nil
+}
+
+// withRecording wraps base so exchanges are saved to --record
+func withRecording(base http.RoundTripper) (http.RoundTripper, error) {
+	recordOnce.Do(func() {
+		sharedRecorder, sharedRecordErr = newRecorder(recordDir)
+	})
+	if sharedRecordErr != nil {
+		return nil, sharedRecordErr
+	}
+	return &recordingTransport{base: base, rec: sharedRecorder}, nil
+}
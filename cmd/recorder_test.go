@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	admin "google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/option"
+)
+
+func TestPseudonymizeEmail(t *testing.T) {
+	got := pseudonymizeEmail("jane.doe@example.com")
+	if !strings.HasSuffix(got, "@example.com") || !strings.HasPrefix(got, "user-") {
+		t.Errorf("pseudonymizeEmail() = %q, want user-<hash>@example.com", got)
+	}
+	if again := pseudonymizeEmail("Jane.Doe@example.com"); again != got {
+		t.Errorf("pseudonymizeEmail() is not case-insensitive: %q vs %q", again, got)
+	}
+	if idempotent := pseudonymizeEmail(got); idempotent != got {
+		t.Errorf("pseudonymizeEmail(%q) = %q, want unchanged", got, idempotent)
+	}
+}
+
+func TestSanitizeURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		want    string
+		notWant string
+	}{
+		{
+			name: "path email",
+			url:  "https://admin.googleapis.com/admin/directory/v1/users/jane%40example.com?alt=json",
+			want: "/admin/directory/v1/users/" + url.PathEscape(pseudonymizeEmail("jane@example.com")) + "?alt=json",
+		},
+		{
+			name:    "query secret",
+			url:     "https://oauth2.googleapis.com/token?access_token=abc123&alt=json",
+			want:    "access_token=REDACTED",
+			notWant: "abc123",
+		},
+		{
+			name:    "query email",
+			url:     "https://admin.googleapis.com/admin/directory/v1/groups?userKey=jane@example.com",
+			notWant: "jane",
+		},
+		{
+			name: "canonical query order",
+			url:  "https://admin.googleapis.com/x?b=2&a=1",
+			want: "/x?a=1&b=2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.Parse(tt.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := sanitizeURL(u)
+			if tt.want != "" && !strings.Contains(got, tt.want) {
+				t.Errorf("sanitizeURL() = %q, want it to contain %q", got, tt.want)
+			}
+			if tt.notWant != "" && strings.Contains(got, tt.notWant) {
+				t.Errorf("sanitizeURL() = %q, must not contain %q", got, tt.notWant)
+			}
+		})
+	}
+}
+
+func TestSanitizeBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		notWant     []string
+		want        []string
+	}{
+		{
+			name:    "token response",
+			body:    `{"access_token":"ya29.secret","refresh_token":"1//secret","expires_in":3599}`,
+			notWant: []string{"ya29.secret", "1//secret"},
+			want:    []string{`"expires_in":3599`},
+		},
+		{
+			name:    "user PII",
+			body:    `{"primaryEmail":"jane@example.com","name":{"fullName":"Jane Doe","givenName":"Jane"},"phones":[{"value":"555-0100"}],"suspended":false}`,
+			notWant: []string{"jane@", "Jane", "555-0100"},
+			want:    []string{"@example.com", `"suspended":false`},
+		},
+		{
+			name:    "google error keeps code",
+			body:    `{"error":{"code":404,"message":"Resource Not Found: userKey"}}`,
+			want:    []string{`"code":404`},
+			notWant: []string{"REDACTED"},
+		},
+		{
+			name:        "form body",
+			body:        "grant_type=refresh_token&refresh_token=1%2F%2Fxyz789&client_secret=shh",
+			contentType: "application/x-www-form-urlencoded",
+			notWant:     []string{"xyz789", "shh"},
+			want:        []string{"grant_type=refresh_token"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sanitizeBody([]byte(tt.body), tt.contentType)
+			for _, s := range tt.want {
+				if !strings.Contains(got, s) {
+					t.Errorf("sanitizeBody() = %s, want it to contain %q", got, s)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(got, s) {
+					t.Errorf("sanitizeBody() = %s, must not contain %q", got, s)
+				}
+			}
+		})
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	server := newMockServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=secret")
+		_ = json.NewEncoder(w).Encode(&admin.User{
+			PrimaryEmail: "jane@example.com",
+			Name:         &admin.UserName{FullName: "Jane Doe"},
+			OrgUnitPath:  "/Engineering",
+		})
+	})
+	defer server.Close()
+
+	dir := t.TempDir()
+	rec, err := newRecorder(filepath.Join(dir, "fixtures"))
+	if err != nil {
+		t.Fatalf("newRecorder() error = %v", err)
+	}
+
+	client := &http.Client{Transport: &recordingTransport{
+		base: &http.Transport{Proxy: func(*http.Request) (*url.URL, error) { return url.Parse(server.URL) }},
+		rec:  rec,
+	}}
+	srv, err := admin.NewService(context.Background(), option.WithHTTPClient(client), option.WithEndpoint(server.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.Users.Get("jane@example.com").Do(); err != nil {
+		t.Fatalf("recorded Users.Get() error = %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(rec.dir, "*.json"))
+	if len(files) != 1 {
+		t.Fatalf("recorded %d fixtures, want 1", len(files))
+	}
+	if strings.Contains(files[0], "jane") {
+		t.Errorf("fixture name %q leaks the email address", filepath.Base(files[0]))
+	}
+	info, err := os.Stat(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("fixture permissions = %o, want 600", perm)
+	}
+	raw, _ := os.ReadFile(files[0])
+	for _, leak := range []string{"jane@", "Jane Doe", "session=secret"} {
+		if strings.Contains(string(raw), leak) {
+			t.Errorf("fixture contains %q:\n%s", leak, raw)
+		}
+	}
+
+	// Replay with no server: the request is made with the pseudonymized
+	// address, as a user reproducing the capture would
+	server.Close()
+	replay, err := newReplayTransport(rec.dir)
+	if err != nil {
+		t.Fatalf("newReplayTransport() error = %v", err)
+	}
+	srv, err = admin.NewService(context.Background(), option.WithHTTPClient(&http.Client{Transport: replay}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := srv.Users.Get(pseudonymizeEmail("jane@example.com")).Do()
+	if err != nil {
+		t.Fatalf("replayed Users.Get() error = %v", err)
+	}
+	if user.OrgUnitPath != "/Engineering" {
+		t.Errorf("replayed OrgUnitPath = %q, want /Engineering", user.OrgUnitPath)
+	}
+
+	// Each fixture is served once
+	if _, err := srv.Users.Get(pseudonymizeEmail("jane@example.com")).Do(); err == nil {
+		t.Error("second replayed request succeeded, want no recorded response")
+	}
+}
+
+func TestReplayFixtures(t *testing.T) {
+	replay, err := newReplayTransport(filepath.Join("testdata", "replay", "user-lifecycle"))
+	if err != nil {
+		t.Fatalf("newReplayTransport() error = %v", err)
+	}
+	srv, err := admin.NewService(context.Background(), option.WithHTTPClient(&http.Client{Transport: replay}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	users, err := srv.Users.List().Customer("my_customer").MaxResults(500).Do()
+	if err != nil {
+		t.Fatalf("Users.List() error = %v", err)
+	}
+	if len(users.Users) != 2 {
+		t.Fatalf("Users.List() returned %d users, want 2", len(users.Users))
+	}
+
+	_, err = srv.Users.Get("user-00000000@example.com").Do()
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Users.Get() error = %v, want recorded 404", err)
+	}
+}
+
+func TestReplayTransportErrors(t *testing.T) {
+	if _, err := newReplayTransport(t.TempDir()); err == nil {
+		t.Error("newReplayTransport() on empty directory succeeded, want error")
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "0001-GET-x.json"), []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newReplayTransport(dir); err == nil {
+		t.Error("newReplayTransport() with invalid fixture succeeded, want error")
+	}
+}
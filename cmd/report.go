@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+// formatMarkdown outputs data as a GitHub-flavored Markdown table
+func formatMarkdown(w io.Writer, data interface{}, headers []string) error {
+	headers, rows, err := tableRows(data, headers)
+	if err != nil {
+		return err
+	}
+	if len(headers) == 0 && len(rows) > 0 {
+		// A Markdown table needs a header row
+		headers = make([]string, len(rows[0]))
+	}
+
+	var b strings.Builder
+	writeMarkdownRow(&b, headers)
+	b.WriteString("|")
+	for range headers {
+		b.WriteString(" --- |")
+	}
+	b.WriteString("\n")
+	for _, row := range rows {
+		writeMarkdownRow(&b, row)
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
+
+func writeMarkdownRow(b *strings.Builder, cells []string) {
+	b.WriteString("|")
+	for _, cell := range cells {
+		cell = strings.ReplaceAll(cell, `\`, `\\`)
+		cell = strings.ReplaceAll(cell, "|", `\|`)
+		cell = strings.ReplaceAll(cell, "\r\n", "<br>")
+		cell = strings.ReplaceAll(cell, "\n", "<br>")
+		b.WriteString(" " + cell + " |")
+	}
+	b.WriteString("\n")
+}
+
+// htmlReport is a standalone page with one table. Clicking a column header
+// sorts by that column, numerically when every cell is a number.
+var htmlReport = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>gac report</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; vertical-align: top; }
+th { background: #f0f0f0; cursor: pointer; user-select: none; }
+th[aria-sort=ascending]::after { content: " \25B2"; }
+th[aria-sort=descending]::after { content: " \25BC"; }
+tbody tr:nth-child(even) { background: #fafafa; }
+</style>
+</head>
+<body>
+<table>
+<thead>
+<tr>{{range .Headers}}<th>{{.}}</th>{{end}}</tr>
+</thead>
+<tbody>
+{{- range .Rows}}
+<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{- end}}
+</tbody>
+</table>
+<script>
+document.querySelectorAll("th").forEach(function (th, col) {
+  th.addEventListener("click", function () {
+    var tbody = th.closest("table").tBodies[0];
+    var rows = Array.from(tbody.rows);
+    var asc = th.getAttribute("aria-sort") !== "ascending";
+    var text = function (row) { return row.cells[col] ? row.cells[col].textContent : ""; };
+    var numeric = rows.every(function (row) { return text(row) === "" || !isNaN(Number(text(row))); });
+    rows.sort(function (a, b) {
+      var x = text(a), y = text(b);
+      var cmp = numeric ? Number(x) - Number(y) : x.localeCompare(y, undefined, { sensitivity: "base" });
+      return asc ? cmp : -cmp;
+    });
+    th.parentNode.querySelectorAll("th").forEach(function (h) { h.removeAttribute("aria-sort"); });
+    th.setAttribute("aria-sort", asc ? "ascending" : "descending");
+    rows.forEach(function (row) { tbody.appendChild(row); });
+  });
+});
+</script>
+</body>
+</html>
+`))
+
+// formatHTML outputs data as a standalone HTML page with a sortable table
+func formatHTML(w io.Writer, data interface{}, headers []string) error {
+	headers, rows, err := tableRows(data, headers)
+	if err != nil {
+		return err
+	}
+	report := struct {
+		Headers []string
+		Rows    [][]string
+	}{headers, rows}
+	if err := htmlReport.Execute(w, report); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+type reportRow struct {
+	Email  string `json:"email"`
+	Status string `json:"status"`
+}
+
+func TestFormatMarkdown(t *testing.T) {
+	withRunnerGlobals(t, OutputFormatMarkdown)
+
+	data := []reportRow{
+		{Email: "alice@example.com", Status: "ACTIVE"},
+		{Email: "bob@example.com", Status: "a|b\nc"},
+	}
+	want := "| Email | Status |\n| --- | --- |\n" +
+		"| alice@example.com | ACTIVE |\n" +
+		"| bob@example.com | a\\|b<br>c |\n"
+
+	var buf bytes.Buffer
+	if err := FormatOutputWithWriter(&buf, data, []string{"Email", "Status"}); err != nil {
+		t.Fatalf("FormatOutputWithWriter() error = %v", err)
+	}
+	if got := buf.String(); got != want {
+		t.Errorf("output =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFormatHTML(t *testing.T) {
+	withRunnerGlobals(t, OutputFormatHTML)
+
+	var buf bytes.Buffer
+	data := []reportRow{{Email: "alice@example.com", Status: "<b>ACTIVE</b>"}}
+	if err := FormatOutputWithWriter(&buf, data, nil); err != nil {
+		t.Fatalf("FormatOutputWithWriter() error = %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		"<tr><th>email</th><th>status</th></tr>",
+		"<tr><td>alice@example.com</td><td>&lt;b&gt;ACTIVE&lt;/b&gt;</td></tr>",
+		"<script>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestFormatXLSX(t *testing.T) {
+	withRunnerGlobals(t, OutputFormatXLSX)
+
+	var buf bytes.Buffer
+	data := []reportRow{{Email: "alice@example.com", Status: "Fish & Chips"}}
+	if err := FormatOutputWithWriter(&buf, data, []string{"Email", "Status"}); err != nil {
+		t.Fatalf("FormatOutputWithWriter() error = %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("output is not a zip archive: %v", err)
+	}
+	parts := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		parts[f.Name] = string(b)
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Errorf("workbook is missing %s", name)
+		}
+	}
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	for _, want := range []string{
+		`<c r="A1" s="1" t="inlineStr"><is><t xml:space="preserve">Email</t></is></c>`,
+		`<c r="B2" t="inlineStr"><is><t xml:space="preserve">Fish &amp; Chips</t></is></c>`,
+		`state="frozen"`,
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("sheet does not contain %q:\n%s", want, sheet)
+		}
+	}
+}
+
+func TestXLSXColumn(t *testing.T) {
+	tests := map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"}
+	for i, want := range tests {
+		if got := xlsxColumn(i); got != want {
+			t.Errorf("xlsxColumn(%d) = %q, want %q", i, got, want)
+		}
+	}
+}
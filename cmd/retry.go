@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Default retry settings, overridable under the "retry:" config key
+const (
+	defaultMaxRetries     = 5
+	defaultInitialBackoff = time.Second
+	defaultMaxBackoff     = 32 * time.Second
+)
+
+// retryConfig controls how failed API requests are retried
+type retryConfig struct {
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// retryNonIdempotentKey marks a request context as safe to retry even though
+// its method is not idempotent
+type retryNonIdempotentKey struct{}
+
+// withNonIdempotentRetry opts a call into retries for non-idempotent methods
+// such as POST. Use it only where repeating the request is harmless.
+func withNonIdempotentRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryNonIdempotentKey{}, true)
+}
+
+// getRetryConfig reads retry settings from flags, env vars and config
+func getRetryConfig() retryConfig {
+	cfg := retryConfig{
+		MaxRetries:     viper.GetInt("retry.max-retries"),
+		InitialBackoff: viper.GetDuration("retry.initial-backoff"),
+		MaxBackoff:     viper.GetDuration("retry.max-backoff"),
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	}
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = defaultInitialBackoff
+	}
+	if cfg.MaxBackoff < cfg.InitialBackoff {
+		cfg.MaxBackoff = cfg.InitialBackoff
+	}
+	return cfg
+}
+
+// retryTransport retries rate-limited and transiently failed requests with
+// jittered exponential backoff
+type retryTransport struct {
+	base http.RoundTripper
+	cfg  retryConfig
+
+	// sleep waits between attempts; replaced in tests
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// newRetryTransport wraps base with retry behaviour
+func newRetryTransport(base http.RoundTripper, cfg retryConfig) *retryTransport {
+	return &retryTransport{base: base, cfg: cfg, sleep: sleepContext}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.cfg.MaxRetries == 0 || !isRetryable(req) {
+		return t.base.RoundTrip(req)
+	}
+
+	// The body must be replayable to send it more than once
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		b, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(b))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(b)), nil
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		r := req
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r = req.Clone(req.Context())
+			r.Body = body
+		}
+
+		resp, err := t.base.RoundTrip(r)
+		reason := retryReason(req.Context(), resp, err)
+		if reason == "" || attempt >= t.cfg.MaxRetries {
+			return resp, err
+		}
+
+		delay := t.backoff(attempt)
+		if resp != nil {
+			if d, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+				delay = d
+			}
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+
+		LogAPICall(apiForRequest(req), req.Method+" "+req.URL.Path, map[string]interface{}{
+			"retry":       attempt + 1,
+			"max_retries": t.cfg.MaxRetries,
+			"reason":      reason,
+			"delay":       delay.String(),
+		})
+
+		if err := t.sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// backoff returns the jittered delay before retry number attempt+1: the
+// exponential step is capped at MaxBackoff, then a random value between
+// half and all of it is chosen so parallel clients spread out
+func (t *retryTransport) backoff(attempt int) time.Duration {
+	d := t.cfg.InitialBackoff
+	for i := 0; i < attempt && d < t.cfg.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > t.cfg.MaxBackoff {
+		d = t.cfg.MaxBackoff
+	}
+	half := d / 2
+	// #nosec G404 - jitter does not need a cryptographic source
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// isRetryable reports whether a request may be sent again: idempotent
+// methods always, others only when the caller opted in
+func isRetryable(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	optIn, _ := req.Context().Value(retryNonIdempotentKey{}).(bool)
+	return optIn
+}
+
+// retryReason explains why a response should be retried, or returns "" if it
+// should not
+func retryReason(ctx context.Context, resp *http.Response, err error) string {
+	if err != nil {
+		// Cancellation and deadlines are final
+		if ctx.Err() != nil {
+			return ""
+		}
+		return "transport error: " + err.Error()
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return "rate limited (429)"
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return "server error (" + strconv.Itoa(resp.StatusCode) + ")"
+	case http.StatusForbidden:
+		// Google reports quota errors as 403 with a reason in the body
+		if reason := googleErrorReason(resp); reason == "rateLimitExceeded" || reason == "userRateLimitExceeded" {
+			return reason
+		}
+	}
+	return ""
+}
+
+// googleErrorReason extracts the first error reason from a Google API error
+// body, leaving the body readable for the caller
+func googleErrorReason(resp *http.Response) string {
+	b, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(b))
+	if err != nil {
+		return ""
+	}
+
+	var body struct {
+		Error struct {
+			Errors []struct {
+				Reason string `json:"reason"`
+			} `json:"errors"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(b, &body); err != nil || len(body.Error.Errors) == 0 {
+		return ""
+	}
+	return body.Error.Errors[0].Reason
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an
+// HTTP date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		d := time.Until(when)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// sleepContext waits for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
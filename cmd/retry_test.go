@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestRetryTransport returns a retry transport that records delays
+// instead of sleeping
+func newTestRetryTransport(maxRetries int, delays *[]time.Duration) *retryTransport {
+	rt := newRetryTransport(http.DefaultTransport, retryConfig{
+		MaxRetries:     maxRetries,
+		InitialBackoff: time.Second,
+		MaxBackoff:     8 * time.Second,
+	})
+	rt.sleep = func(ctx context.Context, d time.Duration) error {
+		*delays = append(*delays, d)
+		return ctx.Err()
+	}
+	return rt
+}
+
+func TestRetryTransport(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		optIn       bool
+		responses   []int
+		body        string
+		retryAfter  string
+		wantStatus  int
+		wantCalls   int32
+		wantDelayEq time.Duration
+	}{
+		{
+			name:       "success is not retried",
+			method:     http.MethodGet,
+			responses:  []int{200},
+			wantStatus: 200,
+			wantCalls:  1,
+		},
+		{
+			name:       "429 then success",
+			method:     http.MethodGet,
+			responses:  []int{429, 200},
+			wantStatus: 200,
+			wantCalls:  2,
+		},
+		{
+			name:       "server errors then success",
+			method:     http.MethodDelete,
+			responses:  []int{503, 500, 200},
+			wantStatus: 200,
+			wantCalls:  3,
+		},
+		{
+			name:       "gives up after max retries",
+			method:     http.MethodGet,
+			responses:  []int{503, 503, 503, 503},
+			wantStatus: 503,
+			wantCalls:  4,
+		},
+		{
+			name:       "client errors are not retried",
+			method:     http.MethodGet,
+			responses:  []int{404},
+			wantStatus: 404,
+			wantCalls:  1,
+		},
+		{
+			name:       "403 rate limit reason is retried",
+			method:     http.MethodGet,
+			responses:  []int{403, 200},
+			body:       `{"error":{"code":403,"errors":[{"reason":"userRateLimitExceeded"}]}}`,
+			wantStatus: 200,
+			wantCalls:  2,
+		},
+		{
+			name:       "403 permission error is not retried",
+			method:     http.MethodGet,
+			responses:  []int{403},
+			body:       `{"error":{"code":403,"errors":[{"reason":"forbidden"}]}}`,
+			wantStatus: 403,
+			wantCalls:  1,
+		},
+		{
+			name:       "POST is not retried by default",
+			method:     http.MethodPost,
+			responses:  []int{503},
+			wantStatus: 503,
+			wantCalls:  1,
+		},
+		{
+			name:       "POST is retried when opted in",
+			method:     http.MethodPost,
+			optIn:      true,
+			responses:  []int{503, 200},
+			wantStatus: 200,
+			wantCalls:  2,
+		},
+		{
+			name:        "Retry-After is respected",
+			method:      http.MethodGet,
+			responses:   []int{429, 200},
+			retryAfter:  "7",
+			wantStatus:  200,
+			wantCalls:   2,
+			wantDelayEq: 7 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := atomic.AddInt32(&calls, 1)
+				if r.Method == http.MethodPost {
+					if b, _ := io.ReadAll(r.Body); string(b) != "payload" {
+						t.Errorf("attempt %d body = %q, want payload", n, b)
+					}
+				}
+				status := tt.responses[int(n)-1]
+				if status != 200 && tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(status)
+				if status != 200 {
+					_, _ = io.WriteString(w, tt.body)
+				}
+			}))
+			defer server.Close()
+
+			var delays []time.Duration
+			client := &http.Client{Transport: newTestRetryTransport(3, &delays)}
+
+			ctx := context.Background()
+			if tt.optIn {
+				ctx = withNonIdempotentRetry(ctx)
+			}
+			var body io.Reader
+			if tt.method == http.MethodPost {
+				body = strings.NewReader("payload")
+			}
+			req, err := http.NewRequestWithContext(ctx, tt.method, server.URL+"/admin/directory/v1/users", body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+			b, _ := io.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if got := atomic.LoadInt32(&calls); got != tt.wantCalls {
+				t.Errorf("server called %d times, want %d", got, tt.wantCalls)
+			}
+			if len(delays) != int(tt.wantCalls)-1 {
+				t.Errorf("slept %d times, want %d", len(delays), tt.wantCalls-1)
+			}
+			if tt.wantDelayEq != 0 && (len(delays) == 0 || delays[0] != tt.wantDelayEq) {
+				t.Errorf("delays = %v, want first delay %v", delays, tt.wantDelayEq)
+			}
+			if resp.StatusCode == 403 && string(b) != tt.body {
+				t.Errorf("error body not preserved: %q", b)
+			}
+		})
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	rt := newRetryTransport(http.DefaultTransport, retryConfig{
+		MaxRetries:     10,
+		InitialBackoff: time.Second,
+		MaxBackoff:     8 * time.Second,
+	})
+
+	for attempt, ceiling := range []time.Duration{1, 2, 4, 8, 8, 8} {
+		ceiling *= time.Second
+		for i := 0; i < 20; i++ {
+			d := rt.backoff(attempt)
+			if d < ceiling/2 || d > ceiling {
+				t.Fatalf("backoff(%d) = %v, want within [%v, %v]", attempt, d, ceiling/2, ceiling)
+			}
+		}
+	}
+}
+
+func TestRetryTransportCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rt := newRetryTransport(http.DefaultTransport, retryConfig{MaxRetries: 5, InitialBackoff: time.Hour, MaxBackoff: time.Hour})
+	rt.sleep = func(ctx context.Context, d time.Duration) error {
+		cancel()
+		return sleepContext(ctx, d)
+	}
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if _, err := (&http.Client{Transport: rt}).Do(req); err == nil {
+		t.Error("expected error after context cancellation")
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if d, ok := parseRetryAfter("12"); !ok || d != 12*time.Second {
+		t.Errorf("parseRetryAfter(12) = %v, %v", d, ok)
+	}
+	future := time.Now().Add(30 * time.Second).UTC().Format(http.TimeFormat)
+	if d, ok := parseRetryAfter(future); !ok || d <= 0 || d > 30*time.Second {
+		t.Errorf("parseRetryAfter(date) = %v, %v", d, ok)
+	}
+	if _, ok := parseRetryAfter("soon"); ok {
+		t.Error("parseRetryAfter(soon) should fail")
+	}
+	if _, ok := parseRetryAfter(""); ok {
+		t.Error("parseRetryAfter(\"\") should fail")
+	}
+}
+
+func TestAPIForRequest(t *testing.T) {
+	tests := map[string]string{
+		"https://admin.googleapis.com/admin/directory/v1/users":        "admin",
+		"https://admin.googleapis.com/admin/reports/v1/activity":       "reports",
+		"https://admin.googleapis.com/admin/datatransfer/v1/transfers": "datatransfer",
+		"https://www.googleapis.com/calendar/v3/calendars/primary":     "calendar",
+		"https://www.googleapis.com/groups/v1/groups/team@example.com": "groupssettings",
+		"https://oauth2.googleapis.com/token":                          "other",
+	}
+	for url, want := range tests {
+		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		if got := apiForRequest(req); got != want {
+			t.Errorf("apiForRequest(%s) = %q, want %q", url, got, want)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -27,47 +28,126 @@ var rootCmd = &cobra.Command{
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	//	Run: func(cmd *cobra.Command, args []string) { },
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+	// Errors are reported by Execute, with an exit code for their kind
+	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Flags and arguments were accepted; usage is no longer printed
+		// with errors
+		commandStarted = true
+		cmd.SilenceUsage = true
 		// Initialize logger with flags
 		InitLogger(verbose, logLevel, jsonLog)
-		// Set global confirmation skip flag
-		skipConfirmations = yesFlag
-		// Set output format and quiet mode
+		// Set output format first so later errors are reported in it
 		if formatFlag != "" {
 			if err := ValidateOutputFormat(formatFlag); err != nil {
-				Logger.Fatal().Err(err).Msg("Invalid output format")
+				return invalidInputf("%w", err)
 			}
 			outputFormat = OutputFormat(formatFlag)
+		} else if templateFlag != "" || templateFileFlag != "" {
+			outputFormat = OutputFormatTemplate
 		}
 		quietMode = quietFlag
+		if outputFileFlag != "" {
+			dest, err := newOutputFile(outputFileFlag)
+			if err != nil {
+				return invalidInputf("invalid output file path: %w", err)
+			}
+			outputDest = dest
+		}
+		if err := parseOutputSelection(fieldsFlag, queryFlag); err != nil {
+			return invalidInputf("%w", err)
+		}
+		if err := parseListOptions(filterFlags, sortByFlag); err != nil {
+			return invalidInputf("%w", err)
+		}
+		if err := validateLimit(limitFlag); err != nil {
+			return invalidInputf("%w", err)
+		}
+		if outputFormat == OutputFormatTemplate {
+			tmpl, err := parseOutputTemplate(templateFlag, templateFileFlag)
+			if err != nil {
+				return invalidInputf("%w", err)
+			}
+			outputTemplate = tmpl
+		} else if templateFlag != "" || templateFileFlag != "" {
+			return invalidInputf("--template and --template-file require --format template")
+		}
+		// Refuse to run against an unknown profile; the profiles commands
+		// stay usable so the configuration can be fixed
+		if profileLoadErr != nil && !isProfilesCommand(cmd) {
+			return invalidInputf("invalid configuration profile: %w", profileLoadErr)
+		}
+		// Apply --timeout and expose the signal-aware context to API calls
+		setupCommandContext(cmd)
+		// Request only the OAuth2 scopes this command declared
+		requiredScopes = commandScopes(cmd)
+		// Set global confirmation skip flag
+		skipConfirmations = yesFlag
+		return nil
 	},
 }
 
 // Execute adds all child commands to the root command sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		Logger.Fatal().Err(err).Msg("Command execution failed")
+	ctx, stop := newSignalContext()
+
+	err := rootCmd.ExecuteContext(ctx)
+	err = finishOutputFile(err)
+	// Report before releasing the contexts, which would mark them cancelled
+	code := reportError(os.Stderr, err)
+	cancelTimeout()
+	stop()
+	if code != 0 {
+		os.Exit(code)
 	}
 }
 
 func init() {
 	cobra.OnInitialize(initConfig)
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.google-admin.yaml)")
+	rootCmd.PersistentFlags().StringVar(&profileFlag, "profile", "", "named configuration profile to use (e.g., production, sandbox)")
+	rootCmd.PersistentFlags().String("customer-id", "", "Google Workspace customer ID (default: my_customer)")
 	rootCmd.PersistentFlags().StringVar(&clientSecret, "client-secret", clientSecret, "file containing client secret JSON")
 	rootCmd.PersistentFlags().StringVar(&cacheFile, "cache-file", cacheFile, "file containing oauth2 credential cache")
+	rootCmd.PersistentFlags().StringVar(&impersonate, "impersonate", "", "admin email to impersonate when using a service account key (domain-wide delegation)")
 	rootCmd.PersistentFlags().StringVar(&domain, "domain", "", "domain for email addresses (e.g., example.com)")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "enable verbose/debug logging")
 	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "log level (debug, info, warn, error)")
 	rootCmd.PersistentFlags().BoolVar(&jsonLog, "json-log", false, "output logs in JSON format")
 	rootCmd.PersistentFlags().BoolVarP(&yesFlag, "yes", "y", false, "skip all confirmation prompts (use with caution)")
-	rootCmd.PersistentFlags().StringVar(&formatFlag, "format", "", "output format: json, csv, yaml, table, plain (default: plain)")
+	rootCmd.PersistentFlags().StringVar(&formatFlag, "format", "", "output format: json, ndjson, csv, yaml, table, plain, template, markdown, html, xlsx (default: plain)")
+	rootCmd.PersistentFlags().StringVar(&fieldsFlag, "fields", "", "comma-separated fields to output, as dotted paths (e.g., 'primaryEmail,name.fullName,organizations[0].department')")
+	rootCmd.PersistentFlags().StringVar(&queryFlag, "query", "", "JSONPath expression selecting what to output (e.g., '$[*].primaryEmail')")
+	rootCmd.PersistentFlags().StringArrayVar(&filterFlags, "filter", nil, "only output list items matching field<op>value, with = != =~ !~ : < <= > >= (repeatable, e.g., 'orgUnitPath=~^/Engineering')")
+	rootCmd.PersistentFlags().StringVar(&sortByFlag, "sort-by", "", "sort list output by a field, optionally descending (e.g., 'name.fullName' or 'lastLoginTime,desc')")
+	rootCmd.PersistentFlags().IntVar(&limitFlag, "limit", 0, "output at most this many list items, after --filter and --sort-by (default: 0, no limit)")
+	rootCmd.PersistentFlags().IntVar(&flattenDepth, "flatten-depth", 0, "levels of nested fields that become CSV and table columns; deeper values are written as JSON (0 for no limit)")
+	rootCmd.PersistentFlags().StringVar(&flattenJoin, "flatten-join", flattenJoin, "separator for lists of values in one CSV or table cell")
+	rootCmd.PersistentFlags().StringVar(&templateFlag, "template", "", "Go template applied to each item with --format template (e.g., '{{.PrimaryEmail}}\t{{.OrgUnitPath}}')")
+	rootCmd.PersistentFlags().StringVar(&templateFileFlag, "template-file", "", "file containing the Go template for --format template")
+	rootCmd.PersistentFlags().StringVar(&outputFileFlag, "output-file", "", "write output to this file instead of stdout, replacing it only if the command succeeds (gzip-compressed if it ends in .gz)")
 	rootCmd.PersistentFlags().BoolVarP(&quietFlag, "quiet", "q", false, "quiet mode - minimal output suitable for scripting")
 
 	// Cache flags
 	rootCmd.PersistentFlags().BoolVar(&noCacheFlag, "no-cache", false, "disable cache and force API calls")
 	rootCmd.PersistentFlags().StringVar(&cacheTTLFlag, "cache-ttl", "", "cache TTL (e.g., '15m', '1h', '30s')")
 
+	// Cancellation flags
+	rootCmd.PersistentFlags().DurationVar(&timeoutFlag, "timeout", 0, "abort the command after this long (e.g., '30s', '5m'; 0 means no limit)")
+
+	// Record/replay flags
+	rootCmd.PersistentFlags().StringVar(&recordDir, "record", "", "save sanitized HTTP request/response pairs to this directory")
+	rootCmd.PersistentFlags().StringVar(&replayDir, "replay", "", "serve HTTP responses from a --record directory instead of the network")
+	rootCmd.MarkFlagsMutuallyExclusive("record", "replay")
+
+	// Endpoint flags
+	rootCmd.PersistentFlags().StringArrayVar(&apiEndpointFlags, "api-endpoint", nil, "use a different API endpoint: 'url' for every service or 'service=url' (repeatable)")
+	rootCmd.PersistentFlags().Bool("no-auth", false, "send requests without credentials (requires an api-endpoint override)")
+
+	// Retry flags
+	rootCmd.PersistentFlags().Int("max-retries", defaultMaxRetries, "retries for rate-limited or failed API requests (0 disables)")
+
 	// Maintain backward compatibility with old flag names
 	rootCmd.PersistentFlags().StringVar(&clientSecret, "secret", clientSecret, "deprecated: use --client-secret instead")
 	rootCmd.PersistentFlags().StringVar(&cacheFile, "cache", cacheFile, "deprecated: use --cache-file instead")
@@ -85,9 +165,24 @@ func init() {
 	if err := viper.BindPFlag("cache-file", rootCmd.PersistentFlags().Lookup("cache-file")); err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to bind cache-file flag: %s\n", err)
 	}
+	if err := viper.BindPFlag("impersonate", rootCmd.PersistentFlags().Lookup("impersonate")); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to bind impersonate flag: %s\n", err)
+	}
+	if err := viper.BindPFlag("profile", rootCmd.PersistentFlags().Lookup("profile")); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to bind profile flag: %s\n", err)
+	}
+	if err := viper.BindPFlag("customer-id", rootCmd.PersistentFlags().Lookup("customer-id")); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to bind customer-id flag: %s\n", err)
+	}
 	if err := viper.BindPFlag("domain", rootCmd.PersistentFlags().Lookup("domain")); err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to bind domain flag: %s\n", err)
 	}
+	if err := viper.BindPFlag("retry.max-retries", rootCmd.PersistentFlags().Lookup("max-retries")); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to bind max-retries flag: %s\n", err)
+	}
+	if err := viper.BindPFlag("no-auth", rootCmd.PersistentFlags().Lookup("no-auth")); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to bind no-auth flag: %s\n", err)
+	}
 
 	// Bind environment variables
 	// Supports both GOOGLE_ADMIN_CLIENT_SECRET and GAC_CLIENT_SECRET
@@ -100,11 +195,59 @@ func init() {
 	if err := viper.BindEnv("domain", "GAC_DOMAIN", "GOOGLE_ADMIN_DOMAIN"); err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to bind domain env vars: %s\n", err)
 	}
+	if err := viper.BindEnv("impersonate", "GAC_IMPERSONATE", "GOOGLE_ADMIN_IMPERSONATE"); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to bind impersonate env vars: %s\n", err)
+	}
+	if err := viper.BindEnv("profile", "GAC_PROFILE", "GOOGLE_ADMIN_PROFILE"); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to bind profile env vars: %s\n", err)
+	}
+	if err := viper.BindEnv("customer-id", "GAC_CUSTOMER_ID", "GOOGLE_ADMIN_CUSTOMER_ID"); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to bind customer-id env vars: %s\n", err)
+	}
+	if err := viper.BindEnv("retry.max-retries", "GAC_MAX_RETRIES", "GOOGLE_ADMIN_MAX_RETRIES"); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to bind max-retries env vars: %s\n", err)
+	}
+	if err := viper.BindEnv("no-auth", "GAC_NO_AUTH", "GOOGLE_ADMIN_NO_AUTH"); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to bind no-auth env vars: %s\n", err)
+	}
+	// The encryption key is only read from the environment (or a key file),
+	// never from flags, so it does not end up in shell history
+	if err := viper.BindEnv("encryption.key", "GAC_ENCRYPTION_KEY", "GOOGLE_ADMIN_ENCRYPTION_KEY"); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to bind encryption key env vars: %s\n", err)
+	}
+	if err := viper.BindEnv("encryption.key-file", "GAC_ENCRYPTION_KEY_FILE", "GOOGLE_ADMIN_ENCRYPTION_KEY_FILE"); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to bind encryption key-file env vars: %s\n", err)
+	}
+	if err := viper.BindEnv("encryption.enabled", "GAC_ENCRYPTION", "GOOGLE_ADMIN_ENCRYPTION"); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to bind encryption env vars: %s\n", err)
+	}
+	// GAC_API_ENDPOINT applies to every service, GAC_<SERVICE>_API_ENDPOINT
+	// (e.g. GAC_CALENDAR_API_ENDPOINT) to one
+	if err := viper.BindEnv("api-endpoint."+defaultEndpointService, "GAC_API_ENDPOINT", "GOOGLE_ADMIN_API_ENDPOINT"); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to bind api-endpoint env vars: %s\n", err)
+	}
+	for _, service := range apiServiceNames() {
+		name := strings.ToUpper(service)
+		if err := viper.BindEnv("api-endpoint."+service, "GAC_"+name+"_API_ENDPOINT", "GOOGLE_ADMIN_"+name+"_API_ENDPOINT"); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to bind %s api-endpoint env vars: %s\n", service, err)
+		}
+	}
+
+	// "subject" is accepted in config files as an alias for "impersonate"
+	viper.RegisterAlias("subject", "impersonate")
 
 	// Set default cache configuration
 	viper.SetDefault("cache.enabled", true)
 	viper.SetDefault("cache.ttl", "15m")
 	viper.SetDefault("cache.directory", "~/.cache/gac")
+
+	// Set default retry configuration
+	viper.SetDefault("retry.max-retries", defaultMaxRetries)
+	viper.SetDefault("retry.initial-backoff", defaultInitialBackoff.String())
+	viper.SetDefault("retry.max-backoff", defaultMaxBackoff.String())
+
+	// Set default client-side rate limiting (per-API limits in ratelimit.go)
+	viper.SetDefault("ratelimit.enabled", true)
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -123,6 +266,8 @@ func initConfig() {
 		fmt.Println("Using config file:", viper.ConfigFileUsed())
 	}
 
+	// Overlay the selected profile (--profile, GAC_PROFILE or default-profile)
+	profileLoadErr = applyProfile()
 }
 
 // getDomain returns the configured domain, with fallback to default
@@ -2,8 +2,10 @@ package cmd
 
 import (
 	"os"
+	"strings"
 	"testing"
 
+	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
@@ -127,3 +129,20 @@ func TestRootCommandHasRequiredFlags(t *testing.T) {
 		}
 	}
 }
+
+func TestRootInvalidFormatError(t *testing.T) {
+	originalFormat, originalStarted := formatFlag, commandStarted
+	defer func() { formatFlag, commandStarted = originalFormat, originalStarted }()
+
+	formatFlag = "bogus"
+	err := rootCmd.PersistentPreRunE(&cobra.Command{}, nil)
+	if err == nil {
+		t.Fatal("PersistentPreRunE() with --format bogus succeeded, want error")
+	}
+	if got := strings.Count(err.Error(), "invalid output format"); got != 1 {
+		t.Errorf("error %q repeats \"invalid output format\" %d times, want once", err, got)
+	}
+	if kind := classifyError(err); kind != kindInvalid {
+		t.Errorf("classifyError() = %v, want %v", kind, kindInvalid)
+	}
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/oauth2"
+	datatransfer "google.golang.org/api/admin/datatransfer/v1"
+	admin "google.golang.org/api/admin/directory/v1"
+	reports "google.golang.org/api/admin/reports/v1"
+	calendar "google.golang.org/api/calendar/v3"
+	groupssettings "google.golang.org/api/groupssettings/v1"
+)
+
+// scopesAnnotation is the cobra annotation holding a command's OAuth2 scopes
+const scopesAnnotation = "gac.oauth-scopes"
+
+var (
+	// requiredScopes are the scopes the running command declared; set in
+	// the root PersistentPreRun
+	requiredScopes []string
+
+	// allScopes covers every command and is requested by `gac init --all-scopes`
+	// and by commands that declare no scopes of their own
+	allScopes = []string{
+		admin.AdminDirectoryUserScope,
+		admin.AdminDirectoryGroupScope,
+		admin.AdminDirectoryGroupMemberScope,
+		admin.AdminDirectoryOrgunitScope,
+		admin.AdminDirectoryResourceCalendarScope,
+		calendar.CalendarScope,
+		datatransfer.AdminDatatransferScope,
+		groupssettings.AppsGroupsSettingsScope,
+		reports.AdminReportsAuditReadonlyScope,
+	}
+
+	// legacyTokenScopes are assumed for token caches written before gac
+	// recorded granted scopes, when every token was issued with this set
+	legacyTokenScopes = []string{
+		admin.AdminDirectoryUserReadonlyScope,
+		admin.AdminDirectoryUserScope,
+		admin.AdminDirectoryGroupReadonlyScope,
+		admin.AdminDirectoryGroupMemberReadonlyScope,
+		admin.AdminDirectoryGroupMemberScope,
+		admin.AdminDirectoryResourceCalendarReadonlyScope,
+		admin.AdminDirectoryResourceCalendarScope,
+		calendar.CalendarScope,
+		calendar.CalendarReadonlyScope,
+		calendar.CalendarEventsScope,
+		calendar.CalendarEventsReadonlyScope,
+		datatransfer.AdminDatatransferScope,
+		groupssettings.AppsGroupsSettingsScope,
+		reports.AdminReportsAuditReadonlyScope,
+	}
+)
+
+// requireScopes declares the OAuth2 scopes a command needs. Subcommands
+// without their own declaration inherit their parent's.
+func requireScopes(cmd *cobra.Command, scopes ...string) {
+	if cmd.Annotations == nil {
+		cmd.Annotations = map[string]string{}
+	}
+	cmd.Annotations[scopesAnnotation] = strings.Join(scopes, " ")
+}
+
+// commandScopes returns the scopes declared by cmd or its nearest ancestor
+func commandScopes(cmd *cobra.Command) []string {
+	for c := cmd; c != nil; c = c.Parent() {
+		if s, ok := c.Annotations[scopesAnnotation]; ok {
+			return strings.Fields(s)
+		}
+	}
+	return nil
+}
+
+// activeScopes returns the scopes to request for the running command
+func activeScopes() []string {
+	if len(requiredScopes) == 0 {
+		return allScopes
+	}
+	return requiredScopes
+}
+
+// scopeCovers reports whether a granted scope satisfies a wanted one.
+// Google scopes are hierarchical: ".../admin.directory.user" also grants
+// ".../admin.directory.user.readonly" and ".../admin.directory.user.alias".
+func scopeCovers(granted, want string) bool {
+	return granted == want || strings.HasPrefix(want, granted+".")
+}
+
+// missingScopes returns the required scopes not covered by granted
+func missingScopes(granted, required []string) []string {
+	var missing []string
+	for _, want := range required {
+		covered := false
+		for _, g := range granted {
+			if scopeCovers(g, want) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			missing = append(missing, want)
+		}
+	}
+	return missing
+}
+
+// grantedScopes returns the scopes an authorization response granted. Google
+// reports them in the token's "scope" field; if absent the requested scopes
+// are assumed.
+func grantedScopes(tok *oauth2.Token, requested []string) []string {
+	if s, ok := tok.Extra("scope").(string); ok && strings.TrimSpace(s) != "" {
+		return mergeScopes(strings.Fields(s))
+	}
+	return mergeScopes(requested)
+}
+
+// mergeScopes returns the sorted union of the given scope lists
+func mergeScopes(lists ...[]string) []string {
+	seen := map[string]bool{}
+	var merged []string
+	for _, list := range lists {
+		for _, s := range list {
+			if !seen[s] {
+				seen[s] = true
+				merged = append(merged, s)
+			}
+		}
+	}
+	sort.Strings(merged)
+	return merged
+}
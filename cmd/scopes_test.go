@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/oauth2"
+	admin "google.golang.org/api/admin/directory/v1"
+	calendar "google.golang.org/api/calendar/v3"
+)
+
+func TestScopeCovers(t *testing.T) {
+	tests := []struct {
+		name     string
+		granted  string
+		want     string
+		expected bool
+	}{
+		{
+			name:     "identical scope",
+			granted:  admin.AdminDirectoryUserReadonlyScope,
+			want:     admin.AdminDirectoryUserReadonlyScope,
+			expected: true,
+		},
+		{
+			name:     "write scope covers readonly",
+			granted:  admin.AdminDirectoryUserScope,
+			want:     admin.AdminDirectoryUserReadonlyScope,
+			expected: true,
+		},
+		{
+			name:     "user scope covers alias",
+			granted:  admin.AdminDirectoryUserScope,
+			want:     admin.AdminDirectoryUserAliasScope,
+			expected: true,
+		},
+		{
+			name:     "calendar covers events",
+			granted:  calendar.CalendarScope,
+			want:     calendar.CalendarEventsScope,
+			expected: true,
+		},
+		{
+			name:     "readonly does not cover write",
+			granted:  admin.AdminDirectoryUserReadonlyScope,
+			want:     admin.AdminDirectoryUserScope,
+			expected: false,
+		},
+		{
+			name:     "shared prefix without separator",
+			granted:  admin.AdminDirectoryUserScope,
+			want:     admin.AdminDirectoryUserschemaScope,
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scopeCovers(tt.granted, tt.want); got != tt.expected {
+				t.Errorf("scopeCovers(%q, %q) = %v, want %v", tt.granted, tt.want, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMissingScopes(t *testing.T) {
+	granted := []string{admin.AdminDirectoryUserScope}
+	required := []string{admin.AdminDirectoryUserReadonlyScope, admin.AdminDirectoryGroupReadonlyScope}
+
+	got := missingScopes(granted, required)
+	want := []string{admin.AdminDirectoryGroupReadonlyScope}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("missingScopes() = %v, want %v", got, want)
+	}
+
+	if got := missingScopes(legacyTokenScopes, []string{admin.AdminDirectoryUserReadonlyScope}); len(got) != 0 {
+		t.Errorf("legacy token should cover user.readonly, missing %v", got)
+	}
+}
+
+func TestGrantedScopes(t *testing.T) {
+	requested := []string{admin.AdminDirectoryGroupReadonlyScope}
+
+	tok := (&oauth2.Token{AccessToken: "a"}).WithExtra(map[string]interface{}{
+		"scope": admin.AdminDirectoryUserReadonlyScope + " " + admin.AdminDirectoryGroupReadonlyScope,
+	})
+	want := []string{admin.AdminDirectoryGroupReadonlyScope, admin.AdminDirectoryUserReadonlyScope}
+	if got := grantedScopes(tok, requested); !reflect.DeepEqual(got, want) {
+		t.Errorf("grantedScopes() = %v, want %v", got, want)
+	}
+
+	// Without a scope field the requested scopes are assumed
+	if got := grantedScopes(&oauth2.Token{AccessToken: "a"}, requested); !reflect.DeepEqual(got, requested) {
+		t.Errorf("grantedScopes() = %v, want %v", got, requested)
+	}
+}
+
+func TestCommandScopes(t *testing.T) {
+	tests := []struct {
+		args    []string
+		want    string
+		notWant string
+	}{
+		{args: []string{"user", "list"}, want: admin.AdminDirectoryUserReadonlyScope, notWant: admin.AdminDirectoryUserScope},
+		{args: []string{"user", "create"}, want: admin.AdminDirectoryUserScope},
+		{args: []string{"group", "list"}, want: admin.AdminDirectoryGroupReadonlyScope, notWant: calendar.CalendarScope},
+		{args: []string{"ou", "list"}, want: admin.AdminDirectoryOrgunitReadonlyScope, notWant: admin.AdminDirectoryOrgunitScope},
+		{args: []string{"calendar", "list"}, want: calendar.CalendarEventsReadonlyScope},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			cmd, _, err := rootCmd.Find(tt.args)
+			if err != nil {
+				t.Fatalf("Find(%v) error = %v", tt.args, err)
+			}
+			scopes := commandScopes(cmd)
+			if len(missingScopes(scopes, []string{tt.want})) != 0 {
+				t.Errorf("%v scopes = %v, want %s", tt.args, scopes, tt.want)
+			}
+			if tt.notWant != "" {
+				for _, s := range scopes {
+					if s == tt.notWant {
+						t.Errorf("%v requests %s, which it does not need", tt.args, s)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestAPICommandsDeclareScopes(t *testing.T) {
+	// Commands that never call a Google API
+	local := map[string]bool{
+		"gac cache clear": true, "gac cache status": true, "gac version": true,
+		"gac config validate": true, "gac config profiles list": true,
+		"gac config profiles use": true, "gac config profiles show": true,
+		"gac completion bash": true, "gac completion zsh": true, "gac completion fish": true,
+		"gac help": true,
+	}
+
+	var walk func(c *cobra.Command)
+	walk = func(c *cobra.Command) {
+		for _, sub := range c.Commands() {
+			walk(sub)
+		}
+		if !c.Runnable() || local[c.CommandPath()] || c == rootCmd {
+			return
+		}
+		if len(commandScopes(c)) == 0 {
+			t.Errorf("%q does not declare its OAuth2 scopes", c.CommandPath())
+		}
+	}
+	walk(rootCmd)
+}
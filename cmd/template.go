@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Global template settings (set by the --template and --template-file flags)
+var (
+	templateFlag     string
+	templateFileFlag string
+
+	outputTemplate *template.Template
+)
+
+// templateFuncs are the helper functions available to --template
+var templateFuncs = template.FuncMap{
+	"join":    templateJoin,
+	"default": templateDefault,
+	"date":    templateDate,
+	"upper":   strings.ToUpper,
+	"lower":   strings.ToLower,
+	"json":    templateJSON,
+}
+
+// parseOutputTemplate parses --template or --template-file. Escaped tabs
+// and newlines in --template are expanded, since shells pass them through
+// literally.
+func parseOutputTemplate(text, file string) (*template.Template, error) {
+	switch {
+	case text != "" && file != "":
+		return nil, errors.New("--template and --template-file cannot be used together")
+	case file != "":
+		if err := validateCredentialPath(file); err != nil {
+			return nil, fmt.Errorf("invalid template file path: %w", err)
+		}
+		// #nosec G304 - Path is validated by validateCredentialPath() above
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template file: %w", err)
+		}
+		text = string(b)
+	case text != "":
+		text = strings.NewReplacer(`\t`, "\t", `\n`, "\n").Replace(text)
+	default:
+		return nil, errors.New("--format template requires --template or --template-file")
+	}
+
+	tmpl, err := template.New("output").Funcs(templateFuncs).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// formatTemplate executes the output template once per item, or once for a
+// single object, ending each result with a newline
+func formatTemplate(w io.Writer, data interface{}) error {
+	if outputTemplate == nil {
+		return errors.New("no output template (use --template or --template-file)")
+	}
+
+	v := reflect.ValueOf(data)
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		for i := 0; i < v.Len(); i++ {
+			if err := executeTemplate(w, v.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return executeTemplate(w, data)
+}
+
+func executeTemplate(w io.Writer, item interface{}) error {
+	// Selected fields are addressed by path, e.g. {{index . "name.fullName"}}
+	if r, ok := item.(fieldRecord); ok {
+		m := make(map[string]interface{}, len(r.keys))
+		for i, key := range r.keys {
+			m[key] = r.values[i]
+		}
+		item = m
+	}
+	if err := outputTemplate.Execute(w, item); err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+	if _, err := fmt.Fprintln(w); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
+
+// templateJoin joins a list of any element type: {{join ", " .Aliases}}
+func templateJoin(sep string, list interface{}) (string, error) {
+	v := reflect.ValueOf(list)
+	if !v.IsValid() {
+		return "", nil
+	}
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return "", fmt.Errorf("join: expected a list, got %T", list)
+	}
+	parts := make([]string, v.Len())
+	for i := range parts {
+		parts[i] = fmt.Sprint(v.Index(i).Interface())
+	}
+	return strings.Join(parts, sep), nil
+}
+
+// templateDefault returns value, or def if value is empty:
+// {{.RecoveryEmail | default "n/a"}}
+func templateDefault(def, value interface{}) interface{} {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() || v.IsZero() {
+		return def
+	}
+	if (v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.Len() == 0 {
+		return def
+	}
+	return value
+}
+
+// templateDate formats a time.Time or an RFC 3339 timestamp with a Go layout:
+// {{date "2006-01-02" .CreationTime}}. Empty values format as "".
+func templateDate(layout string, value interface{}) (string, error) {
+	switch t := value.(type) {
+	case time.Time:
+		return t.Format(layout), nil
+	case *time.Time:
+		if t == nil {
+			return "", nil
+		}
+		return t.Format(layout), nil
+	case string:
+		if t == "" {
+			return "", nil
+		}
+		parsed, err := time.Parse(time.RFC3339, t)
+		if err != nil {
+			return "", fmt.Errorf("date: %w", err)
+		}
+		return parsed.Format(layout), nil
+	case nil:
+		return "", nil
+	default:
+		return "", fmt.Errorf("date: expected a time or RFC 3339 string, got %T", value)
+	}
+}
+
+// templateJSON encodes value as compact JSON: {{json .Emails}}
+func templateJSON(value interface{}) (string, error) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("json: %w", err)
+	}
+	return string(b), nil
+}
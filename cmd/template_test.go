@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	admin "google.golang.org/api/admin/directory/v1"
+)
+
+// withOutputTemplate sets the --format template output for one test
+func withOutputTemplate(t *testing.T, text string) {
+	t.Helper()
+	withRunnerGlobals(t, OutputFormatTemplate)
+	original := outputTemplate
+	t.Cleanup(func() { outputTemplate = original })
+	tmpl, err := parseOutputTemplate(text, "")
+	if err != nil {
+		t.Fatalf("parseOutputTemplate(%q) error = %v", text, err)
+	}
+	outputTemplate = tmpl
+}
+
+func TestFormatTemplate(t *testing.T) {
+	users := []*admin.User{
+		{
+			PrimaryEmail: "alice@example.com",
+			OrgUnitPath:  "/Engineering",
+			Name:         &admin.UserName{FullName: "Alice Smith"},
+			Aliases:      []string{"al@example.com", "asmith@example.com"},
+			CreationTime: "2024-03-01T09:30:00Z",
+		},
+		{
+			PrimaryEmail: "bob@example.com",
+			OrgUnitPath:  "/Sales",
+			Name:         &admin.UserName{FullName: "Bob Jones"},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		tmpl   string
+		data   interface{}
+		fields string
+		want   string
+	}{
+		{
+			name: "escaped tab",
+			tmpl: `{{.PrimaryEmail}}\t{{.OrgUnitPath}}`,
+			data: users,
+			want: "alice@example.com\t/Engineering\nbob@example.com\t/Sales\n",
+		},
+		{
+			name: "nested fields",
+			tmpl: `{{.Name.FullName | upper}} <{{.PrimaryEmail | lower}}>`,
+			data: users[0],
+			want: "ALICE SMITH <alice@example.com>\n",
+		},
+		{
+			name: "join and default",
+			tmpl: `{{.PrimaryEmail}}: {{join ", " .Aliases | default "no aliases"}}`,
+			data: users,
+			want: "alice@example.com: al@example.com, asmith@example.com\nbob@example.com: no aliases\n",
+		},
+		{
+			name: "date",
+			tmpl: `{{date "Jan 2, 2006" .CreationTime}}|{{date "2006" .LastLoginTime}}`,
+			data: users[0],
+			want: "Mar 1, 2024|\n",
+		},
+		{
+			name: "json",
+			tmpl: `{{json .Name}}`,
+			data: users[1],
+			want: `{"fullName":"Bob Jones"}` + "\n",
+		},
+		{
+			name:   "selected fields",
+			tmpl:   `{{index . "name.fullName"}}`,
+			data:   users,
+			fields: "name.fullName",
+			want:   "Alice Smith\nBob Jones\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withOutputTemplate(t, tt.tmpl)
+			withOutputSelection(t, tt.fields, "")
+
+			var buf bytes.Buffer
+			if err := FormatOutputWithWriter(&buf, tt.data, nil); err != nil {
+				t.Fatalf("FormatOutputWithWriter() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("output = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatTemplateExecutionError(t *testing.T) {
+	withOutputTemplate(t, `{{date "2006" .PrimaryEmail}}`)
+
+	var buf bytes.Buffer
+	err := FormatOutputWithWriter(&buf, &admin.User{PrimaryEmail: "alice@example.com"}, nil)
+	if err == nil || !strings.Contains(err.Error(), "failed to execute template") {
+		t.Errorf("FormatOutputWithWriter() error = %v, want a template error", err)
+	}
+}
+
+func TestParseOutputTemplate(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "body.tmpl")
+	if err := os.WriteFile(file, []byte("Hello {{.Name.GivenName}},\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		text    string
+		file    string
+		wantErr string
+	}{
+		{name: "inline", text: "{{.PrimaryEmail}}"},
+		{name: "file", file: file},
+		{name: "neither", wantErr: "requires --template or --template-file"},
+		{name: "both", text: "{{.}}", file: file, wantErr: "cannot be used together"},
+		{name: "syntax error", text: "{{.PrimaryEmail", wantErr: "invalid template"},
+		{name: "unknown function", text: "{{bogus .}}", wantErr: "invalid template"},
+		{name: "missing file", file: filepath.Join(dir, "missing.tmpl"), wantErr: "failed to read template file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseOutputTemplate(tt.text, tt.file)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("parseOutputTemplate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseOutputTemplate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTemplateDate(t *testing.T) {
+	ts := time.Date(2025, 7, 4, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value   interface{}
+		want    string
+		wantErr bool
+	}{
+		{value: ts, want: "2025-07-04"},
+		{value: &ts, want: "2025-07-04"},
+		{value: "2025-07-04T12:00:00Z", want: "2025-07-04"},
+		{value: "", want: ""},
+		{value: nil, want: ""},
+		{value: "yesterday", wantErr: true},
+		{value: 42, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := templateDate("2006-01-02", tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("templateDate(%v) = %q, %v, want %q, wantErr %v", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
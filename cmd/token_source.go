@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+// persistingTokenSource refreshes OAuth2 tokens and writes every new token
+// back to the token cache file. Without this, a rotated refresh token only
+// lives in memory and later gac runs fail to authenticate.
+type persistingTokenSource struct {
+	mu     sync.Mutex
+	ctx    context.Context
+	config *oauth2.Config
+	file   string
+	tok    *oauth2.Token
+	scopes []string
+}
+
+// newPersistingTokenSource wraps the given token so refreshes are persisted
+// to file
+func newPersistingTokenSource(ctx context.Context, config *oauth2.Config, file string, tok *oauth2.Token, scopes []string) oauth2.TokenSource {
+	return &persistingTokenSource{
+		ctx:    ctx,
+		config: config,
+		file:   file,
+		tok:    tok,
+		scopes: scopes,
+	}
+}
+
+// Token returns a valid token, refreshing and persisting it when needed
+func (s *persistingTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tok.Valid() {
+		return s.tok, nil
+	}
+
+	// Hold the cache lock across reload, refresh and save so two gac
+	// processes never spend the same refresh token
+	unlock, err := lockFile(s.file, true)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	// Another gac process may already have refreshed (and rotated) the token
+	cached, cachedScopes, err := readTokenFile(s.file)
+	if err == nil && cached.Valid() {
+		LogDebug("Using token refreshed by another process", map[string]interface{}{
+			"path": s.file,
+		})
+		s.tok = cached
+		return cached, nil
+	} else if err == nil && cached.RefreshToken != "" {
+		s.tok = cached
+		if len(cachedScopes) > 0 {
+			s.scopes = cachedScopes
+		}
+	}
+
+	tok, err := s.config.TokenSource(s.ctx, s.tok).Token()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeTokenFile(s.file, tok, s.scopes); err != nil {
+		LogWarn("Failed to persist refreshed token", map[string]interface{}{
+			"path":  s.file,
+			"error": err.Error(),
+		})
+	} else {
+		LogDebug("Persisted refreshed token", map[string]interface{}{
+			"path":   s.file,
+			"expiry": tok.Expiry,
+		})
+	}
+
+	s.tok = tok
+	return tok, nil
+}
@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// newRefreshServer returns a token endpoint that rotates the refresh token
+// on every call and counts how often it was hit
+func newRefreshServer(t *testing.T, calls *int32) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(calls, 1)
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("Failed to parse refresh request: %v", err)
+		}
+		if r.Form.Get("grant_type") != "refresh_token" {
+			t.Errorf("Expected refresh_token grant, got %q", r.Form.Get("grant_type"))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token":  "refreshed-access",
+			"refresh_token": "rotated-refresh",
+			"token_type":    "Bearer",
+			"expires_in":    3600 * int(n),
+		})
+	}))
+}
+
+func TestSaveTokenRoundTrip(t *testing.T) {
+	tmpDir := t.TempDir()
+	file := filepath.Join(tmpDir, "gac.json")
+
+	tok := &oauth2.Token{
+		AccessToken:  "access",
+		RefreshToken: "refresh",
+		TokenType:    "Bearer",
+		Expiry:       time.Now().Add(time.Hour).Round(time.Second),
+	}
+
+	scopes := []string{"https://www.googleapis.com/auth/admin.directory.user.readonly"}
+	if err := saveToken(file, tok, scopes); err != nil {
+		t.Fatalf("saveToken() error = %v", err)
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatalf("token file not written: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("token file permissions = %o, want 600", info.Mode().Perm())
+	}
+
+	got, gotScopes, err := tokenFromFile(file)
+	if err != nil {
+		t.Fatalf("tokenFromFile() error = %v", err)
+	}
+	if got.AccessToken != tok.AccessToken || got.RefreshToken != tok.RefreshToken || !got.Expiry.Equal(tok.Expiry) {
+		t.Errorf("tokenFromFile() = %+v, want %+v", got, tok)
+	}
+	if len(gotScopes) != 1 || gotScopes[0] != scopes[0] {
+		t.Errorf("tokenFromFile() scopes = %v, want %v", gotScopes, scopes)
+	}
+
+	// No temp files should be left behind by the atomic write
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if e.Name() != "gac.json" && e.Name() != "gac.json.lock" {
+			t.Errorf("unexpected leftover file %s", e.Name())
+		}
+	}
+}
+
+func TestSaveTokenConcurrent(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "gac.json")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tok := &oauth2.Token{AccessToken: "access", RefreshToken: string(rune('a' + i))}
+			if err := saveToken(file, tok, nil); err != nil {
+				t.Errorf("saveToken() error = %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if _, _, err := tokenFromFile(file); err != nil {
+		t.Errorf("token file corrupted after concurrent writes: %v", err)
+	}
+}
+
+func TestPersistingTokenSource(t *testing.T) {
+	var calls int32
+	server := newRefreshServer(t, &calls)
+	defer server.Close()
+
+	config := &oauth2.Config{
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+		Endpoint:     oauth2.Endpoint{TokenURL: server.URL},
+	}
+
+	t.Run("expired token is refreshed and persisted", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		file := filepath.Join(t.TempDir(), "gac.json")
+		expired := &oauth2.Token{
+			AccessToken:  "stale",
+			RefreshToken: "original-refresh",
+			Expiry:       time.Now().Add(-time.Hour),
+		}
+		if err := saveToken(file, expired, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		ts := newPersistingTokenSource(context.Background(), config, file, expired, nil)
+		tok, err := ts.Token()
+		if err != nil {
+			t.Fatalf("Token() error = %v", err)
+		}
+		if tok.AccessToken != "refreshed-access" {
+			t.Errorf("AccessToken = %q, want refreshed-access", tok.AccessToken)
+		}
+
+		saved, _, err := tokenFromFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if saved.RefreshToken != "rotated-refresh" {
+			t.Errorf("persisted RefreshToken = %q, want rotated-refresh", saved.RefreshToken)
+		}
+
+		// A second call reuses the valid token without hitting the endpoint
+		if _, err := ts.Token(); err != nil {
+			t.Fatal(err)
+		}
+		if n := atomic.LoadInt32(&calls); n != 1 {
+			t.Errorf("refresh endpoint called %d times, want 1", n)
+		}
+	})
+
+	t.Run("token refreshed by another process is reused", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		file := filepath.Join(t.TempDir(), "gac.json")
+		fresh := &oauth2.Token{
+			AccessToken:  "from-other-process",
+			RefreshToken: "other-refresh",
+			Expiry:       time.Now().Add(time.Hour),
+		}
+		if err := saveToken(file, fresh, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		expired := &oauth2.Token{
+			AccessToken:  "stale",
+			RefreshToken: "spent-refresh",
+			Expiry:       time.Now().Add(-time.Hour),
+		}
+		ts := newPersistingTokenSource(context.Background(), config, file, expired, nil)
+		tok, err := ts.Token()
+		if err != nil {
+			t.Fatalf("Token() error = %v", err)
+		}
+		if tok.AccessToken != "from-other-process" {
+			t.Errorf("AccessToken = %q, want from-other-process", tok.AccessToken)
+		}
+		if n := atomic.LoadInt32(&calls); n != 0 {
+			t.Errorf("refresh endpoint called %d times, want 0", n)
+		}
+	})
+}
+
+func TestTokenFromFileLegacyFormat(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "gac.json")
+	legacy := `{"access_token":"access","token_type":"Bearer","refresh_token":"refresh","expiry":"2030-01-01T00:00:00Z"}`
+	if err := os.WriteFile(file, []byte(legacy), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tok, scopes, err := tokenFromFile(file)
+	if err != nil {
+		t.Fatalf("tokenFromFile() error = %v", err)
+	}
+	if tok.RefreshToken != "refresh" {
+		t.Errorf("RefreshToken = %q, want refresh", tok.RefreshToken)
+	}
+	if len(scopes) != 0 {
+		t.Errorf("scopes = %v, want none recorded for legacy token", scopes)
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/spf13/cobra"
 	datatransfer "google.golang.org/api/admin/datatransfer/v1"
+	admin "google.golang.org/api/admin/directory/v1"
 )
 
 var (
@@ -20,27 +21,34 @@ var (
 	transferCmd = &cobra.Command{
 		Use:   "transfer",
 		Short: "Transfer user data",
-		Run:   transferRunFunc,
+		RunE:  transferRunFunc,
 	}
 )
 
 func init() {
 	rootCmd.AddCommand(transferCmd)
+	requireScopes(transferCmd,
+		datatransfer.AdminDatatransferScope,
+		admin.AdminDirectoryUserReadonlyScope,
+	)
 
 	transferCmd.Flags().StringVarP(&fromAddr, "from", "f", "", "source email address for doc transfer")
 	transferCmd.Flags().StringVarP(&toAddr, "to", "t", "", "destination email address for doc transfer")
 }
 
-func transferRunFunc(cmd *cobra.Command, args []string) {
+func transferRunFunc(cmd *cobra.Command, args []string) error {
 	if fromAddr == "" || toAddr == "" {
-		exitWithError("must provide --from and --to")
+		return invalidInputf("must provide --from and --to")
 	}
 	fmt.Printf("document transfer: %s --> %s\n", fromAddr, toAddr)
-	fromID, toID := getUserIDs(fromAddr, toAddr)
+	fromID, toID, err := getUserIDs(fromAddr, toAddr)
+	if err != nil {
+		return err
+	}
 
 	dtc, err := newDataTransferClient()
 	if err != nil {
-		exitWithError(fmt.Sprintf("unable to create client: %s", err))
+		return fmt.Errorf("unable to create client: %w", err)
 	}
 
 	// these should be stable, but if you need to look up appIDs this
@@ -48,7 +56,7 @@ func transferRunFunc(cmd *cobra.Command, args []string) {
 	// as := datatransfer.NewApplicationsService(dtc)
 	// ar, err := as.List().Do()
 	// if err != nil {
-	// 	exitWithError(err.Error())
+	// 	return err
 	// }
 	// var appID int64
 	// for _, i := range ar.Applications {
@@ -57,31 +65,31 @@ func transferRunFunc(cmd *cobra.Command, args []string) {
 	// 	}
 	// }
 	// if appID == 0 {
-	// 	exitWithError("application id not found")
+	// 	return invalidInputf("application id not found")
 	// }
 
-	transferDocs(dtc, fromID, toID)
 	// maybe?
 	//transferCalendar(...)
+	return transferDocs(dtc, fromID, toID)
 }
 
-func getUserIDs(fromAddr, toAddr string) (string, string) {
-	ac, err := newAdminClient()
+func getUserIDs(fromAddr, toAddr string) (string, string, error) {
+	client, err := newDirectoryClient()
 	if err != nil {
-		exitWithError(fmt.Sprintf("unable to create client: %s", err))
+		return "", "", fmt.Errorf("unable to create client: %w", err)
 	}
-	from, err := ac.Users.Get(fromAddr).Do()
+	from, err := client.GetUser(apiContext(), fromAddr)
 	if err != nil {
-		exitWithError(fmt.Sprintf("unable to get ID for %s: %v", fromAddr, err))
+		return "", "", fmt.Errorf("unable to get ID for %s: %w", fromAddr, err)
 	}
-	to, err := ac.Users.Get(toAddr).Do()
+	to, err := client.GetUser(apiContext(), toAddr)
 	if err != nil {
-		exitWithError(fmt.Sprintf("unable to get ID for %s: %v", toAddr, err))
+		return "", "", fmt.Errorf("unable to get ID for %s: %w", toAddr, err)
 	}
-	return from.Id, to.Id
+	return from.Id, to.Id, nil
 }
 
-func transferDocs(dtc *datatransfer.Service, fromID, toID string) {
+func transferDocs(dtc *datatransfer.Service, fromID, toID string) error {
 	// https://developers.google.com/admin-sdk/data-transfer/v1/parameters
 	p := datatransfer.ApplicationTransferParam{
 		Key: "PRIVACY_LEVEL",
@@ -100,20 +108,16 @@ func transferDocs(dtc *datatransfer.Service, fromID, toID string) {
 		OldOwnerUserId:           fromID,
 	}
 	ts := datatransfer.NewTransfersService(dtc)
-	tr, err := ts.Insert(&t).Do()
+	// Opt in to retrying this POST so rate limits and transient failures are
+	// retried with backoff by the shared transport
+	tr, err := ts.Insert(&t).Context(withNonIdempotentRetry(apiContext())).Do()
 	if err != nil {
-		if docTransfers == docTransfersMax {
-			exitWithError(err.Error())
-		}
-		fmt.Printf("retry %v/%v\n", docTransfers+1, docTransfersMax)
-		docTransfers = docTransfers + 1
-		time.Sleep(5 * time.Second)
-		transferDocs(dtc, fromID, toID)
+		return err
 	}
 	count := 1
 	for {
 		time.Sleep(5 * time.Second)
-		res, _ := ts.Get(tr.Id).Do()
+		res, _ := ts.Get(tr.Id).Context(apiContext()).Do()
 		if count == 5 {
 			if res != nil && res.OverallTransferStatusCode == "inProgress" {
 				fmt.Println("transfer running long")
@@ -125,7 +129,9 @@ func transferDocs(dtc *datatransfer.Service, fromID, toID string) {
 				}
 				fmt.Printf("retry %v/%v\n", docTransfers+1, docTransfersMax)
 				docTransfers = docTransfers + 1
-				transferDocs(dtc, fromID, toID)
+				if err := transferDocs(dtc, fromID, toID); err != nil {
+					return err
+				}
 			}
 		}
 		if res != nil && res.OverallTransferStatusCode == "completed" {
@@ -134,4 +140,5 @@ func transferDocs(dtc *datatransfer.Service, fromID, toID string) {
 		}
 		count = count + 1
 	}
+	return nil
 }
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"net/http"
+	"strings"
+)
+
+// apiPrefixes maps request path prefixes to the Google API they belong to
+var apiPrefixes = []struct {
+	prefix string
+	api    string
+}{
+	{"/admin/directory/", "admin"},
+	{"/admin/reports/", "reports"},
+	{"/admin/datatransfer/", "datatransfer"},
+	{"/calendar/", "calendar"},
+	{"/groups/", "groupssettings"},
+}
+
+// apiForRequest returns the short name of the Google API a request targets,
+// or "other" (e.g. for OAuth2 token requests)
+func apiForRequest(req *http.Request) string {
+	path := req.URL.Path
+	for _, p := range apiPrefixes {
+		if strings.HasPrefix(path, p.prefix) {
+			return p.api
+		}
+	}
+	return "other"
+}
+
+// newAPITransport wraps the authenticated transport with the behaviour
+// shared by every Google API call. Rate limiting sits below retries so every
+// attempt, including retries, draws from the API's quota.
+func newAPITransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return newRetryTransport(newRateLimitTransport(base), getRetryConfig())
+}
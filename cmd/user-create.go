@@ -6,6 +6,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/acockrell/google-admin-client/pkg/gac"
 	admin "google.golang.org/api/admin/directory/v1"
 
 	"github.com/spf13/cobra"
@@ -32,7 +33,7 @@ var (
 var createUserCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Interactively create the specified user",
-	Run:   createUserRunFunc,
+	RunE:  createUserRunFunc,
 	Long: `
 Interactively create a user.
 
@@ -74,29 +75,27 @@ command.
 
 func init() {
 	userCmd.AddCommand(createUserCmd)
+	requireScopes(createUserCmd,
+		admin.AdminDirectoryUserScope,
+		admin.AdminDirectoryGroupMemberScope,
+	)
 	createUserCmd.Flags().StringSliceVarP(&groups, "groups", "g", groups, "groups")
 	createUserCmd.Flags().StringVarP(&personalEmail, "email", "e", "", "email")
 	createUserCmd.Flags().StringVarP(&firstName, "first-name", "f", "", "first name")
 	createUserCmd.Flags().StringVarP(&lastName, "last-name", "l", "", "last name")
 }
 
-func createUserRunFunc(cmd *cobra.Command, args []string) {
-	// For interactive mode, we still need to handle collectUserInfo
-	// For now, if flags are not provided, use the old path
+func createUserRunFunc(cmd *cobra.Command, args []string) error {
+	// Prompt for whatever details were not given as flags
 	if personalEmail == "" || firstName == "" || lastName == "" {
-		createUserRunFuncInteractive(cmd, args)
-		return
+		return createUserRunFuncInteractive(cmd, args)
 	}
 
-	// Create real admin client
-	service, err := newAdminClient()
+	client, err := newDirectoryClient()
 	if err != nil {
-		exitWithError(fmt.Sprintf("unable to create client: %s", err))
+		return fmt.Errorf("unable to create client: %w", err)
 	}
 
-	// Wrap in adapter
-	client := newRealAdminClientAdapter(service)
-
 	// Package flags
 	flags := createUserFlags{
 		groups:        groups,
@@ -105,71 +104,55 @@ func createUserRunFunc(cmd *cobra.Command, args []string) {
 		lastName:      lastName,
 	}
 
-	// Call testable function
-	if err := createUserWithClient(client, args, flags); err != nil {
-		exitWithError(err.Error())
+	user, err := createUserWithClient(client, args, flags)
+	if err != nil {
+		return err
 	}
 
-	// Success message (note: password not accessible from testable function)
-	// For now, we'll skip the output in the refactored path
-	// TODO: Refactor to return user object and print here
+	fmt.Printf(EMAIL, user.PrimaryEmail, user.Password, user.PrimaryEmail)
+	return nil
 }
 
 // createUserRunFuncInteractive handles the interactive user creation flow
 // This preserves the existing behavior for when flags are not provided
-func createUserRunFuncInteractive(cmd *cobra.Command, args []string) {
-	var email string
-
+func createUserRunFuncInteractive(cmd *cobra.Command, args []string) error {
 	if len(args) == 0 {
-		exitWithError("email is a required argument")
+		return invalidInputf("email is a required argument")
 	}
 
-	email = SanitizeInput(args[0])
+	opts := gac.CreateUserOptions{
+		Email:  SanitizeInput(args[0]),
+		Groups: groups,
+	}
 
 	// Validate email address
-	if err := ValidateEmail(email); err != nil {
-		exitWithError(fmt.Sprintf("invalid email address: %s", err))
+	if err := ValidateEmail(opts.Email); err != nil {
+		return invalidInputf("invalid email address: %w", err)
 	}
-	client, err := newAdminClient()
-	if err != nil {
-		exitWithError(fmt.Sprintf("unable to create client: %s", err))
+	if err := validateGroupNames(opts.Groups); err != nil {
+		return err
 	}
-
-	user := admin.User{}
-	user.PrimaryEmail = email
-	user.ChangePasswordAtNextLogin = true
-	user.Password = randomPassword(12)
-
-	err = collectUserInfo(&user)
+	client, err := newGACClient()
 	if err != nil {
-		exitWithError(err.Error())
+		return fmt.Errorf("unable to create client: %w", err)
 	}
 
-	_, err = client.Users.Insert(&user).Do()
+	err = collectUserInfo(&opts)
 	if err != nil {
-		exitWithError(fmt.Sprintf("Unable to update %s: %s", email, err))
+		return err
 	}
 
-	for _, g := range groups {
-		// Validate group name
-		if err := ValidateGroupName(g); err != nil {
-			exitWithError(fmt.Sprintf("invalid group name '%s': %s", g, err))
-		}
-
-		groupEmail := g
-		if !strings.Contains(g, "@") {
-			groupEmail = g + "@" + getDomain()
-		}
-		_, err = client.Members.Insert(groupEmail, &admin.Member{Email: user.PrimaryEmail}).Do()
-		if err != nil {
-			exitWithError(fmt.Sprintf("Unable to add %s to group %s: %s", user.PrimaryEmail, g, err))
-		}
+	user, err := client.Users.Create(apiContext(), opts)
+	if err != nil {
+		return err
 	}
 
 	fmt.Printf(EMAIL, user.PrimaryEmail, user.Password, user.PrimaryEmail)
+	return nil
 }
 
-func collectUserInfo(user *admin.User) (err error) {
+// collectUserInfo prompts for the personal email and names of a new user
+func collectUserInfo(opts *gac.CreateUserOptions) (err error) {
 	reader := bufio.NewReader(os.Stdin)
 
 	fmt.Print("Personal Email: ")
@@ -181,7 +164,7 @@ func collectUserInfo(user *admin.User) (err error) {
 
 	// Validate personal email
 	if err := ValidateEmail(email); err != nil {
-		return fmt.Errorf("invalid personal email: %w", err)
+		return invalidInputf("invalid personal email: %w", err)
 	}
 
 	fmt.Print("First Name: ")
@@ -198,26 +181,6 @@ func collectUserInfo(user *admin.User) (err error) {
 	}
 	lname = strings.TrimRight(lname, "\n")
 
-	updateUser(user, email, fname, lname)
-
+	opts.PersonalEmail, opts.FirstName, opts.LastName = email, fname, lname
 	return nil
 }
-
-// TODO: smells like a method
-func updateUser(user *admin.User, email, fname, lname string) {
-	user.Emails = []admin.UserEmail{
-		{
-			Address: email,
-			Type:    "home",
-		},
-		{
-			Address: user.PrimaryEmail,
-			Primary: true,
-		},
-	}
-	user.Name = &admin.UserName{
-		FamilyName: lname,
-		GivenName:  fname,
-		FullName:   fname + " " + lname,
-	}
-}
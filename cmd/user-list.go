@@ -1,9 +1,9 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 
+	"github.com/acockrell/google-admin-client/pkg/gac"
 	admin "google.golang.org/api/admin/directory/v1"
 
 	"github.com/spf13/cobra"
@@ -20,7 +20,7 @@ var (
 var listUserCmd = &cobra.Command{
 	Use:   "list",
 	Short: "list users",
-	Run:   listUserRunFunc,
+	RunE:  listUserRunFunc,
 	Long: `
 List user(s).
 
@@ -35,6 +35,7 @@ $ gac user list username@example.com
 
 func init() {
 	userCmd.AddCommand(listUserCmd)
+	requireScopes(listUserCmd, admin.AdminDirectoryUserReadonlyScope)
 
 	// Backward compatibility flags (deprecated)
 	listUserCmd.Flags().BoolVarP(&fullOutput, "full", "f", false, "deprecated: use --format=json instead")
@@ -50,21 +51,6 @@ func init() {
 	listUserCmd.Flags().BoolVarP(&disabledOnly, "disabled-only", "d", disabledOnly, "lists only disabled accounts")
 }
 
-/*
-NOTE: This is in place of using .Query("orgUnitPath=/path/foo") because the
-existing of a space in the path name Regardless of encoding the path it
-results in HTTP 400
-*/
-func filterDisabledOnly(u admin.Users) admin.Users {
-	var formerEmployees admin.Users
-	for _, user := range u.Users {
-		if user.OrgUnitPath == "/Former employees" {
-			formerEmployees.Users = append(formerEmployees.Users, user)
-		}
-	}
-	return formerEmployees
-}
-
 // userListItem represents a simplified user for list output
 type userListItem struct {
 	Name        string `json:"name"`
@@ -73,16 +59,16 @@ type userListItem struct {
 	OrgUnitPath string `json:"orgUnitPath"`
 }
 
-func listUserRunFunc(cmd *cobra.Command, args []string) {
+func listUserRunFunc(cmd *cobra.Command, args []string) error {
 	var email string
 
 	if len(args) > 0 {
 		email = args[0]
 	}
 
-	client, err := newAdminClient()
+	client, err := newGACClient()
 	if err != nil {
-		exitWithError(fmt.Sprintf("unable to create client: %s", err))
+		return fmt.Errorf("unable to create client: %w", err)
 	}
 
 	// Handle backward compatibility with deprecated flags
@@ -92,17 +78,18 @@ func listUserRunFunc(cmd *cobra.Command, args []string) {
 	} else if csvOutput {
 		outputFormat = OutputFormatCSV
 	}
+	defer func() { outputFormat = originalFormat }()
 
 	// if email is supplied, display that user. otherwise, display a list of all users
 	if email != "" {
-		u, err := client.Users.Get(email).Do(Projection("FULL"))
+		u, err := client.Users.Get(apiContext(), email, gac.GetUserOptions{Projection: "FULL"})
 		if err != nil {
-			exitWithError(err.Error())
+			return fmt.Errorf("failed to get user %s: %w", email, err)
 		}
 
 		// For single user, always show full details
 		if err := FormatOutput(u, nil); err != nil {
-			exitWithError(fmt.Sprintf("Failed to format output: %s", err))
+			return fmt.Errorf("failed to format output: %w", err)
 		}
 	} else {
 		// Generate cache key based on domain and filters
@@ -117,50 +104,35 @@ func listUserRunFunc(cmd *cobra.Command, args []string) {
 		var u admin.Users
 
 		// Try to read from cache first
-		cachedData, err := readFromCache(cacheKey, cacheTTL)
+		cachedUsers, err := readFromCache[[]*admin.User](cacheKey, cacheTTL)
 		if err == nil {
-			// Cache hit - unmarshal the data
-			// The cached data is a slice of users
-			if users, ok := cachedData.([]interface{}); ok {
-				// Convert back to admin.User objects
-				for _, userInterface := range users {
-					// Marshal and unmarshal to convert map to struct
-					userBytes, _ := json.Marshal(userInterface)
-					var user admin.User
-					if err := json.Unmarshal(userBytes, &user); err == nil {
-						u.Users = append(u.Users, &user)
-					}
-				}
-				Logger.Debug().Str("key", cacheKey).Int("count", len(u.Users)).Msg("Using cached user list")
-			}
+			u.Users = cachedUsers
+			Logger.Debug().Str("key", cacheKey).Int("count", len(u.Users)).Msg("Using cached user list")
+		} else if streamingOutput() {
+			// Stream users as pages arrive; streamed listings are not cached
+			Logger.Debug().Str("key", cacheKey).Err(err).Msg("Cache miss, streaming from API")
+			_, err := streamNDJSON(outputWriter(), client.Users.All(apiContext(), gac.ListUsersOptions{FormerEmployeesOnly: disabledOnly}), "users")
+			return err
 		} else {
 			// Cache miss - fetch from API
 			Logger.Debug().Str("key", cacheKey).Err(err).Msg("Cache miss, fetching from API")
 
-			var pageToken string
-			for {
-				res, err := client.Users.List().Customer("my_customer").PageToken(pageToken).Do()
-				if err != nil {
-					exitWithError(err.Error())
+			u.Users, err = collectList(client.Users.All(apiContext(), gac.ListUsersOptions{FormerEmployeesOnly: disabledOnly}), fetchLimit())
+			if err != nil {
+				// On Ctrl-C or --timeout, show the pages fetched so far
+				if _, _, cancelled := cancellation(); !cancelled || len(u.Users) == 0 {
+					return fmt.Errorf("failed to list users: %w", err)
 				}
-				u.Users = append(u.Users, res.Users...)
-
-				if res.NextPageToken == "" {
-					break
-				}
-				pageToken = res.NextPageToken
 			}
 
-			// Write to cache (before filtering)
-			if err := writeToCache(cacheKey, u.Users, cacheTTL); err != nil {
-				Logger.Warn().Err(err).Msg("Failed to write to cache")
+			// Partial and --limit results are not cached
+			if apiContext().Err() == nil && fetchLimit() == 0 {
+				if err := writeToCache(cacheKey, u.Users, cacheTTL); err != nil {
+					Logger.Warn().Err(err).Msg("Failed to write to cache")
+				}
 			}
 		}
 
-		if disabledOnly {
-			u = filterDisabledOnly(u)
-		}
-
 		// Convert to simplified list items for CSV/table/plain formats
 		headers := []string{"Name", "Email", "Admin", "OrgUnitPath"}
 		var items []userListItem
@@ -174,19 +146,20 @@ func listUserRunFunc(cmd *cobra.Command, args []string) {
 			items = append(items, item)
 		}
 
-		// For JSON/YAML, output full user data
+		// For JSON/YAML, --fields/--query and --filter/--sort-by, output full user data
 		var outputData interface{}
-		if outputFormat == OutputFormatJSON || outputFormat == OutputFormatYAML {
+		if structuredOutput() {
 			outputData = u.Users
+			headers = nil // flattened into a column per field
 		} else {
 			outputData = items
 		}
 
 		if err := FormatOutput(outputData, headers); err != nil {
-			exitWithError(fmt.Sprintf("Failed to format output: %s", err))
+			return fmt.Errorf("failed to format output: %w", err)
 		}
+		warnPartialOutput(len(u.Users), "users")
 	}
 
-	// Restore original format
-	outputFormat = originalFormat
+	return nil
 }
@@ -64,12 +64,13 @@ Common use cases:
 
 func init() {
 	userCmd.AddCommand(userSuspendCmd)
+	requireScopes(userSuspendCmd, admin.AdminDirectoryUserScope)
 	userSuspendCmd.Flags().StringVarP(&suspendReason, "reason", "r", "", "reason for suspension")
 	userSuspendCmd.Flags().BoolVarP(&suspendForce, "force", "f", false, "skip confirmation prompt")
 }
 
 func userSuspendRunFunc(cmd *cobra.Command, args []string) error {
-	client, err := newAdminClient()
+	client, err := newGACClient()
 	if err != nil {
 		return fmt.Errorf("failed to create admin client: %w", err)
 	}
@@ -78,7 +79,7 @@ func userSuspendRunFunc(cmd *cobra.Command, args []string) error {
 
 	// Validate email format
 	if err := ValidateEmail(userEmail); err != nil {
-		return fmt.Errorf("invalid email format for %s: %w", userEmail, err)
+		return invalidInputf("invalid email format for %s: %w", userEmail, err)
 	}
 
 	// Show warning and prompt for confirmation unless --force or --yes is used
@@ -90,19 +91,6 @@ func userSuspendRunFunc(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	// Suspend the user
-	user := &admin.User{
-		Suspended: true,
-	}
-
-	// Add suspension reason if provided
-	if suspendReason != "" {
-		user.SuspensionReason = suspendReason
-	}
-
-	// Force send the Suspended field
-	user.ForceSendFields = []string{"Suspended"}
-
 	// Log API call
 	LogAPICall("admin", "Users.Update", map[string]interface{}{
 		"user_email": userEmail,
@@ -111,7 +99,7 @@ func userSuspendRunFunc(cmd *cobra.Command, args []string) error {
 	})
 
 	startTime := time.Now()
-	result, err := client.Users.Update(userEmail, user).Do()
+	result, err := client.Users.Suspend(apiContext(), userEmail, suspendReason)
 	duration := time.Since(startTime)
 
 	if err != nil {
@@ -119,7 +107,12 @@ func userSuspendRunFunc(cmd *cobra.Command, args []string) error {
 			"user_email": userEmail,
 			"duration":   duration,
 		})
-		return fmt.Errorf("failed to suspend user %s: %w (check: user exists, permissions, already suspended)", userEmail, err)
+		return withHint(fmt.Errorf("failed to suspend user %s: %w", userEmail, err),
+			failureReasons(
+				"User does not exist",
+				"User is already suspended",
+				"Insufficient permissions",
+			))
 	}
 
 	LogAPIResponse("admin", "Users.Update", 200, duration)
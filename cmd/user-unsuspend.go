@@ -58,22 +58,21 @@ Common use cases:
 
 func init() {
 	userCmd.AddCommand(userUnsuspendCmd)
+	requireScopes(userUnsuspendCmd, admin.AdminDirectoryUserScope)
 	userUnsuspendCmd.Flags().BoolVarP(&unsuspendForce, "force", "f", false, "skip confirmation prompt")
 }
 
 func userUnsuspendRunFunc(cmd *cobra.Command, args []string) error {
-	client, err := newAdminClient()
+	client, err := newGACClient()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error creating client: %v\n", err)
-		return err
+		return fmt.Errorf("failed to create client: %w", err)
 	}
 
 	userEmail := args[0]
 
 	// Validate email format
 	if err := ValidateEmail(userEmail); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Invalid email format: %v\n", err)
-		return err
+		return invalidInputf("invalid email format: %w", err)
 	}
 
 	// Show warning and prompt for confirmation unless --force is used
@@ -99,22 +98,14 @@ func userUnsuspendRunFunc(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Unsuspend the user
-	user := &admin.User{
-		Suspended: false,
-	}
-
-	// Force send the Suspended field
-	user.ForceSendFields = []string{"Suspended"}
-
-	result, err := client.Users.Update(userEmail, user).Do()
+	result, err := client.Users.Unsuspend(apiContext(), userEmail)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error unsuspending user: %v\n", err)
-		fmt.Fprintf(os.Stderr, "\nCommon reasons for failure:\n")
-		fmt.Fprintf(os.Stderr, "  - User does not exist\n")
-		fmt.Fprintf(os.Stderr, "  - Insufficient permissions\n")
-		fmt.Fprintf(os.Stderr, "  - User is already active (not suspended)\n")
-		return err
+		return withHint(fmt.Errorf("failed to unsuspend user: %w", err),
+			failureReasons(
+				"User does not exist",
+				"Insufficient permissions",
+				"User is already active (not suspended)",
+			))
 	}
 
 	fmt.Printf("Successfully unsuspended user account:\n\n")
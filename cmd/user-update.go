@@ -8,8 +8,8 @@ import (
 	"strings"
 
 	admin "google.golang.org/api/admin/directory/v1"
-	"google.golang.org/api/googleapi"
 
+	"github.com/acockrell/google-admin-client/pkg/gac"
 	"github.com/spf13/cobra"
 )
 
@@ -34,7 +34,7 @@ var (
 var updateUserCmd = &cobra.Command{
 	Use:   "update",
 	Short: "Update the specified user",
-	Run:   updateUserRunFunc,
+	RunE:  updateUserRunFunc,
 	Long: `
 Update the speficied user.
 
@@ -58,28 +58,13 @@ Usage
 `,
 }
 
-type orgArgs struct {
-	Dept  string
-	Title string
-}
-
-// Projection and associated type/method implements CallOption
-// interface to enable seeing custom user attributes.
-//
-// https://developers.google.com/admin-sdk/directory/reference/rest/v1/users/get#Projection
-// https://github.com/googleapis/google-api-go-client/blob/v0.14.0/googleapi/googleapi.go#L378
-func Projection(p string) googleapi.CallOption {
-	return projection(p)
-}
-
-type projection string
-
-func (p projection) Get() (string, string) {
-	return "projection", string(p)
-}
-
 func init() {
 	userCmd.AddCommand(updateUserCmd)
+	requireScopes(updateUserCmd,
+		admin.AdminDirectoryUserScope,
+		admin.AdminDirectoryGroupReadonlyScope,
+		admin.AdminDirectoryGroupMemberScope,
+	)
 	// Here you will define your flags and configuration settings.
 
 	// ensure groups is empty for update operations
@@ -105,179 +90,119 @@ func init() {
 	updateUserCmd.Flags().BoolVarP(&clearPII, "clear-pii", "", clearPII, "clear personal information")
 }
 
-func updateUserRunFunc(cmd *cobra.Command, args []string) {
+func updateUserRunFunc(cmd *cobra.Command, args []string) error {
 	var email string
 	if len(args) == 0 {
-		exitWithError("email is a required argument")
+		return invalidInputf("email is a required argument")
 	}
 	email = SanitizeInput(args[0])
 
 	// Validate email address
 	if err := ValidateEmail(email); err != nil {
-		exitWithError(fmt.Sprintf("invalid email address: %s", err))
+		return invalidInputf("invalid email address: %w", err)
 	}
 
-	client, err := newAdminClient()
+	client, err := newGACClient()
 	if err != nil {
-		exitWithError(fmt.Sprintf("unable to create client: %s", err))
+		return fmt.Errorf("unable to create client: %w", err)
+	}
+	if err := validateGroupNames(groups); err != nil {
+		return err
 	}
 
 	// if parameters aren't supplied, create a user based on stdin
-	user := new(admin.User)
 	if address == "" && dept == "" && employeeID == "" && employeeType == "" && ou == "" && managerEmail == "" && phone == "" && title == "" && len(groups) == 0 {
+		user := new(admin.User)
 		j, _ := io.ReadAll(os.Stdin)
 		err := json.Unmarshal(j, &user)
 		if err != nil {
-			exitWithError(err.Error())
+			return invalidInputf("invalid user JSON on stdin: %w", err)
 		}
-	} else {
-		if removeUser {
-			// signout sessions on all devices, reset cookies
-			client.Users.SignOut(email)
-
-			// asp/token edits == scope error
-			// Error 403: Request had insufficient authentication scopes.
-			//
-			// remove any application specific passwords
-			// as, err := client.Asps.List(email).Do()
-			// if err != nil {
-			// 	exitWithError(err.Error())
-			// }
-			// for _, a := range as.Items {
-			// 	client.Asps.Delete(email, a.CodeId)
-			// }
-			//
-			// remove any tokens issues to 3rd party apps
-			// ts, err := client.Tokens.List(email).Do()
-			// if err != nil {
-			// 	exitWithError(err.Error())
-			// }
-			// for _, t := range ts.Items {
-			// 	client.Tokens.Delete(email, t.ClientId)
-			// }
-
-			clearUserPII(user)
-			disableGsuiteUser(user)
-
-			gs, _ := client.Groups.List().UserKey(email).Do()
-			for _, g := range gs.Groups {
-				err := client.Members.Delete(g.Email, email).Do()
-				if err != nil {
-					exitWithError(fmt.Sprintf("Unable to remove %s from group %s: %s", email, g.Email, err))
-				}
-			}
-		} else if clearPII {
-			// If you just want to Clear PII without disabling the user.  Useful for testing.
-			clearUserPII(user)
-		} else {
-			if address != "" {
-				user.Addresses = parseAddress(SanitizeInput(address))
-			}
-			if dept != "" || title != "" {
-				// Validate department if provided
-				if dept != "" {
-					if err := ValidateDepartment(dept); err != nil {
-						exitWithError(fmt.Sprintf("invalid department: %s", err))
-					}
-				}
-				user.Organizations = parseOrg(&orgArgs{Dept: SanitizeInput(dept), Title: SanitizeInput(title)})
-			}
-			if employeeID != "" {
-				// Validate employee ID as UUID
-				if err := ValidateUUID(employeeID); err != nil {
-					exitWithError(fmt.Sprintf("invalid employee ID: %s", err))
-				}
-
-				u, err := client.Users.Get(email).Do()
-				if err != nil {
-					exitWithError(err.Error())
-				}
+		if _, err := client.Users.UpdateRecord(apiContext(), email, user); err != nil {
+			return fmt.Errorf("unable to update %s: %w", email, err)
+		}
+		return nil
+	}
 
-				if u.ExternalIds == nil || forceUpdate {
-					user.ExternalIds = parseID(employeeID)
-				} else {
-					fmt.Println("Skipping update of existing Employee ID, use --force.")
-				}
-			}
-			if employeeType != "" {
-				user.CustomSchemas = parseType(employeeType)
-				// lists user with custom attributes
-				// e.g. to reverse engineer wtf custom attributes look like :-/
-				// u, _ := client.Users.Get(email).Do(Projection("FULL"))
-				// fmt.Printf("DEBUG: %+v", string(u.CustomSchemas["Employee_Type"]))
+	if removeUser {
+		// asp/token edits == scope error
+		// Error 403: Request had insufficient authentication scopes.
+		//
+		// remove any application specific passwords
+		// as, err := client.Asps.List(email).Do()
+		// if err != nil {
+		// 	return err
+		// }
+		// for _, a := range as.Items {
+		// 	client.Asps.Delete(email, a.CodeId)
+		// }
+		//
+		// remove any tokens issues to 3rd party apps
+		// ts, err := client.Tokens.List(email).Do()
+		// if err != nil {
+		// 	return err
+		// }
+		// for _, t := range ts.Items {
+		// 	client.Tokens.Delete(email, t.ClientId)
+		// }
+		if _, err := client.Users.Offboard(apiContext(), email); err != nil {
+			return err
+		}
+	} else if clearPII {
+		// If you just want to Clear PII without disabling the user.  Useful for testing.
+		if _, err := client.Users.ClearPII(apiContext(), email); err != nil {
+			return fmt.Errorf("unable to update %s: %w", email, err)
+		}
+	} else {
+		opts := gac.UpdateUserOptions{
+			Address:             SanitizeInput(address),
+			Department:          SanitizeInput(dept),
+			Title:               SanitizeInput(title),
+			OrgUnitPath:         ou,
+			EmployeeID:          employeeID,
+			OverwriteEmployeeID: forceUpdate,
+			EmployeeType:        employeeType,
+			Groups:              groups,
+		}
+		// Validate department if provided
+		if dept != "" {
+			if err := ValidateDepartment(dept); err != nil {
+				return invalidInputf("invalid department: %w", err)
 			}
-			if ou != "" {
-				user.OrgUnitPath = ou
+		}
+		if employeeID != "" {
+			// Validate employee ID as UUID
+			if err := ValidateUUID(employeeID); err != nil {
+				return invalidInputf("invalid employee ID: %w", err)
 			}
-			if managerEmail != "" {
-				managerEmail = SanitizeInput(managerEmail)
-				// Validate manager email
-				if err := ValidateEmail(managerEmail); err != nil {
-					exitWithError(fmt.Sprintf("invalid manager email: %s", err))
-				}
-				user.Relations = parseManager(managerEmail)
+		}
+		if managerEmail != "" {
+			opts.Manager = SanitizeInput(managerEmail)
+			// Validate manager email
+			if err := ValidateEmail(opts.Manager); err != nil {
+				return invalidInputf("invalid manager email: %w", err)
 			}
-			if phone != "" {
-				// Validate phone numbers (handles multiple phones separated by semicolon)
-				phones := strings.Split(phone, ";")
-				for _, p := range phones {
-					if err := ValidatePhoneNumber(strings.TrimSpace(p)); err != nil {
-						exitWithError(fmt.Sprintf("invalid phone number: %s", err))
-					}
+		}
+		if phone != "" {
+			// Validate phone numbers (handles multiple phones separated by semicolon)
+			phones := strings.Split(phone, ";")
+			for _, p := range phones {
+				if err := ValidatePhoneNumber(strings.TrimSpace(p)); err != nil {
+					return invalidInputf("invalid phone number: %w", err)
 				}
-				user.Phones = parsePhone(phone)
 			}
+			opts.Phones = parsePhone(phone)
 		}
-	}
 
-	_, err = client.Users.Update(email, user).Do()
-	if err != nil {
-		exitWithError(fmt.Sprintf("Unable to update %s: %s", email, err))
-	}
-
-	for _, g := range groups {
-		// Validate group name
-		if err := ValidateGroupName(g); err != nil {
-			exitWithError(fmt.Sprintf("invalid group name '%s': %s", g, err))
-		}
-
-		groupEmail := g
-		if !strings.Contains(g, "@") {
-			groupEmail = g + "@" + getDomain()
-		}
-		_, err = client.Members.Insert(groupEmail, &admin.Member{Email: email}).Do()
-		if err != nil {
-			exitWithError(fmt.Sprintf("Unable to add %s to group %s: %s", email, g, err))
+		// A failed group add still returns the updated user
+		result, err := client.Users.Update(apiContext(), email, opts)
+		if result != nil && result.EmployeeIDSkipped {
+			fmt.Println("Skipping update of existing Employee ID, use --force.")
 		}
-	}
-}
-
-// ...
-func disableGsuiteUser(u *admin.User) {
-	u.ChangePasswordAtNextLogin = false
-	u.IncludeInGlobalAddressList = false
-	u.OrgUnitPath = "/Former employees"
-	u.Password = randomPassword(12)
-
-	// this stops mail delivery...
-	//u.Suspended = true
-}
-
-func clearUserPII(u *admin.User) {
-	u.ForceSendFields = []string{
-		"RecoveryEmail",
-		"RecoveryPhone",
-	}
-
-	// These are JSON arrays.  Sending Null fields will flush them out.
-	// No need to set them as blank strings.
-	u.NullFields = []string{
-		"Addresses",
-		"Emails",
+		return err
 	}
 
-	u.RecoveryEmail = ""
-	u.RecoveryPhone = ""
+	return client.Users.AddToGroups(apiContext(), email, groups)
 }
 
 // parse a phone string like "mobile:<number>" or "mobile:<number>;work:<number>"
@@ -294,53 +219,3 @@ func parsePhone(str string) (phones []admin.UserPhone) {
 	}
 	return phones
 }
-
-// parse manager email
-func parseManager(managerEmail string) (relations []admin.UserRelation) {
-	r := admin.UserRelation{
-		Type:  "manager",
-		Value: managerEmail,
-	}
-	return []admin.UserRelation{r}
-}
-
-// parse an address string
-func parseAddress(address string) []admin.UserAddress {
-	a := admin.UserAddress{Formatted: address}
-	return []admin.UserAddress{a}
-}
-
-// parse user organization
-func parseOrg(args *orgArgs) []admin.UserOrganization {
-	o := admin.UserOrganization{Primary: true}
-	if args.Dept != "" {
-		o.Department = args.Dept
-	}
-	if args.Title != "" {
-		o.Title = args.Title
-	}
-	return []admin.UserOrganization{o}
-}
-
-// parse employee type custom attribute
-func parseType(employeeType string) map[string]googleapi.RawMessage {
-	schema := make(map[string]googleapi.RawMessage)
-
-	// U-G-L-Y but works for now...
-	var t = []byte(`{"Staff":[{"type":"work","value":"Yes"}],"Contractor":[{"type":"work","value":""}]}`)
-	if employeeType == "contractor" {
-		t = []byte(`{"Staff":[{"type":"work","value":""}],"Contractor":[{"type":"work","value":"Yes"}]}`)
-	}
-	schema["Employee_Type"] = t
-
-	return schema
-}
-
-// parse employee ID
-func parseID(employeeID string) []admin.UserExternalId {
-	i := admin.UserExternalId{
-		Type:  "organization",
-		Value: employeeID,
-	}
-	return []admin.UserExternalId{i}
-}
@@ -1,9 +1,6 @@
 package cmd
 
 import (
-	"crypto/rand"
-	"math/big"
-
 	"github.com/spf13/cobra"
 )
 
@@ -27,20 +24,3 @@ func init() {
 	// userCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 
 }
-
-func randomPassword(length int) string {
-	const letterRunes = "abcdefghijkmnopqrstuvwxyzABCDEFGHIJKLMNPQRSTUVWXYZ123456789"
-
-	b := make([]byte, length)
-	maxIdx := big.NewInt(int64(len(letterRunes)))
-
-	for i := range b {
-		n, err := rand.Int(rand.Reader, maxIdx)
-		if err != nil {
-			// If crypto/rand fails, this is a critical error
-			panic("failed to generate secure random number: " + err.Error())
-		}
-		b[i] = letterRunes[n.Int64()]
-	}
-	return string(b)
-}
@@ -1,9 +1,7 @@
 package cmd
 
 import (
-	"fmt"
-	"strings"
-
+	"github.com/acockrell/google-admin-client/pkg/gac"
 	admin "google.golang.org/api/admin/directory/v1"
 )
 
@@ -16,53 +14,45 @@ type createUserFlags struct {
 }
 
 // createUserWithClient is the testable version of createUserRunFunc
-// It accepts a client interface and returns errors instead of calling os.Exit
-func createUserWithClient(client adminClientInterface, args []string, flags createUserFlags) error {
+// It accepts a client interface
+func createUserWithClient(client gac.Directory, args []string, flags createUserFlags) (*admin.User, error) {
 	if len(args) == 0 {
-		return fmt.Errorf("email is a required argument")
+		return nil, invalidInputf("email is a required argument")
 	}
 
 	email := SanitizeInput(args[0])
 
 	// Validate email address
 	if err := ValidateEmail(email); err != nil {
-		return fmt.Errorf("invalid email address: %w", err)
+		return nil, invalidInputf("invalid email address: %w", err)
 	}
 
-	user := admin.User{}
-	user.PrimaryEmail = email
-	user.ChangePasswordAtNextLogin = true
-	user.Password = randomPassword(12)
-
 	// Skip interactive collection if flags are provided
 	if flags.personalEmail == "" || flags.firstName == "" || flags.lastName == "" {
-		return fmt.Errorf("all user details must be provided via flags (use -e, -f, -l)")
+		return nil, invalidInputf("all user details must be provided via flags (use -e, -f, -l)")
 	}
 
-	updateUser(&user, flags.personalEmail, flags.firstName, flags.lastName)
-
-	_, err := client.InsertUser(&user)
-	if err != nil {
-		return fmt.Errorf("unable to create user %s: %w", email, err)
+	// Check group names before the user is created
+	if err := validateGroupNames(flags.groups); err != nil {
+		return nil, err
 	}
 
-	// Add user to groups
-	for _, g := range flags.groups {
-		// Validate group name
-		if err := ValidateGroupName(g); err != nil {
-			return fmt.Errorf("invalid group name '%s': %w", g, err)
-		}
-
-		groupEmail := g
-		if !strings.Contains(g, "@") {
-			groupEmail = g + "@" + getDomain()
-		}
+	svc := gac.NewWithServices(gacConfig(), gac.Services{Directory: client})
+	return svc.Users.Create(apiContext(), gac.CreateUserOptions{
+		Email:         email,
+		FirstName:     flags.firstName,
+		LastName:      flags.lastName,
+		PersonalEmail: flags.personalEmail,
+		Groups:        flags.groups,
+	})
+}
 
-		_, err = client.InsertMember(groupEmail, &admin.Member{Email: user.PrimaryEmail})
-		if err != nil {
-			return fmt.Errorf("unable to add %s to group %s: %w", user.PrimaryEmail, g, err)
+// validateGroupNames checks the group names given on the command line
+func validateGroupNames(groups []string) error {
+	for _, g := range groups {
+		if err := ValidateGroupName(g); err != nil {
+			return invalidInputf("invalid group name '%s': %w", g, err)
 		}
 	}
-
 	return nil
 }
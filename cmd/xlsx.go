@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"unicode/utf8"
+)
+
+// xlsxMaxColumnWidth caps auto-sized column widths, in characters
+const xlsxMaxColumnWidth = 60
+
+// The fixed parts of a one-sheet workbook. Style 1 is the bold header row.
+var xlsxParts = []struct{ name, content string }{
+	{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="gac" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`},
+	{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+		`</Relationships>`},
+	{"xl/styles.xml", xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+		`</styleSheet>`},
+}
+
+// formatXLSX outputs data as an Excel workbook with one sheet. The header
+// row is bold and frozen; every cell is text, so IDs keep all their digits.
+func formatXLSX(w io.Writer, data interface{}, headers []string) error {
+	if f, ok := w.(*os.File); ok {
+		if info, err := f.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+			return invalidInputf("--format xlsx writes a binary workbook; write it to a file with --output-file report.xlsx")
+		}
+	}
+
+	var rows [][]string
+	if data != nil {
+		var err error
+		if headers, rows, err = tableRows(data, headers); err != nil {
+			return err
+		}
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, part := range xlsxParts {
+		if err := writeZipFile(zw, part.name, []byte(part.content)); err != nil {
+			return err
+		}
+	}
+	if err := writeZipFile(zw, "xl/worksheets/sheet1.xml", xlsxSheet(headers, rows)); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to write workbook: %w", err)
+	}
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
+
+func writeZipFile(zw *zip.Writer, name string, content []byte) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to write workbook: %w", err)
+	}
+	if _, err := f.Write(content); err != nil {
+		return fmt.Errorf("failed to write workbook: %w", err)
+	}
+	return nil
+}
+
+// xlsxSheet builds the worksheet XML with inline string cells
+func xlsxSheet(headers []string, rows [][]string) []byte {
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	if len(headers) > 0 {
+		b.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	}
+
+	widths := xlsxColumnWidths(headers, rows)
+	if len(widths) > 0 {
+		b.WriteString("<cols>")
+		for i, width := range widths {
+			fmt.Fprintf(&b, `<col min="%d" max="%d" width="%d" customWidth="1"/>`, i+1, i+1, width)
+		}
+		b.WriteString("</cols>")
+	}
+
+	b.WriteString("<sheetData>")
+	rowNum := 1
+	if len(headers) > 0 {
+		xlsxRow(&b, rowNum, headers, 1)
+		rowNum++
+	}
+	for _, row := range rows {
+		xlsxRow(&b, rowNum, row, 0)
+		rowNum++
+	}
+	b.WriteString("</sheetData></worksheet>")
+	return b.Bytes()
+}
+
+func xlsxRow(b *bytes.Buffer, rowNum int, cells []string, style int) {
+	fmt.Fprintf(b, `<row r="%d">`, rowNum)
+	for i, cell := range cells {
+		ref := xlsxColumn(i) + strconv.Itoa(rowNum)
+		if style > 0 {
+			fmt.Fprintf(b, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">`, ref, style)
+		} else {
+			fmt.Fprintf(b, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+		}
+		// EscapeText only fails on write errors, which bytes.Buffer has none of
+		_ = xml.EscapeText(b, []byte(cell))
+		b.WriteString("</t></is></c>")
+	}
+	b.WriteString("</row>")
+}
+
+// xlsxColumn returns the letters of column i (0 is A, 26 is AA)
+func xlsxColumn(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// xlsxColumnWidths sizes each column to its longest cell
+func xlsxColumnWidths(headers []string, rows [][]string) []int {
+	var widths []int
+	measure := func(cells []string) {
+		for i, cell := range cells {
+			for len(widths) <= i {
+				widths = append(widths, 8)
+			}
+			if n := utf8.RuneCountInString(cell) + 2; n > widths[i] {
+				widths[i] = min(n, xlsxMaxColumnWidth)
+			}
+		}
+	}
+	measure(headers)
+	for _, row := range rows {
+		measure(row)
+	}
+	return widths
+}
@@ -0,0 +1,161 @@
+package gac
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"strings"
+	"time"
+
+	reports "google.golang.org/api/admin/reports/v1"
+)
+
+// Applications lists the applications audit logs can be exported for
+var Applications = []string{
+	"admin",
+	"login",
+	"drive",
+	"calendar",
+	"groups",
+	"mobile",
+	"token",
+	"groups_enterprise",
+	"saml",
+	"chrome",
+	"gcp",
+	"chat",
+	"meet",
+}
+
+// ValidApplication reports whether name is one of Applications
+func ValidApplication(name string) bool {
+	for _, app := range Applications {
+		if app == name {
+			return true
+		}
+	}
+	return false
+}
+
+// AuditService reads audit logs from the Reports API
+type AuditService struct {
+	reports *reports.Service
+}
+
+// ActivityQuery selects audit log entries
+type ActivityQuery struct {
+	// Application is one of Applications
+	Application string
+
+	// User limits the results to one user; empty means all users
+	User string
+
+	// StartTime and EndTime bound the results. EndTime defaults to now and
+	// StartTime to 24 hours before EndTime.
+	StartTime time.Time
+	EndTime   time.Time
+
+	// EventNames limits the results to these events
+	EventNames []string
+
+	// ActorIP limits the results to one actor IP address
+	ActorIP string
+
+	// MaxResults caps the number of entries returned; 0 means all
+	MaxResults int64
+}
+
+// ActivityList is the result of an audit log query
+type ActivityList struct {
+	Items []*reports.Activity
+
+	// Pages is the number of API pages fetched
+	Pages int
+}
+
+// Activities returns the audit log entries matching q, newest first. If a
+// page fails, the entries fetched so far are returned with the error.
+func (s *AuditService) Activities(ctx context.Context, q ActivityQuery) (*ActivityList, error) {
+	call, err := s.listCall(q)
+	if err != nil {
+		return nil, err
+	}
+	result := &ActivityList{}
+	result.Items, err = collect(limit(paginate(activityPages(ctx, call, &result.Pages)), q.MaxResults))
+	return result, err
+}
+
+// All yields the audit log entries matching q as each page arrives, newest
+// first. An invalid query or a failed page ends the sequence with its error.
+// Each range over the sequence runs the query again from the first page.
+func (s *AuditService) All(ctx context.Context, q ActivityQuery) iter.Seq2[*reports.Activity, error] {
+	return func(yield func(*reports.Activity, error) bool) {
+		// The call holds the page token, so each range needs its own
+		call, err := s.listCall(q)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		var pages int
+		for a, err := range limit(paginate(activityPages(ctx, call, &pages)), q.MaxResults) {
+			if !yield(a, err) {
+				return
+			}
+		}
+	}
+}
+
+// activityPages fetches pages of call, counting them in pages
+func activityPages(ctx context.Context, call *reports.ActivitiesListCall, pages *int) pageFunc[*reports.Activity] {
+	return func(pageToken string) ([]*reports.Activity, string, error) {
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+		resp, err := call.Context(ctx).Do()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to fetch activities page %d: %w", *pages+1, err)
+		}
+		*pages++
+		return resp.Items, resp.NextPageToken, nil
+	}
+}
+
+// listCall validates q and builds the Reports API call for it
+func (s *AuditService) listCall(q ActivityQuery) (*reports.ActivitiesListCall, error) {
+	if !ValidApplication(q.Application) {
+		return nil, invalidf("invalid application %q: must be one of %s", q.Application, strings.Join(Applications, ", "))
+	}
+
+	end := q.EndTime
+	if end.IsZero() {
+		end = time.Now()
+	}
+	start := q.StartTime
+	if start.IsZero() {
+		start = end.Add(-24 * time.Hour)
+	}
+	if end.Before(start) {
+		return nil, invalidf("end time must be after start time")
+	}
+
+	// For the Reports API, userKey can be "all" or a specific user email
+	userKey := "all"
+	if q.User != "" {
+		userKey = q.User
+	}
+
+	call := s.reports.Activities.List(userKey, q.Application).
+		StartTime(start.Format(time.RFC3339)).
+		EndTime(end.Format(time.RFC3339))
+	if q.ActorIP != "" {
+		call = call.ActorIpAddress(q.ActorIP)
+	}
+	if len(q.EventNames) > 0 {
+		call = call.EventName(strings.Join(q.EventNames, ","))
+	}
+	if q.MaxResults > 0 {
+		call = call.MaxResults(q.MaxResults)
+	}
+
+	return call, nil
+}
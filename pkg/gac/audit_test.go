@@ -0,0 +1,79 @@
+package gac
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestValidApplication(t *testing.T) {
+	for _, app := range Applications {
+		if !ValidApplication(app) {
+			t.Errorf("ValidApplication(%q) = false, want true", app)
+		}
+	}
+	if ValidApplication("mail") {
+		t.Error(`ValidApplication("mail") = true, want false`)
+	}
+}
+
+func TestAuditServiceActivities(t *testing.T) {
+	client, srv := newTestClient(t)
+	srv.PageSize = 1
+	ctx := context.Background()
+	day := func(d int) time.Time { return time.Date(2026, 1, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name      string
+		query     ActivityQuery
+		want      int
+		wantPages int
+		wantErr   bool
+	}{
+		{"whole range across pages", ActivityQuery{Application: "login", StartTime: day(1), EndTime: day(10)}, 2, 2, false},
+		{"one user", ActivityQuery{Application: "login", User: "bob@example.com", StartTime: day(1), EndTime: day(10)}, 1, 1, false},
+		{"start time", ActivityQuery{Application: "login", StartTime: day(6), EndTime: day(10)}, 1, 1, false},
+		{"max results", ActivityQuery{Application: "login", StartTime: day(1), EndTime: day(10), MaxResults: 1}, 1, 1, false},
+		{"invalid application", ActivityQuery{Application: "mail"}, 0, 0, true},
+		{"end before start", ActivityQuery{Application: "login", StartTime: day(10), EndTime: day(1)}, 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := client.Audit.Activities(ctx, tt.query)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Activities() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(result.Items) != tt.want || result.Pages != tt.wantPages {
+				t.Errorf("Activities() = %d items in %d pages, want %d in %d", len(result.Items), result.Pages, tt.want, tt.wantPages)
+			}
+		})
+	}
+}
+
+func TestAuditServiceAllRepeatable(t *testing.T) {
+	client, srv := newTestClient(t)
+	srv.PageSize = 1
+	q := ActivityQuery{
+		Application: "login",
+		StartTime:   time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndTime:     time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC),
+	}
+
+	seq := client.Audit.All(context.Background(), q)
+	for run := 1; run <= 2; run++ {
+		var ids []int64
+		for a, err := range seq {
+			if err != nil {
+				t.Fatalf("run %d: All() error = %v", run, err)
+			}
+			ids = append(ids, a.Id.UniqueQualifier)
+		}
+		if len(ids) != 2 {
+			t.Errorf("run %d: All() yielded %v, want both activities from the first page on", run, ids)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+// Package gac is the Go API behind the gac command line tool. It wraps the
+// Google Workspace Admin APIs in services for users, groups, organizational
+// units, calendar resources and audit logs, so other Go programs can run the
+// same operations the commands do.
+//
+// Every call takes a context and an options struct; nothing is read from
+// flags, config files or the environment.
+//
+//	client, err := gac.New(ctx, gac.Config{Domain: "example.com"},
+//		option.WithHTTPClient(httpClient))
+//	if err != nil {
+//		return err
+//	}
+//	user, err := client.Users.Create(ctx, gac.CreateUserOptions{
+//		Email:         "jdoe@example.com",
+//		FirstName:     "Jane",
+//		LastName:      "Doe",
+//		PersonalEmail: "jane@personal.example",
+//		Groups:        []string{"engineering"},
+//	})
+package gac
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	admin "google.golang.org/api/admin/directory/v1"
+	reports "google.golang.org/api/admin/reports/v1"
+	groupssettings "google.golang.org/api/groupssettings/v1"
+	"google.golang.org/api/option"
+)
+
+// DefaultCustomerID refers to the customer of the authenticated admin
+const DefaultCustomerID = "my_customer"
+
+// DefaultFormerEmployeesOrgUnit is where offboarded users are moved
+const DefaultFormerEmployeesOrgUnit = "/Former employees"
+
+// DefaultStaffOrgUnits lists the OUs whose users count as staff, rather than
+// contractors, in group audits
+var DefaultStaffOrgUnits = []string{
+	"/",
+	"/Customer Education",
+	"/Customer Success",
+	"/Engineering",
+	"/Marketing",
+	"/Product",
+	"/Sales",
+	"/Sales Engineering",
+}
+
+// Config holds the tenant settings the services need
+type Config struct {
+	// Domain is appended to group names given without one. Group members
+	// outside it count as external in audits.
+	Domain string
+
+	// CustomerID selects the tenant; defaults to DefaultCustomerID. Only
+	// used by New: a Directory passed to NewWithServices has its own.
+	CustomerID string
+
+	// FormerEmployeesOrgUnit defaults to DefaultFormerEmployeesOrgUnit
+	FormerEmployeesOrgUnit string
+
+	// StaffOrgUnits defaults to DefaultStaffOrgUnits
+	StaffOrgUnits []string
+}
+
+func (c Config) withDefaults() Config {
+	if c.CustomerID == "" {
+		c.CustomerID = DefaultCustomerID
+	}
+	if c.FormerEmployeesOrgUnit == "" {
+		c.FormerEmployeesOrgUnit = DefaultFormerEmployeesOrgUnit
+	}
+	if c.StaffOrgUnits == nil {
+		c.StaffOrgUnits = DefaultStaffOrgUnits
+	}
+	return c
+}
+
+// groupEmail turns a group name into an address in the configured domain;
+// addresses are returned unchanged
+func (c Config) groupEmail(group string) string {
+	if strings.Contains(group, "@") {
+		return group
+	}
+	return group + "@" + c.Domain
+}
+
+// Client groups the services. Create one with New or NewWithServices.
+type Client struct {
+	Users     *UserService
+	Groups    *GroupService
+	OrgUnits  *OUService
+	Resources *ResourceService
+	Audit     *AuditService
+}
+
+// Services are the API clients a Client is built on. A nil Directory leaves
+// Users, Groups, OrgUnits and Resources nil; a nil Reports leaves Audit nil.
+type Services struct {
+	Directory Directory
+	Reports   *reports.Service
+}
+
+// New creates the Google API services with opts and returns a Client over
+// them. opts typically carries option.WithHTTPClient or
+// option.WithCredentialsFile; use NewWithServices to configure each service
+// separately.
+func New(ctx context.Context, cfg Config, opts ...option.ClientOption) (*Client, error) {
+	cfg = cfg.withDefaults()
+
+	adminSrv, err := admin.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create admin directory service: %w", err)
+	}
+	settingsSrv, err := groupssettings.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create groups settings service: %w", err)
+	}
+	reportsSrv, err := reports.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create reports service: %w", err)
+	}
+
+	return NewWithServices(cfg, Services{
+		Directory: NewDirectory(adminSrv, settingsSrv, cfg.CustomerID),
+		Reports:   reportsSrv,
+	}), nil
+}
+
+// NewWithServices returns a Client over existing API clients
+func NewWithServices(cfg Config, s Services) *Client {
+	cfg = cfg.withDefaults()
+	c := &Client{}
+	if s.Directory != nil {
+		c.Users = &UserService{dir: s.Directory, cfg: cfg}
+		c.Groups = &GroupService{dir: s.Directory, cfg: cfg}
+		c.OrgUnits = &OUService{dir: s.Directory}
+		c.Resources = &ResourceService{dir: s.Directory}
+	}
+	if s.Reports != nil {
+		c.Audit = &AuditService{reports: s.Reports}
+	}
+	return c
+}
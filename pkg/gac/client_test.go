@@ -0,0 +1,149 @@
+package gac
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/acockrell/google-admin-client/pkg/gactest"
+	admin "google.golang.org/api/admin/directory/v1"
+	reports "google.golang.org/api/admin/reports/v1"
+	groupssettings "google.golang.org/api/groupssettings/v1"
+	"google.golang.org/api/option"
+)
+
+// testWorkspace seeds the fake Workspace the service tests run against
+const testWorkspace = `
+orgUnits:
+  - name: Engineering
+    parentOrgUnitPath: /
+  - name: Former employees
+    parentOrgUnitPath: /
+
+users:
+  - primaryEmail: alice@example.com
+    name: {givenName: Alice, familyName: Smith}
+    orgUnitPath: /Engineering
+  - primaryEmail: bob@example.com
+    name: {givenName: Bob, familyName: Jones}
+    orgUnitPath: /Engineering
+    externalIds: [{type: organization, value: "1234"}]
+  - primaryEmail: carol@example.com
+    name: {givenName: Carol, familyName: White}
+    orgUnitPath: /Former employees
+
+groups:
+  - email: engineering@example.com
+    name: Engineering
+    members:
+      - {email: alice@example.com, role: OWNER}
+      - {email: bob@example.com}
+  - email: alumni@example.com
+    name: Alumni
+    members:
+      - {email: carol@example.com}
+      - {email: friend@partner.example}
+  - email: all@example.com
+    name: Everyone
+    members:
+      - {email: engineering@example.com, type: GROUP}
+      - {email: alice@example.com}
+      - {email: carol@example.com}
+
+buildings:
+  - {buildingId: hq, buildingName: Headquarters}
+  - {buildingId: annex, buildingName: Annex}
+
+calendarResources:
+  - {resourceId: room-1, resourceName: Boardroom, resourceType: ROOM, buildingId: hq}
+  - {resourceId: room-2, resourceName: Huddle, resourceType: ROOM, buildingId: annex}
+  - {resourceId: projector-1, resourceName: Projector, resourceType: EQUIPMENT}
+
+activities:
+  - id: {applicationName: login, time: "2026-01-05T08:00:00Z", uniqueQualifier: "1"}
+    actor: {email: alice@example.com}
+    events: [{name: login_success}]
+  - id: {applicationName: login, time: "2026-01-06T08:00:00Z", uniqueQualifier: "2"}
+    actor: {email: bob@example.com}
+    events: [{name: login_failure}]
+`
+
+// newTestClient returns a Client for a fake Workspace seeded with
+// testWorkspace
+func newTestClient(t *testing.T) (*Client, *gactest.Server) {
+	t.Helper()
+	srv := gactest.NewServer(nil)
+	t.Cleanup(srv.Close)
+	if err := srv.Load([]byte(testWorkspace)); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	ctx := context.Background()
+	opts := func(path string) []option.ClientOption {
+		return []option.ClientOption{
+			option.WithEndpoint(srv.URL + "/" + path),
+			option.WithHTTPClient(http.DefaultClient),
+		}
+	}
+	adminSrv, err := admin.NewService(ctx, opts("")...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	settingsSrv, err := groupssettings.NewService(ctx, opts("groups/v1/groups/")...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reportsSrv, err := reports.NewService(ctx, opts("")...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := NewWithServices(Config{Domain: "example.com"}, Services{
+		Directory: NewDirectory(adminSrv, settingsSrv, ""),
+		Reports:   reportsSrv,
+	})
+	return client, srv
+}
+
+func TestNewWithServices(t *testing.T) {
+	c := NewWithServices(Config{}, Services{})
+	if c.Users != nil || c.Groups != nil || c.OrgUnits != nil || c.Resources != nil || c.Audit != nil {
+		t.Errorf("NewWithServices() without services = %+v, want no services", c)
+	}
+
+	client, _ := newTestClient(t)
+	if client.Users == nil || client.Groups == nil || client.OrgUnits == nil || client.Resources == nil || client.Audit == nil {
+		t.Errorf("NewWithServices() = %+v, want every service", client)
+	}
+	if got := client.Users.cfg.FormerEmployeesOrgUnit; got != DefaultFormerEmployeesOrgUnit {
+		t.Errorf("FormerEmployeesOrgUnit = %q, want the default", got)
+	}
+}
+
+func TestConfigGroupEmail(t *testing.T) {
+	cfg := Config{Domain: "example.com"}
+	tests := []struct {
+		group string
+		want  string
+	}{
+		{"dev", "dev@example.com"},
+		{"dev@other.example", "dev@other.example"},
+	}
+	for _, tt := range tests {
+		if got := cfg.groupEmail(tt.group); got != tt.want {
+			t.Errorf("groupEmail(%q) = %q, want %q", tt.group, got, tt.want)
+		}
+	}
+}
+
+// toJSON encodes v for comparisons; the API decodes some list fields, such
+// as ExternalIds, into untyped values
+func toJSON(t *testing.T, v interface{}) string {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
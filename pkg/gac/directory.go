@@ -0,0 +1,201 @@
+package gac
+
+import (
+	"context"
+
+	admin "google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/googleapi"
+	groupssettings "google.golang.org/api/groupssettings/v1"
+)
+
+// Directory is every Directory and Groups Settings API call the services
+// make. NewDirectory returns the implementation backed by the Google API
+// clients; tests can supply their own. Customer-scoped calls use the
+// customer ID the Directory was created with, and list calls that the API
+// pages take a page token ("" for the first page).
+type Directory interface {
+	// Users
+	GetUser(ctx context.Context, userKey string, opts ...googleapi.CallOption) (*admin.User, error)
+	ListUsers(ctx context.Context, pageToken string) (*admin.Users, error)
+	InsertUser(ctx context.Context, user *admin.User) (*admin.User, error)
+	UpdateUser(ctx context.Context, userKey string, user *admin.User) (*admin.User, error)
+
+	// Aliases
+	ListAliases(ctx context.Context, userKey string) (*admin.Aliases, error)
+	InsertAlias(ctx context.Context, userKey string, alias *admin.Alias) (*admin.Alias, error)
+	DeleteAlias(ctx context.Context, userKey, alias string) error
+
+	// Groups
+	GetGroup(ctx context.Context, groupKey string) (*admin.Group, error)
+	ListGroups(ctx context.Context, pageToken string) (*admin.Groups, error)
+	ListUserGroups(ctx context.Context, userKey, pageToken string) (*admin.Groups, error)
+
+	// Members
+	ListMembers(ctx context.Context, groupKey, pageToken string) (*admin.Members, error)
+	InsertMember(ctx context.Context, groupKey string, member *admin.Member) (*admin.Member, error)
+	DeleteMember(ctx context.Context, groupKey, memberKey string) error
+
+	// Organizational units; listType is "all" or "children"
+	ListOrgUnits(ctx context.Context, orgUnitPath, listType string) (*admin.OrgUnits, error)
+	InsertOrgUnit(ctx context.Context, ou *admin.OrgUnit) (*admin.OrgUnit, error)
+	UpdateOrgUnit(ctx context.Context, orgUnitPath string, ou *admin.OrgUnit) (*admin.OrgUnit, error)
+	DeleteOrgUnit(ctx context.Context, orgUnitPath string) error
+
+	// Calendar resources
+	GetCalendarResource(ctx context.Context, resourceID string) (*admin.CalendarResource, error)
+	ListCalendarResources(ctx context.Context, pageToken string) (*admin.CalendarResources, error)
+	InsertCalendarResource(ctx context.Context, resource *admin.CalendarResource) (*admin.CalendarResource, error)
+	UpdateCalendarResource(ctx context.Context, resourceID string, resource *admin.CalendarResource) (*admin.CalendarResource, error)
+	DeleteCalendarResource(ctx context.Context, resourceID string) error
+
+	// Buildings
+	ListBuildings(ctx context.Context, pageToken string) (*admin.Buildings, error)
+
+	// Group settings
+	GetGroupSettings(ctx context.Context, groupEmail string) (*groupssettings.Groups, error)
+	UpdateGroupSettings(ctx context.Context, groupEmail string, settings *groupssettings.Groups) (*groupssettings.Groups, error)
+}
+
+// directory implements Directory with the Google API clients
+type directory struct {
+	admin      *admin.Service
+	settings   *groupssettings.Service
+	customerID string
+}
+
+// NewDirectory returns a Directory backed by the Directory and Groups
+// Settings services. An empty customerID means DefaultCustomerID.
+func NewDirectory(adminSrv *admin.Service, settingsSrv *groupssettings.Service, customerID string) Directory {
+	if customerID == "" {
+		customerID = DefaultCustomerID
+	}
+	return &directory{admin: adminSrv, settings: settingsSrv, customerID: customerID}
+}
+
+func (d *directory) GetUser(ctx context.Context, userKey string, opts ...googleapi.CallOption) (*admin.User, error) {
+	return d.admin.Users.Get(userKey).Context(ctx).Do(opts...)
+}
+
+func (d *directory) ListUsers(ctx context.Context, pageToken string) (*admin.Users, error) {
+	call := d.admin.Users.List().Customer(d.customerID)
+	if pageToken != "" {
+		call = call.PageToken(pageToken)
+	}
+	return call.Context(ctx).Do()
+}
+
+func (d *directory) InsertUser(ctx context.Context, user *admin.User) (*admin.User, error) {
+	return d.admin.Users.Insert(user).Context(ctx).Do()
+}
+
+func (d *directory) UpdateUser(ctx context.Context, userKey string, user *admin.User) (*admin.User, error) {
+	return d.admin.Users.Update(userKey, user).Context(ctx).Do()
+}
+
+func (d *directory) ListAliases(ctx context.Context, userKey string) (*admin.Aliases, error) {
+	return d.admin.Users.Aliases.List(userKey).Context(ctx).Do()
+}
+
+func (d *directory) InsertAlias(ctx context.Context, userKey string, alias *admin.Alias) (*admin.Alias, error) {
+	return d.admin.Users.Aliases.Insert(userKey, alias).Context(ctx).Do()
+}
+
+func (d *directory) DeleteAlias(ctx context.Context, userKey, alias string) error {
+	return d.admin.Users.Aliases.Delete(userKey, alias).Context(ctx).Do()
+}
+
+func (d *directory) GetGroup(ctx context.Context, groupKey string) (*admin.Group, error) {
+	return d.admin.Groups.Get(groupKey).Context(ctx).Do()
+}
+
+func (d *directory) ListGroups(ctx context.Context, pageToken string) (*admin.Groups, error) {
+	call := d.admin.Groups.List().Customer(d.customerID)
+	if pageToken != "" {
+		call = call.PageToken(pageToken)
+	}
+	return call.Context(ctx).Do()
+}
+
+func (d *directory) ListUserGroups(ctx context.Context, userKey, pageToken string) (*admin.Groups, error) {
+	call := d.admin.Groups.List().UserKey(userKey)
+	if pageToken != "" {
+		call = call.PageToken(pageToken)
+	}
+	return call.Context(ctx).Do()
+}
+
+func (d *directory) ListMembers(ctx context.Context, groupKey, pageToken string) (*admin.Members, error) {
+	call := d.admin.Members.List(groupKey)
+	if pageToken != "" {
+		call = call.PageToken(pageToken)
+	}
+	return call.Context(ctx).Do()
+}
+
+func (d *directory) InsertMember(ctx context.Context, groupKey string, member *admin.Member) (*admin.Member, error) {
+	return d.admin.Members.Insert(groupKey, member).Context(ctx).Do()
+}
+
+func (d *directory) DeleteMember(ctx context.Context, groupKey, memberKey string) error {
+	return d.admin.Members.Delete(groupKey, memberKey).Context(ctx).Do()
+}
+
+func (d *directory) ListOrgUnits(ctx context.Context, orgUnitPath, listType string) (*admin.OrgUnits, error) {
+	call := d.admin.Orgunits.List(d.customerID).Type(listType)
+	if orgUnitPath != "" {
+		call = call.OrgUnitPath(orgUnitPath)
+	}
+	return call.Context(ctx).Do()
+}
+
+func (d *directory) InsertOrgUnit(ctx context.Context, ou *admin.OrgUnit) (*admin.OrgUnit, error) {
+	return d.admin.Orgunits.Insert(d.customerID, ou).Context(ctx).Do()
+}
+
+func (d *directory) UpdateOrgUnit(ctx context.Context, orgUnitPath string, ou *admin.OrgUnit) (*admin.OrgUnit, error) {
+	return d.admin.Orgunits.Update(d.customerID, orgUnitPath, ou).Context(ctx).Do()
+}
+
+func (d *directory) DeleteOrgUnit(ctx context.Context, orgUnitPath string) error {
+	return d.admin.Orgunits.Delete(d.customerID, orgUnitPath).Context(ctx).Do()
+}
+
+func (d *directory) GetCalendarResource(ctx context.Context, resourceID string) (*admin.CalendarResource, error) {
+	return d.admin.Resources.Calendars.Get(d.customerID, resourceID).Context(ctx).Do()
+}
+
+func (d *directory) ListCalendarResources(ctx context.Context, pageToken string) (*admin.CalendarResources, error) {
+	call := d.admin.Resources.Calendars.List(d.customerID)
+	if pageToken != "" {
+		call = call.PageToken(pageToken)
+	}
+	return call.Context(ctx).Do()
+}
+
+func (d *directory) InsertCalendarResource(ctx context.Context, resource *admin.CalendarResource) (*admin.CalendarResource, error) {
+	return d.admin.Resources.Calendars.Insert(d.customerID, resource).Context(ctx).Do()
+}
+
+func (d *directory) UpdateCalendarResource(ctx context.Context, resourceID string, resource *admin.CalendarResource) (*admin.CalendarResource, error) {
+	return d.admin.Resources.Calendars.Update(d.customerID, resourceID, resource).Context(ctx).Do()
+}
+
+func (d *directory) DeleteCalendarResource(ctx context.Context, resourceID string) error {
+	return d.admin.Resources.Calendars.Delete(d.customerID, resourceID).Context(ctx).Do()
+}
+
+func (d *directory) ListBuildings(ctx context.Context, pageToken string) (*admin.Buildings, error) {
+	call := d.admin.Resources.Buildings.List(d.customerID)
+	if pageToken != "" {
+		call = call.PageToken(pageToken)
+	}
+	return call.Context(ctx).Do()
+}
+
+func (d *directory) GetGroupSettings(ctx context.Context, groupEmail string) (*groupssettings.Groups, error) {
+	return d.settings.Groups.Get(groupEmail).Context(ctx).Do()
+}
+
+func (d *directory) UpdateGroupSettings(ctx context.Context, groupEmail string, settings *groupssettings.Groups) (*groupssettings.Groups, error) {
+	return d.settings.Groups.Update(groupEmail, settings).Context(ctx).Do()
+}
@@ -0,0 +1,80 @@
+package gac
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"google.golang.org/api/googleapi"
+)
+
+// Error kinds. Classify maps Google API errors onto them; errors gac creates
+// itself, such as ErrNoChanges, wrap one and match with errors.Is.
+var (
+	ErrInvalid     = errors.New("invalid request")
+	ErrNotFound    = errors.New("not found")
+	ErrConflict    = errors.New("conflict")
+	ErrForbidden   = errors.New("permission denied")
+	ErrRateLimited = errors.New("rate limit exceeded")
+)
+
+var errorKinds = []error{ErrInvalid, ErrNotFound, ErrConflict, ErrForbidden, ErrRateLimited}
+
+// rateLimitReasons are the error reasons Google returns with a 403 when a
+// quota, rather than a permission, stops a request
+var rateLimitReasons = map[string]bool{
+	"rateLimitExceeded":     true,
+	"userRateLimitExceeded": true,
+	"quotaExceeded":         true,
+	"dailyLimitExceeded":    true,
+}
+
+// Classify returns the kind of err: one of the Err* values above, or nil if
+// it has none
+func Classify(err error) error {
+	if err == nil {
+		return nil
+	}
+	for _, kind := range errorKinds {
+		if errors.Is(err, kind) {
+			return kind
+		}
+	}
+
+	var gerr *googleapi.Error
+	if !errors.As(err, &gerr) {
+		return nil
+	}
+	for _, item := range gerr.Errors {
+		if rateLimitReasons[item.Reason] {
+			return ErrRateLimited
+		}
+	}
+	switch gerr.Code {
+	case http.StatusBadRequest:
+		return ErrInvalid
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrForbidden
+	case http.StatusNotFound, http.StatusGone:
+		return ErrNotFound
+	case http.StatusConflict, http.StatusPreconditionFailed:
+		return ErrConflict
+	case http.StatusTooManyRequests:
+		return ErrRateLimited
+	}
+	return nil
+}
+
+// invalidError is an ErrInvalid with its own message
+type invalidError struct {
+	msg string
+}
+
+func (e *invalidError) Error() string { return e.msg }
+
+func (e *invalidError) Is(target error) bool { return target == ErrInvalid }
+
+// invalidf returns an ErrInvalid formatted like fmt.Sprintf
+func invalidf(format string, a ...interface{}) error {
+	return &invalidError{msg: fmt.Sprintf(format, a...)}
+}
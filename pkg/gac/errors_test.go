@@ -0,0 +1,63 @@
+package gac
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"google.golang.org/api/googleapi"
+)
+
+func TestClassify(t *testing.T) {
+	apiError := func(code int, reason string) error {
+		gerr := &googleapi.Error{Code: code}
+		if reason != "" {
+			gerr.Errors = []googleapi.ErrorItem{{Reason: reason}}
+		}
+		return fmt.Errorf("wrapped: %w", gerr)
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "nil", err: nil, want: nil},
+		{name: "plain", err: errors.New("boom"), want: nil},
+		{name: "400", err: apiError(400, "invalid"), want: ErrInvalid},
+		{name: "401", err: apiError(401, ""), want: ErrForbidden},
+		{name: "403", err: apiError(403, "forbidden"), want: ErrForbidden},
+		{name: "403 rate limit", err: apiError(403, "userRateLimitExceeded"), want: ErrRateLimited},
+		{name: "404", err: apiError(404, "notFound"), want: ErrNotFound},
+		{name: "409", err: apiError(409, "duplicate"), want: ErrConflict},
+		{name: "412", err: apiError(412, ""), want: ErrConflict},
+		{name: "429", err: apiError(429, ""), want: ErrRateLimited},
+		{name: "500", err: apiError(500, "backendError"), want: nil},
+		{name: "kind", err: fmt.Errorf("lookup: %w", ErrNotFound), want: ErrNotFound},
+		{name: "no changes", err: ErrNoChanges, want: ErrInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Classify(tt.err); got != tt.want {
+				t.Errorf("Classify(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServiceErrorKinds(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx := context.Background()
+
+	_, err := client.Users.Get(ctx, "nobody@example.com", GetUserOptions{})
+	if !errors.Is(Classify(err), ErrNotFound) {
+		t.Errorf("Users.Get(missing) error = %v, want ErrNotFound", err)
+	}
+
+	_, err = client.Audit.Activities(ctx, ActivityQuery{Application: "bogus"})
+	if !errors.Is(err, ErrInvalid) {
+		t.Errorf("Activities(bogus) error = %v, want ErrInvalid", err)
+	}
+}
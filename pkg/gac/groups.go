@@ -0,0 +1,198 @@
+package gac
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"strings"
+	"sync"
+
+	admin "google.golang.org/api/admin/directory/v1"
+)
+
+// GroupService reads groups and audits their membership
+type GroupService struct {
+	dir Directory
+	cfg Config
+}
+
+// Get returns a group by address, or by name in Config.Domain
+func (s *GroupService) Get(ctx context.Context, group string) (*admin.Group, error) {
+	return s.dir.GetGroup(ctx, s.cfg.groupEmail(group))
+}
+
+// List returns every group in the tenant, following page tokens. If a page
+// fails, the groups fetched so far are returned with the error.
+func (s *GroupService) List(ctx context.Context) ([]*admin.Group, error) {
+	return collect(s.All(ctx))
+}
+
+// All yields every group in the tenant as each page arrives. A failed page
+// ends the sequence with its error.
+func (s *GroupService) All(ctx context.Context) iter.Seq2[*admin.Group, error] {
+	return paginate(func(pageToken string) ([]*admin.Group, string, error) {
+		res, err := s.dir.ListGroups(ctx, pageToken)
+		if err != nil {
+			return nil, "", err
+		}
+		return res.Groups, res.NextPageToken, nil
+	})
+}
+
+// Members returns every member of a group, following page tokens
+func (s *GroupService) Members(ctx context.Context, group string) ([]*admin.Member, error) {
+	members, err := collect(paginate(func(pageToken string) ([]*admin.Member, string, error) {
+		res, err := s.dir.ListMembers(ctx, s.cfg.groupEmail(group), pageToken)
+		if err != nil {
+			return nil, "", err
+		}
+		return res.Members, res.NextPageToken, nil
+	}))
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// Member status values reported by MemberStatuses
+const (
+	MemberActive = "active"
+	MemberGroup  = "group"
+	MemberFormer = "former"
+)
+
+// MemberStatus is a group member and whether it is a nested group, an
+// active user or a former employee
+type MemberStatus struct {
+	Email  string `json:"email"`
+	Type   string `json:"type"`
+	Status string `json:"status"`
+}
+
+// MemberStatuses returns the members of a group with their status. Users
+// that cannot be looked up are left out and their errors returned together
+// with the other members.
+func (s *GroupService) MemberStatuses(ctx context.Context, group string) ([]MemberStatus, error) {
+	members, err := s.Members(ctx, group)
+	if err != nil {
+		return nil, err
+	}
+
+	var statuses []MemberStatus
+	var errs []error
+	for _, m := range members {
+		status := MemberActive
+		if m.Type == "GROUP" {
+			status = MemberGroup
+		} else if m.Type == "USER" {
+			u, err := s.dir.GetUser(ctx, m.Email)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("unable to get user %s: %w", m.Email, err))
+				continue
+			}
+			if u.OrgUnitPath == s.cfg.FormerEmployeesOrgUnit {
+				status = MemberFormer
+			}
+		}
+		statuses = append(statuses, MemberStatus{Email: m.Email, Type: m.Type, Status: status})
+	}
+	return statuses, errors.Join(errs...)
+}
+
+// GroupAuditOptions filters an audit
+type GroupAuditOptions struct {
+	// FormerEmployeesOnly keeps only groups with former employees as members
+	FormerEmployeesOnly bool
+}
+
+// GroupAudit summarises who is in a group
+type GroupAudit struct {
+	Group *admin.Group
+
+	// Owners are the members with a role other than MEMBER
+	Owners []string
+
+	// ExternalMembers is set when a member is outside Config.Domain or is
+	// a user outside the staff OUs
+	ExternalMembers bool
+
+	// FormerEmployees is set when a member is in the former employees OU
+	FormerEmployees bool
+}
+
+// Audit checks the membership of each group concurrently. Results keep the
+// order of groups. Groups whose members cannot be listed are left out, and
+// their errors returned together with the other results.
+func (s *GroupService) Audit(ctx context.Context, groups []*admin.Group, opts GroupAuditOptions) ([]GroupAudit, error) {
+	results := make([]*GroupAudit, len(groups))
+	errs := make([]error, len(groups))
+
+	var wg sync.WaitGroup
+	for i, g := range groups {
+		wg.Add(1)
+		go func(i int, g *admin.Group) {
+			defer wg.Done()
+			results[i], errs[i] = s.audit(ctx, g)
+		}(i, g)
+	}
+	wg.Wait()
+
+	var audits []GroupAudit
+	for _, r := range results {
+		if r == nil || (opts.FormerEmployeesOnly && !r.FormerEmployees) {
+			continue
+		}
+		audits = append(audits, *r)
+	}
+	return audits, errors.Join(errs...)
+}
+
+// audit checks one group. A user lookup failure skips that member and is
+// reported with the result.
+func (s *GroupService) audit(ctx context.Context, group *admin.Group) (*GroupAudit, error) {
+	members, err := s.Members(ctx, group.Email)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list members of %s: %w", group.Email, err)
+	}
+
+	result := &GroupAudit{Group: group}
+	var errs []error
+	for _, m := range members {
+		if s.cfg.Domain != "" && !strings.HasSuffix(m.Email, "@"+s.cfg.Domain) {
+			result.ExternalMembers = true
+			continue
+		}
+
+		if m.Role != "MEMBER" {
+			result.Owners = append(result.Owners, m.Email)
+		}
+
+		if m.Type == "USER" {
+			u, err := s.dir.GetUser(ctx, m.Email)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				errs = append(errs, fmt.Errorf("unable to get user %s: %w", m.Email, err))
+				continue
+			}
+			if !s.isStaff(u.OrgUnitPath) {
+				result.ExternalMembers = true
+			}
+			if u.OrgUnitPath == s.cfg.FormerEmployeesOrgUnit {
+				result.FormerEmployees = true
+			}
+		}
+	}
+	return result, errors.Join(errs...)
+}
+
+func (s *GroupService) isStaff(orgUnitPath string) bool {
+	for _, ou := range s.cfg.StaffOrgUnits {
+		if ou == orgUnitPath {
+			return true
+		}
+	}
+	return false
+}
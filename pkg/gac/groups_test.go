@@ -0,0 +1,97 @@
+package gac
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestGroupServiceList(t *testing.T) {
+	client, srv := newTestClient(t)
+	srv.PageSize = 1
+
+	groups, err := client.Groups.List(context.Background())
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(groups) != 3 {
+		t.Errorf("List() returned %d groups across pages, want 3", len(groups))
+	}
+}
+
+func TestGroupServiceMemberStatuses(t *testing.T) {
+	client, srv := newTestClient(t)
+	srv.PageSize = 1 // members across pages
+
+	statuses, err := client.Groups.MemberStatuses(context.Background(), "all")
+	if err != nil {
+		t.Fatalf("MemberStatuses() error = %v", err)
+	}
+	want := []MemberStatus{
+		{Email: "engineering@example.com", Type: "GROUP", Status: MemberGroup},
+		{Email: "alice@example.com", Type: "USER", Status: MemberActive},
+		{Email: "carol@example.com", Type: "USER", Status: MemberFormer},
+	}
+	if !reflect.DeepEqual(statuses, want) {
+		t.Errorf("MemberStatuses() = %+v, want %+v", statuses, want)
+	}
+
+	if _, err := client.Groups.MemberStatuses(context.Background(), "missing"); err == nil {
+		t.Error("MemberStatuses() of a missing group succeeded, want error")
+	}
+}
+
+func TestGroupServiceAudit(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx := context.Background()
+
+	groups, err := client.Groups.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		opts GroupAuditOptions
+		want map[string]GroupAudit
+	}{
+		{
+			name: "all groups",
+			want: map[string]GroupAudit{
+				"engineering@example.com": {Owners: []string{"alice@example.com"}},
+				"alumni@example.com":      {ExternalMembers: true, FormerEmployees: true},
+				"all@example.com":         {ExternalMembers: true, FormerEmployees: true},
+			},
+		},
+		{
+			name: "former employees only",
+			opts: GroupAuditOptions{FormerEmployeesOnly: true},
+			want: map[string]GroupAudit{
+				"alumni@example.com": {ExternalMembers: true, FormerEmployees: true},
+				"all@example.com":    {ExternalMembers: true, FormerEmployees: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			audits, err := client.Groups.Audit(ctx, groups, tt.opts)
+			if err != nil {
+				t.Fatalf("Audit() error = %v", err)
+			}
+			if len(audits) != len(tt.want) {
+				t.Fatalf("Audit() returned %d groups, want %d", len(audits), len(tt.want))
+			}
+			for i, a := range audits {
+				if i > 0 && a.Group.Email == audits[i-1].Group.Email {
+					t.Errorf("Audit() repeated %s", a.Group.Email)
+				}
+				want := tt.want[a.Group.Email]
+				if !reflect.DeepEqual(a.Owners, want.Owners) || a.ExternalMembers != want.ExternalMembers || a.FormerEmployees != want.FormerEmployees {
+					t.Errorf("Audit() for %s = owners %v, external %v, former %v, want %+v",
+						a.Group.Email, a.Owners, a.ExternalMembers, a.FormerEmployees, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package gac
+
+import (
+	"context"
+	"strings"
+
+	admin "google.golang.org/api/admin/directory/v1"
+)
+
+// ErrNoChanges is returned by updates that were given nothing to change. It
+// is an ErrInvalid.
+var ErrNoChanges = invalidf("no update fields specified")
+
+// OUService manages organizational units
+type OUService struct {
+	dir Directory
+}
+
+// ListOrgUnitsOptions selects the OUs to list
+type ListOrgUnitsOptions struct {
+	// Path limits the list to an OU; empty means the root
+	Path string
+
+	// Type is "all" (the default) for every descendant or "children" for
+	// direct children only
+	Type string
+}
+
+// List returns organizational units
+func (s *OUService) List(ctx context.Context, opts ListOrgUnitsOptions) ([]*admin.OrgUnit, error) {
+	listType := "all"
+	if opts.Type == "children" {
+		listType = "children"
+	}
+	result, err := s.dir.ListOrgUnits(ctx, opts.Path, listType)
+	if err != nil {
+		return nil, err
+	}
+	return result.OrganizationUnits, nil
+}
+
+// CreateOrgUnitOptions describes a new OU
+type CreateOrgUnitOptions struct {
+	// Path is the full path of the new OU, e.g. /Engineering/Backend. The
+	// last segment is its name.
+	Path string
+
+	// Parent overrides the parent derived from Path
+	Parent string
+
+	Description      string
+	BlockInheritance bool
+}
+
+// Create adds an organizational unit. The parent must already exist.
+func (s *OUService) Create(ctx context.Context, opts CreateOrgUnitOptions) (*admin.OrgUnit, error) {
+	if !strings.HasPrefix(opts.Path, "/") {
+		return nil, invalidf("invalid OU path %q: must start with '/'", opts.Path)
+	}
+
+	// The name is the last segment; the parent is the rest unless given
+	segments := strings.Split(strings.Trim(opts.Path, "/"), "/")
+	name := segments[len(segments)-1]
+
+	parent := opts.Parent
+	if parent == "" {
+		parent = "/" + strings.Join(segments[:len(segments)-1], "/")
+	}
+
+	return s.dir.InsertOrgUnit(ctx, &admin.OrgUnit{
+		Name:              name,
+		ParentOrgUnitPath: parent,
+		Description:       opts.Description,
+		BlockInheritance:  opts.BlockInheritance,
+	})
+}
+
+// UpdateOrgUnitOptions lists the OU fields to change; empty fields are left
+// alone
+type UpdateOrgUnitOptions struct {
+	Name        string
+	Description string
+	Parent      string
+
+	// BlockInheritance is left alone when nil
+	BlockInheritance *bool
+}
+
+// Update changes an organizational unit. It returns ErrNoChanges if opts
+// sets nothing.
+func (s *OUService) Update(ctx context.Context, path string, opts UpdateOrgUnitOptions) (*admin.OrgUnit, error) {
+	ou := &admin.OrgUnit{
+		Name:              opts.Name,
+		Description:       opts.Description,
+		ParentOrgUnitPath: opts.Parent,
+	}
+	if opts.BlockInheritance != nil {
+		ou.BlockInheritance = *opts.BlockInheritance
+		ou.ForceSendFields = append(ou.ForceSendFields, "BlockInheritance")
+	}
+	if ou.Name == "" && ou.Description == "" && ou.ParentOrgUnitPath == "" && opts.BlockInheritance == nil {
+		return nil, ErrNoChanges
+	}
+
+	// The API takes the full path or the OU ID
+	return s.dir.UpdateOrgUnit(ctx, path, ou)
+}
+
+// Delete removes an organizational unit. The API refuses OUs that still
+// contain users or child OUs.
+func (s *OUService) Delete(ctx context.Context, path string) error {
+	return s.dir.DeleteOrgUnit(ctx, path)
+}
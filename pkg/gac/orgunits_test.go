@@ -0,0 +1,56 @@
+package gac
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestOUService(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx := context.Background()
+
+	if _, err := client.OrgUnits.Create(ctx, CreateOrgUnitOptions{Path: "Engineering/Backend"}); err == nil {
+		t.Error("Create() of a relative path succeeded, want error")
+	}
+
+	ou, err := client.OrgUnits.Create(ctx, CreateOrgUnitOptions{Path: "/Engineering/Backend", Description: "Backend team"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if ou.Name != "Backend" || ou.ParentOrgUnitPath != "/Engineering" || ou.OrgUnitPath != "/Engineering/Backend" {
+		t.Errorf("Create() = %s in %s at %s, want Backend in /Engineering", ou.Name, ou.ParentOrgUnitPath, ou.OrgUnitPath)
+	}
+
+	children, err := client.OrgUnits.List(ctx, ListOrgUnitsOptions{Path: "/Engineering", Type: "children"})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(children) != 1 || children[0].Description != "Backend team" {
+		t.Errorf("List(children) = %+v, want the new OU", children)
+	}
+
+	if _, err := client.OrgUnits.Update(ctx, "/Engineering/Backend", UpdateOrgUnitOptions{}); !errors.Is(err, ErrNoChanges) {
+		t.Errorf("Update() with no changes error = %v, want ErrNoChanges", err)
+	}
+
+	block := true
+	ou, err = client.OrgUnits.Update(ctx, "/Engineering/Backend", UpdateOrgUnitOptions{Description: "Services", BlockInheritance: &block})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if ou.Description != "Services" || !ou.BlockInheritance {
+		t.Errorf("Update() = %q, block %v, want the new description and blocked inheritance", ou.Description, ou.BlockInheritance)
+	}
+
+	if err := client.OrgUnits.Delete(ctx, "/Engineering/Backend"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	all, err := client.OrgUnits.List(ctx, ListOrgUnitsOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 {
+		t.Errorf("List() after Delete() = %d OUs, want 2", len(all))
+	}
+}
@@ -0,0 +1,64 @@
+package gac
+
+import "iter"
+
+// pageFunc fetches one page: the items and the token of the next page, ""
+// after the last. The first page is requested with an empty token.
+type pageFunc[T any] func(pageToken string) ([]T, string, error)
+
+// paginate yields the items of every page in order. The next page is only
+// requested once the previous one has been consumed, so stopping early
+// saves API calls. An error is yielded once and ends the sequence.
+func paginate[T any](fetch pageFunc[T]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var pageToken string
+		for {
+			items, next, err := fetch(pageToken)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if next == "" {
+				return
+			}
+			pageToken = next
+		}
+	}
+}
+
+// limit stops seq after n items; n <= 0 means no limit
+func limit[T any](seq iter.Seq2[T, error], n int64) iter.Seq2[T, error] {
+	if n <= 0 {
+		return seq
+	}
+	return func(yield func(T, error) bool) {
+		var count int64
+		for item, err := range seq {
+			if !yield(item, err) || err != nil {
+				return
+			}
+			if count++; count >= n {
+				return
+			}
+		}
+	}
+}
+
+// collect gathers the items of seq. If it yields an error, the items
+// before it are returned with the error.
+func collect[T any](seq iter.Seq2[T, error]) ([]T, error) {
+	var items []T
+	for item, err := range seq {
+		if err != nil {
+			return items, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
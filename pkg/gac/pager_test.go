@@ -0,0 +1,76 @@
+package gac
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// pagesOf serves pages as a pageFunc, failing at page failAt (1-based) if
+// it is set, and counts the pages requested
+func pagesOf(pages [][]int, failAt int, requested *int) pageFunc[int] {
+	return func(pageToken string) ([]int, string, error) {
+		i := 0
+		if pageToken != "" {
+			i, _ = strconv.Atoi(pageToken)
+		}
+		*requested++
+		if i+1 == failAt {
+			return nil, "", errors.New("page failed")
+		}
+		next := ""
+		if i+1 < len(pages) {
+			next = strconv.Itoa(i + 1)
+		}
+		return pages[i], next, nil
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	pages := [][]int{{1, 2}, {3}, {4, 5}}
+
+	tests := []struct {
+		name      string
+		failAt    int
+		limit     int64
+		want      []int
+		wantPages int
+		wantErr   bool
+	}{
+		{name: "all pages", want: []int{1, 2, 3, 4, 5}, wantPages: 3},
+		{name: "limit stops fetching", limit: 2, want: []int{1, 2}, wantPages: 1},
+		{name: "limit across pages", limit: 3, want: []int{1, 2, 3}, wantPages: 2},
+		{name: "limit above total", limit: 10, want: []int{1, 2, 3, 4, 5}, wantPages: 3},
+		{name: "partial results on error", failAt: 2, want: []int{1, 2}, wantPages: 2, wantErr: true},
+		{name: "error on first page", failAt: 1, wantPages: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requested int
+			got, err := collect(limit(paginate(pagesOf(pages, tt.failAt, &requested)), tt.limit))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("collect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("collect() = %v, want %v", got, tt.want)
+			}
+			if requested != tt.wantPages {
+				t.Errorf("requested %d pages, want %d", requested, tt.wantPages)
+			}
+		})
+	}
+}
+
+func TestPaginateStopsEarly(t *testing.T) {
+	var requested int
+	for item := range paginate(pagesOf([][]int{{1, 2}, {3}}, 0, &requested)) {
+		if item == 2 {
+			break
+		}
+	}
+	if requested != 1 {
+		t.Errorf("requested %d pages after breaking on the first, want 1", requested)
+	}
+}
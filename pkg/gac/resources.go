@@ -0,0 +1,43 @@
+package gac
+
+import (
+	"context"
+	"iter"
+
+	admin "google.golang.org/api/admin/directory/v1"
+)
+
+// ResourceService reads calendar resources and the buildings they are in
+type ResourceService struct {
+	dir Directory
+}
+
+// List returns every calendar resource, following page tokens. If a page
+// fails, the resources fetched so far are returned with the error.
+func (s *ResourceService) List(ctx context.Context) ([]*admin.CalendarResource, error) {
+	return collect(s.All(ctx))
+}
+
+// All yields every calendar resource as each page arrives. A failed page
+// ends the sequence with its error.
+func (s *ResourceService) All(ctx context.Context) iter.Seq2[*admin.CalendarResource, error] {
+	return paginate(func(pageToken string) ([]*admin.CalendarResource, string, error) {
+		res, err := s.dir.ListCalendarResources(ctx, pageToken)
+		if err != nil {
+			return nil, "", err
+		}
+		return res.Items, res.NextPageToken, nil
+	})
+}
+
+// Buildings returns every building, following page tokens. If a page
+// fails, the buildings fetched so far are returned with the error.
+func (s *ResourceService) Buildings(ctx context.Context) ([]*admin.Building, error) {
+	return collect(paginate(func(pageToken string) ([]*admin.Building, string, error) {
+		res, err := s.dir.ListBuildings(ctx, pageToken)
+		if err != nil {
+			return nil, "", err
+		}
+		return res.Buildings, res.NextPageToken, nil
+	}))
+}
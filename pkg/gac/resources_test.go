@@ -0,0 +1,28 @@
+package gac
+
+import (
+	"context"
+	"testing"
+)
+
+func TestResourceServiceList(t *testing.T) {
+	client, srv := newTestClient(t)
+	srv.PageSize = 1
+	ctx := context.Background()
+
+	resources, err := client.Resources.List(ctx)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(resources) != 3 {
+		t.Errorf("List() returned %d resources across pages, want 3", len(resources))
+	}
+
+	buildings, err := client.Resources.Buildings(ctx)
+	if err != nil {
+		t.Fatalf("Buildings() error = %v", err)
+	}
+	if len(buildings) != 2 {
+		t.Errorf("Buildings() returned %d buildings across pages, want 2", len(buildings))
+	}
+}
@@ -0,0 +1,399 @@
+package gac
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"iter"
+	"math/big"
+
+	admin "google.golang.org/api/admin/directory/v1"
+	"google.golang.org/api/googleapi"
+)
+
+// UserService manages user accounts
+type UserService struct {
+	dir Directory
+	cfg Config
+}
+
+// GetUserOptions controls how a user is fetched
+type GetUserOptions struct {
+	// Projection is "basic", "custom" or "full"; "full" includes custom
+	// schema attributes. Empty leaves it to the API.
+	Projection string
+}
+
+// Get returns a user by email, alias or ID
+func (s *UserService) Get(ctx context.Context, userKey string, opts GetUserOptions) (*admin.User, error) {
+	var callOpts []googleapi.CallOption
+	if opts.Projection != "" {
+		callOpts = append(callOpts, Projection(opts.Projection))
+	}
+	return s.dir.GetUser(ctx, userKey, callOpts...)
+}
+
+// ListUsersOptions filters the user list
+type ListUsersOptions struct {
+	// FormerEmployeesOnly keeps only users in the former employees OU
+	FormerEmployeesOnly bool
+}
+
+// List returns every user in the tenant, following page tokens. If a page
+// fails, the users fetched so far are returned with the error.
+func (s *UserService) List(ctx context.Context, opts ListUsersOptions) ([]*admin.User, error) {
+	return collect(s.All(ctx, opts))
+}
+
+// All yields every user in the tenant as each page arrives. A failed page
+// ends the sequence with its error.
+func (s *UserService) All(ctx context.Context, opts ListUsersOptions) iter.Seq2[*admin.User, error] {
+	users := paginate(func(pageToken string) ([]*admin.User, string, error) {
+		res, err := s.dir.ListUsers(ctx, pageToken)
+		if err != nil {
+			return nil, "", err
+		}
+		return res.Users, res.NextPageToken, nil
+	})
+	return func(yield func(*admin.User, error) bool) {
+		for u, err := range users {
+			if err == nil && !s.matches(u, opts) {
+				continue
+			}
+			if !yield(u, err) {
+				return
+			}
+		}
+	}
+}
+
+/*
+NOTE: This is in place of using .Query("orgUnitPath=/path/foo") because the
+existing of a space in the path name Regardless of encoding the path it
+results in HTTP 400
+*/
+func (s *UserService) matches(u *admin.User, opts ListUsersOptions) bool {
+	return !opts.FormerEmployeesOnly || u.OrgUnitPath == s.cfg.FormerEmployeesOrgUnit
+}
+
+// CreateUserOptions describes a new user
+type CreateUserOptions struct {
+	Email         string
+	FirstName     string
+	LastName      string
+	PersonalEmail string
+
+	// Password is the initial password; a random one is generated if
+	// empty. Either way the user must change it at first login.
+	Password string
+
+	// Groups to add the user to, as addresses or names in Config.Domain
+	Groups []string
+}
+
+// NewUser returns the user record Create inserts for opts
+func NewUser(opts CreateUserOptions) *admin.User {
+	user := &admin.User{
+		PrimaryEmail:              opts.Email,
+		ChangePasswordAtNextLogin: true,
+		Password:                  opts.Password,
+	}
+	if user.Password == "" {
+		user.Password = RandomPassword(12)
+	}
+	user.Emails = []admin.UserEmail{
+		{
+			Address: opts.PersonalEmail,
+			Type:    "home",
+		},
+		{
+			Address: user.PrimaryEmail,
+			Primary: true,
+		},
+	}
+	user.Name = &admin.UserName{
+		FamilyName: opts.LastName,
+		GivenName:  opts.FirstName,
+		FullName:   opts.FirstName + " " + opts.LastName,
+	}
+	return user
+}
+
+// Create inserts a user and adds it to opts.Groups. The returned user
+// carries the initial password, which the API does not echo back.
+func (s *UserService) Create(ctx context.Context, opts CreateUserOptions) (*admin.User, error) {
+	user := NewUser(opts)
+	created, err := s.dir.InsertUser(ctx, user)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create user %s: %w", opts.Email, err)
+	}
+	if created == nil {
+		created = user
+	}
+	created.Password = user.Password
+
+	if err := s.AddToGroups(ctx, user.PrimaryEmail, opts.Groups); err != nil {
+		return created, err
+	}
+	return created, nil
+}
+
+// AddToGroups adds a user to each group, stopping at the first failure
+func (s *UserService) AddToGroups(ctx context.Context, email string, groups []string) error {
+	for _, g := range groups {
+		if _, err := s.dir.InsertMember(ctx, s.cfg.groupEmail(g), &admin.Member{Email: email}); err != nil {
+			return fmt.Errorf("unable to add %s to group %s: %w", email, g, err)
+		}
+	}
+	return nil
+}
+
+// UpdateUserOptions lists the profile fields to change; empty fields are
+// left alone
+type UpdateUserOptions struct {
+	Address     string
+	Department  string
+	Title       string
+	OrgUnitPath string
+	Manager     string
+	Phones      []admin.UserPhone
+
+	// EmployeeID is only set on users without one unless
+	// OverwriteEmployeeID is true
+	EmployeeID          string
+	OverwriteEmployeeID bool
+
+	// EmployeeType is "staff" or "contractor"
+	EmployeeType string
+
+	// Groups to add the user to after the update
+	Groups []string
+}
+
+// UpdateUserResult is the outcome of Update
+type UpdateUserResult struct {
+	User *admin.User
+
+	// EmployeeIDSkipped is set when the user already had an employee ID
+	// and OverwriteEmployeeID was false
+	EmployeeIDSkipped bool
+}
+
+// Update changes the profile fields set in opts and adds the user to
+// opts.Groups
+func (s *UserService) Update(ctx context.Context, email string, opts UpdateUserOptions) (*UpdateUserResult, error) {
+	result := &UpdateUserResult{}
+	user := new(admin.User)
+
+	if opts.Address != "" {
+		user.Addresses = addresses(opts.Address)
+	}
+	if opts.Department != "" || opts.Title != "" {
+		user.Organizations = organizations(opts.Department, opts.Title)
+	}
+	if opts.EmployeeID != "" {
+		u, err := s.dir.GetUser(ctx, email)
+		if err != nil {
+			return nil, err
+		}
+		if u.ExternalIds == nil || opts.OverwriteEmployeeID {
+			user.ExternalIds = employeeIDs(opts.EmployeeID)
+		} else {
+			result.EmployeeIDSkipped = true
+		}
+	}
+	if opts.EmployeeType != "" {
+		user.CustomSchemas = employeeTypeSchema(opts.EmployeeType)
+	}
+	if opts.OrgUnitPath != "" {
+		user.OrgUnitPath = opts.OrgUnitPath
+	}
+	if opts.Manager != "" {
+		user.Relations = managerRelation(opts.Manager)
+	}
+	if len(opts.Phones) > 0 {
+		user.Phones = opts.Phones
+	}
+
+	updated, err := s.dir.UpdateUser(ctx, email, user)
+	if err != nil {
+		return nil, fmt.Errorf("unable to update %s: %w", email, err)
+	}
+	result.User = updated
+
+	if err := s.AddToGroups(ctx, email, opts.Groups); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// UpdateRecord sends user to the API as-is, for callers that build the
+// record themselves
+func (s *UserService) UpdateRecord(ctx context.Context, email string, user *admin.User) (*admin.User, error) {
+	return s.dir.UpdateUser(ctx, email, user)
+}
+
+// Offboard disables a departing user: personal information is cleared, the
+// password is reset, the account is hidden from the address list and moved
+// to the former employees OU, and it is removed from every group. The
+// account is not suspended, so mail is still delivered.
+func (s *UserService) Offboard(ctx context.Context, email string) (*admin.User, error) {
+	// TODO: signout sessions on all devices, reset cookies. Users.SignOut
+	// needs the admin.directory.user.security scope, which gac does
+	// not request.
+	user := new(admin.User)
+	clearPII(user)
+	user.ChangePasswordAtNextLogin = false
+	user.IncludeInGlobalAddressList = false
+	user.OrgUnitPath = s.cfg.FormerEmployeesOrgUnit
+	user.Password = RandomPassword(12)
+
+	// List every group before removing any, so the removals do not shift
+	// the pages still to be read
+	var groups []*admin.Group
+	var pageToken string
+	for {
+		gs, err := s.dir.ListUserGroups(ctx, email, pageToken)
+		if err != nil {
+			return nil, fmt.Errorf("unable to list groups of %s: %w", email, err)
+		}
+		groups = append(groups, gs.Groups...)
+		if gs.NextPageToken == "" {
+			break
+		}
+		pageToken = gs.NextPageToken
+	}
+	for _, g := range groups {
+		if err := s.dir.DeleteMember(ctx, g.Email, email); err != nil {
+			return nil, fmt.Errorf("unable to remove %s from group %s: %w", email, g.Email, err)
+		}
+	}
+
+	updated, err := s.dir.UpdateUser(ctx, email, user)
+	if err != nil {
+		return nil, fmt.Errorf("unable to update %s: %w", email, err)
+	}
+	return updated, nil
+}
+
+// ClearPII removes a user's personal addresses, emails and recovery
+// options without disabling the account
+func (s *UserService) ClearPII(ctx context.Context, email string) (*admin.User, error) {
+	user := new(admin.User)
+	clearPII(user)
+	return s.dir.UpdateUser(ctx, email, user)
+}
+
+// Suspend blocks sign-in for a user; reason is optional
+func (s *UserService) Suspend(ctx context.Context, email, reason string) (*admin.User, error) {
+	user := &admin.User{
+		Suspended:        true,
+		SuspensionReason: reason,
+		ForceSendFields:  []string{"Suspended"},
+	}
+	return s.dir.UpdateUser(ctx, email, user)
+}
+
+// Unsuspend restores sign-in for a suspended user
+func (s *UserService) Unsuspend(ctx context.Context, email string) (*admin.User, error) {
+	user := &admin.User{
+		Suspended:       false,
+		ForceSendFields: []string{"Suspended"},
+	}
+	return s.dir.UpdateUser(ctx, email, user)
+}
+
+// Projection returns a call option that sets the user projection, so
+// custom user attributes can be fetched.
+//
+// https://developers.google.com/admin-sdk/directory/reference/rest/v1/users/get#Projection
+func Projection(p string) googleapi.CallOption {
+	return projection(p)
+}
+
+type projection string
+
+func (p projection) Get() (string, string) {
+	return "projection", string(p)
+}
+
+// RandomPassword returns a password of length characters from an alphabet
+// without look-alike characters
+func RandomPassword(length int) string {
+	const letterRunes = "abcdefghijkmnopqrstuvwxyzABCDEFGHIJKLMNPQRSTUVWXYZ123456789"
+
+	b := make([]byte, length)
+	maxIdx := big.NewInt(int64(len(letterRunes)))
+
+	for i := range b {
+		n, err := rand.Int(rand.Reader, maxIdx)
+		if err != nil {
+			// If crypto/rand fails, this is a critical error
+			panic("failed to generate secure random number: " + err.Error())
+		}
+		b[i] = letterRunes[n.Int64()]
+	}
+	return string(b)
+}
+
+func clearPII(u *admin.User) {
+	u.ForceSendFields = []string{
+		"RecoveryEmail",
+		"RecoveryPhone",
+	}
+
+	// These are JSON arrays.  Sending Null fields will flush them out.
+	// No need to set them as blank strings.
+	u.NullFields = []string{
+		"Addresses",
+		"Emails",
+	}
+
+	u.RecoveryEmail = ""
+	u.RecoveryPhone = ""
+}
+
+// addresses returns a single formatted address
+func addresses(address string) []admin.UserAddress {
+	return []admin.UserAddress{{Formatted: address}}
+}
+
+// organizations returns the user's primary organization
+func organizations(department, title string) []admin.UserOrganization {
+	return []admin.UserOrganization{{
+		Primary:    true,
+		Department: department,
+		Title:      title,
+	}}
+}
+
+// managerRelation returns the relation naming the user's manager
+func managerRelation(managerEmail string) []admin.UserRelation {
+	return []admin.UserRelation{{
+		Type:  "manager",
+		Value: managerEmail,
+	}}
+}
+
+// employeeTypeSchema returns the Employee_Type custom schema for "staff" or
+// "contractor"
+func employeeTypeSchema(employeeType string) map[string]googleapi.RawMessage {
+	schema := make(map[string]googleapi.RawMessage)
+
+	// U-G-L-Y but works for now...
+	var t = []byte(`{"Staff":[{"type":"work","value":"Yes"}],"Contractor":[{"type":"work","value":""}]}`)
+	if employeeType == "contractor" {
+		t = []byte(`{"Staff":[{"type":"work","value":""}],"Contractor":[{"type":"work","value":"Yes"}]}`)
+	}
+	schema["Employee_Type"] = t
+
+	return schema
+}
+
+// employeeIDs returns the organization external ID holding the employee ID
+func employeeIDs(employeeID string) []admin.UserExternalId {
+	return []admin.UserExternalId{{
+		Type:  "organization",
+		Value: employeeID,
+	}}
+}
@@ -0,0 +1,437 @@
+package gac
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	admin "google.golang.org/api/admin/directory/v1"
+)
+
+func TestRandomPassword(t *testing.T) {
+	tests := []struct {
+		name   string
+		length int
+	}{
+		{"length 8", 8},
+		{"length 12", 12},
+		{"length 16", 16},
+		{"length 32", 32},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			password := RandomPassword(tt.length)
+
+			if len(password) != tt.length {
+				t.Errorf("RandomPassword(%d) length = %d, want %d", tt.length, len(password), tt.length)
+			}
+
+			// Check that password only contains valid characters
+			validChars := "abcdefghijkmnopqrstuvwxyzABCDEFGHIJKLMNPQRSTUVWXYZ123456789"
+			for _, char := range password {
+				if !strings.ContainsRune(validChars, char) {
+					t.Errorf("RandomPassword contains invalid character: %c", char)
+				}
+			}
+		})
+	}
+}
+
+func TestRandomPasswordUniqueness(t *testing.T) {
+	// Generate multiple passwords and ensure they're different
+	passwords := make(map[string]bool)
+	iterations := 100
+	length := 12
+
+	for i := 0; i < iterations; i++ {
+		password := RandomPassword(length)
+		if passwords[password] {
+			t.Errorf("RandomPassword generated duplicate: %s", password)
+		}
+		passwords[password] = true
+	}
+}
+
+func TestRandomPasswordZeroLength(t *testing.T) {
+	password := RandomPassword(0)
+	if len(password) != 0 {
+		t.Errorf("RandomPassword(0) = %q, want empty string", password)
+	}
+}
+
+func TestNewUser(t *testing.T) {
+	tests := []struct {
+		name         string
+		email        string
+		fname        string
+		lname        string
+		primaryEmail string
+	}{
+		{
+			name:         "full user info",
+			email:        "john.personal@example.com",
+			fname:        "John",
+			lname:        "Doe",
+			primaryEmail: "john.doe@company.com",
+		},
+		{
+			name:         "empty personal email",
+			email:        "",
+			fname:        "Jane",
+			lname:        "Smith",
+			primaryEmail: "jane.smith@company.com",
+		},
+		{
+			name:         "single character names",
+			email:        "a@example.com",
+			fname:        "A",
+			lname:        "B",
+			primaryEmail: "ab@company.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := NewUser(CreateUserOptions{
+				Email:         tt.primaryEmail,
+				FirstName:     tt.fname,
+				LastName:      tt.lname,
+				PersonalEmail: tt.email,
+			})
+
+			if user.PrimaryEmail != tt.primaryEmail {
+				t.Errorf("PrimaryEmail = %q, want %q", user.PrimaryEmail, tt.primaryEmail)
+			}
+			if len(user.Password) != 12 || !user.ChangePasswordAtNextLogin {
+				t.Errorf("Password = %q, ChangePasswordAtNextLogin = %v, want a random password to change", user.Password, user.ChangePasswordAtNextLogin)
+			}
+
+			// Check Name is set correctly
+			if user.Name == nil {
+				t.Fatal("user.Name is nil")
+			}
+			if user.Name.GivenName != tt.fname {
+				t.Errorf("GivenName = %q, want %q", user.Name.GivenName, tt.fname)
+			}
+			if user.Name.FamilyName != tt.lname {
+				t.Errorf("FamilyName = %q, want %q", user.Name.FamilyName, tt.lname)
+			}
+			expectedFullName := tt.fname + " " + tt.lname
+			if user.Name.FullName != expectedFullName {
+				t.Errorf("FullName = %q, want %q", user.Name.FullName, expectedFullName)
+			}
+
+			// Check Emails are set correctly
+			emails, ok := user.Emails.([]admin.UserEmail)
+			if !ok {
+				t.Fatal("user.Emails is not []admin.UserEmail")
+			}
+			if len(emails) != 2 {
+				t.Errorf("len(Emails) = %d, want 2", len(emails))
+				return
+			}
+
+			// First email should be personal
+			if emails[0].Address != tt.email {
+				t.Errorf("Emails[0].Address = %q, want %q", emails[0].Address, tt.email)
+			}
+			if emails[0].Type != "home" {
+				t.Errorf("Emails[0].Type = %q, want %q", emails[0].Type, "home")
+			}
+
+			// Second email should be primary
+			if emails[1].Address != tt.primaryEmail {
+				t.Errorf("Emails[1].Address = %q, want %q", emails[1].Address, tt.primaryEmail)
+			}
+			if !emails[1].Primary {
+				t.Error("Emails[1].Primary = false, want true")
+			}
+		})
+	}
+
+	if user := NewUser(CreateUserOptions{Password: "chosen"}); user.Password != "chosen" {
+		t.Errorf("NewUser() with a password set it to %q, want %q", user.Password, "chosen")
+	}
+}
+
+func TestAddresses(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []admin.UserAddress
+	}{
+		{
+			name:  "simple address",
+			input: "New York, NY",
+			expected: []admin.UserAddress{
+				{Formatted: "New York, NY"},
+			},
+		},
+		{
+			name:  "full address",
+			input: "123 Main St, New York, NY 10001",
+			expected: []admin.UserAddress{
+				{Formatted: "123 Main St, New York, NY 10001"},
+			},
+		},
+		{
+			name:     "empty address",
+			input:    "",
+			expected: []admin.UserAddress{{Formatted: ""}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := addresses(tt.input)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("addresses(%q) = %+v, want %+v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestManagerRelation(t *testing.T) {
+	result := managerRelation("manager@example.com")
+	want := []admin.UserRelation{{Type: "manager", Value: "manager@example.com"}}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("managerRelation() = %+v, want %+v", result, want)
+	}
+}
+
+func TestOrganizations(t *testing.T) {
+	tests := []struct {
+		name  string
+		dept  string
+		title string
+	}{
+		{"both dept and title", "Engineering", "Software Engineer"},
+		{"only dept", "Sales", ""},
+		{"only title", "", "Manager"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := organizations(tt.dept, tt.title)
+			if len(result) != 1 {
+				t.Fatalf("organizations() returned %d organizations, want 1", len(result))
+			}
+			if !result[0].Primary {
+				t.Errorf("organizations() first org should be primary")
+			}
+			if result[0].Department != tt.dept || result[0].Title != tt.title {
+				t.Errorf("organizations() = %+v, want department %q and title %q", result[0], tt.dept, tt.title)
+			}
+		})
+	}
+}
+
+func TestEmployeeTypeSchema(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"staff", "staff", `"Staff":[{"type":"work","value":"Yes"}]`},
+		{"contractor", "contractor", `"Contractor":[{"type":"work","value":"Yes"}]`},
+		{"empty", "", `"Staff":[{"type":"work","value":"Yes"}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := employeeTypeSchema(tt.input)
+			schema, ok := result["Employee_Type"]
+			if !ok {
+				t.Fatalf("employeeTypeSchema(%q) missing 'Employee_Type' key", tt.input)
+			}
+			if !strings.Contains(string(schema), tt.want) {
+				t.Errorf("employeeTypeSchema(%q) = %s, want it to contain %s", tt.input, schema, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmployeeIDs(t *testing.T) {
+	result := employeeIDs("550e8400-e29b-41d4-a716-446655440000")
+	want := []admin.UserExternalId{{Type: "organization", Value: "550e8400-e29b-41d4-a716-446655440000"}}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("employeeIDs() = %+v, want %+v", result, want)
+	}
+}
+
+func TestUserServiceList(t *testing.T) {
+	client, srv := newTestClient(t)
+	srv.PageSize = 1
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		opts ListUsersOptions
+		want []string
+	}{
+		{"all users across pages", ListUsersOptions{}, []string{"alice@example.com", "bob@example.com", "carol@example.com"}},
+		{"former employees only", ListUsersOptions{FormerEmployeesOnly: true}, []string{"carol@example.com"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users, err := client.Users.List(ctx, tt.opts)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			var got []string
+			for _, u := range users {
+				got = append(got, u.PrimaryEmail)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("List() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserServiceCreate(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx := context.Background()
+
+	user, err := client.Users.Create(ctx, CreateUserOptions{
+		Email:         "dave@example.com",
+		FirstName:     "Dave",
+		LastName:      "Brown",
+		PersonalEmail: "dave@personal.example",
+		Groups:        []string{"engineering", "all@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if user.PrimaryEmail != "dave@example.com" || len(user.Password) != 12 {
+		t.Errorf("Create() = %s with password %q, want dave with the generated password", user.PrimaryEmail, user.Password)
+	}
+
+	for _, group := range []string{"engineering", "all"} {
+		members, err := client.Groups.Members(ctx, group)
+		if err != nil {
+			t.Fatalf("Members(%s) error = %v", group, err)
+		}
+		if !hasMember(members, "dave@example.com") {
+			t.Errorf("Members(%s) = %v, want dave added", group, members)
+		}
+	}
+
+	if _, err := client.Users.Create(ctx, CreateUserOptions{Email: "dave@example.com"}); err == nil || !strings.Contains(err.Error(), "unable to create user") {
+		t.Errorf("Create() of an existing user error = %v, want an unable to create error", err)
+	}
+	if _, err := client.Users.Create(ctx, CreateUserOptions{Email: "erin@example.com", Groups: []string{"missing"}}); err == nil || !strings.Contains(err.Error(), "unable to add") {
+		t.Errorf("Create() into a missing group error = %v, want an unable to add error", err)
+	}
+}
+
+func TestUserServiceUpdate(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		email       string
+		opts        UpdateUserOptions
+		wantSkipped bool
+		wantID      string
+	}{
+		{"sets a missing employee ID", "alice@example.com", UpdateUserOptions{EmployeeID: "abcd"}, false, "abcd"},
+		{"keeps an existing employee ID", "bob@example.com", UpdateUserOptions{EmployeeID: "abcd"}, true, "1234"},
+		{"overwrites an existing employee ID", "bob@example.com", UpdateUserOptions{EmployeeID: "abcd", OverwriteEmployeeID: true}, false, "abcd"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := client.Users.Update(ctx, tt.email, tt.opts)
+			if err != nil {
+				t.Fatalf("Update() error = %v", err)
+			}
+			if result.EmployeeIDSkipped != tt.wantSkipped {
+				t.Errorf("EmployeeIDSkipped = %v, want %v", result.EmployeeIDSkipped, tt.wantSkipped)
+			}
+			u, err := client.Users.Get(ctx, tt.email, GetUserOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := u.ExternalIds; !strings.Contains(toJSON(t, got), `"value":"`+tt.wantID+`"`) {
+				t.Errorf("ExternalIds = %s, want value %s", toJSON(t, got), tt.wantID)
+			}
+		})
+	}
+
+	_, err := client.Users.Update(ctx, "alice@example.com", UpdateUserOptions{
+		Department:  "Platform",
+		Title:       "Engineer",
+		OrgUnitPath: "/Engineering",
+		Groups:      []string{"alumni"},
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	u, err := client.Users.Get(ctx, "alice@example.com", GetUserOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := toJSON(t, u.Organizations); !strings.Contains(got, `"department":"Platform"`) || !strings.Contains(got, `"title":"Engineer"`) {
+		t.Errorf("Organizations = %s, want the new department and title", got)
+	}
+}
+
+func TestUserServiceOffboard(t *testing.T) {
+	client, srv := newTestClient(t)
+	srv.PageSize = 1
+	ctx := context.Background()
+
+	user, err := client.Users.Offboard(ctx, "alice@example.com")
+	if err != nil {
+		t.Fatalf("Offboard() error = %v", err)
+	}
+	if user.OrgUnitPath != DefaultFormerEmployeesOrgUnit || user.Suspended {
+		t.Errorf("Offboard() = %s, suspended %v, want moved to former employees and not suspended", user.OrgUnitPath, user.Suspended)
+	}
+
+	// alice was in two groups, each listed on its own page
+	for _, group := range []string{"engineering", "all"} {
+		members, err := client.Groups.Members(ctx, group)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hasMember(members, "alice@example.com") {
+			t.Errorf("alice is still a member of %s", group)
+		}
+	}
+}
+
+func TestUserServiceSuspend(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx := context.Background()
+
+	user, err := client.Users.Suspend(ctx, "bob@example.com", "on leave")
+	if err != nil {
+		t.Fatalf("Suspend() error = %v", err)
+	}
+	if !user.Suspended {
+		t.Error("Suspend() left the user active")
+	}
+
+	user, err = client.Users.Unsuspend(ctx, "bob@example.com")
+	if err != nil {
+		t.Fatalf("Unsuspend() error = %v", err)
+	}
+	if user.Suspended {
+		t.Error("Unsuspend() left the user suspended")
+	}
+}
+
+func hasMember(members []*admin.Member, email string) bool {
+	for _, m := range members {
+		if m.Email == email {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,167 @@
+package gactest
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	calendar "google.golang.org/api/calendar/v3"
+)
+
+// serveCalendar handles calendar/v3 requests. Only events are implemented;
+// "primary" is kept as its own calendar ID.
+func (w *Workspace) serveCalendar(r *request, path []string) {
+	switch {
+	case len(path) == 3 && path[0] == "calendars" && path[2] == "events":
+		w.serveEvents(r, strings.ToLower(path[1]))
+	case len(path) == 4 && path[0] == "calendars" && path[2] == "events":
+		w.serveEvent(r, strings.ToLower(path[1]), path[3])
+	default:
+		r.notFound("Not Found")
+	}
+}
+
+// eventStart returns when an event starts, for ordering and time filters
+func eventStart(e *calendar.Event) time.Time {
+	if e.Start == nil {
+		return time.Time{}
+	}
+	if e.Start.DateTime != "" {
+		t, _ := time.Parse(time.RFC3339, e.Start.DateTime)
+		return t
+	}
+	t, _ := time.Parse("2006-01-02", e.Start.Date)
+	return t
+}
+
+// eventEnd returns when an event ends, falling back to its start
+func eventEnd(e *calendar.Event) time.Time {
+	if e.End == nil {
+		return eventStart(e)
+	}
+	if e.End.DateTime != "" {
+		t, _ := time.Parse(time.RFC3339, e.End.DateTime)
+		return t
+	}
+	t, _ := time.Parse("2006-01-02", e.End.Date)
+	return t
+}
+
+func (w *Workspace) putEvent(calendarID string, e *calendar.Event) *calendar.Event {
+	e.Kind = "calendar#event"
+	if e.Id == "" {
+		e.Id = w.newID()
+	}
+	if e.Status == "" {
+		e.Status = "confirmed"
+	}
+	now := w.Now().UTC().Format(time.RFC3339)
+	if e.Created == "" {
+		e.Created = now
+	}
+	e.Updated = now
+	for i, existing := range w.events[calendarID] {
+		if existing.Id == e.Id {
+			w.events[calendarID][i] = e
+			return e
+		}
+	}
+	w.events[calendarID] = append(w.events[calendarID], e)
+	return e
+}
+
+func (w *Workspace) serveEvents(r *request, calendarID string) {
+	switch r.Method {
+	case http.MethodGet:
+		timeMin, ok := parseTime(r, "timeMin")
+		if !ok {
+			return
+		}
+		timeMax, ok := parseTime(r, "timeMax")
+		if !ok {
+			return
+		}
+
+		var items []*calendar.Event
+		for _, e := range w.events[calendarID] {
+			if (!timeMin.IsZero() && !eventEnd(e).After(timeMin)) || (!timeMax.IsZero() && !eventStart(e).Before(timeMax)) {
+				continue
+			}
+			items = append(items, e)
+		}
+		sort.SliceStable(items, func(i, j int) bool { return eventStart(items[i]).Before(eventStart(items[j])) })
+
+		items, next, ok := page(r, items, w.PageSize)
+		if ok {
+			r.ok(&calendar.Events{Kind: "calendar#events", Summary: calendarID, Items: items, NextPageToken: next})
+		}
+
+	case http.MethodPost:
+		var e calendar.Event
+		if !r.decode(&e) {
+			return
+		}
+		if e.Start == nil || e.End == nil {
+			r.badRequest("Missing time range")
+			return
+		}
+		e.Id = ""
+		r.ok(w.putEvent(calendarID, &e))
+
+	default:
+		r.methodNotAllowed()
+	}
+}
+
+func (w *Workspace) serveEvent(r *request, calendarID, id string) {
+	var e *calendar.Event
+	for _, existing := range w.events[calendarID] {
+		if existing.Id == id {
+			e = existing
+		}
+	}
+	if e == nil {
+		r.notFound("Not Found")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		r.ok(e)
+
+	case http.MethodPut:
+		var updated calendar.Event
+		if !r.decode(&updated) {
+			return
+		}
+		updated.Id, updated.Created = e.Id, e.Created
+		r.ok(w.putEvent(calendarID, &updated))
+
+	case http.MethodPatch:
+		patch, ok := r.decodeMap()
+		if !ok {
+			return
+		}
+		updated, err := merge(e, patch)
+		if err != nil {
+			r.badRequest(err.Error())
+			return
+		}
+		updated.Id = e.Id
+		r.ok(w.putEvent(calendarID, updated))
+
+	case http.MethodDelete:
+		events := w.events[calendarID]
+		for i, existing := range events {
+			if existing.Id == id {
+				w.events[calendarID] = append(events[:i], events[i+1:]...)
+				break
+			}
+		}
+		r.noContent()
+
+	default:
+		r.methodNotAllowed()
+	}
+}
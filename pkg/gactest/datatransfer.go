@@ -0,0 +1,99 @@
+package gactest
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	datatransfer "google.golang.org/api/admin/datatransfer/v1"
+)
+
+// serveDataTransfer handles admin/datatransfer/v1 requests. Transfers
+// complete as soon as they are created.
+func (w *Workspace) serveDataTransfer(r *request, path []string) {
+	switch {
+	case len(path) == 1 && path[0] == "applications":
+		if r.Method != http.MethodGet {
+			r.methodNotAllowed()
+			return
+		}
+		items, next, ok := page(r, w.applications, w.PageSize)
+		if ok {
+			r.ok(&datatransfer.ApplicationsListResponse{Kind: "admin#datatransfer#applicationsList", Applications: items, NextPageToken: next})
+		}
+
+	case len(path) == 2 && path[0] == "applications":
+		if r.Method != http.MethodGet {
+			r.methodNotAllowed()
+			return
+		}
+		for _, app := range w.applications {
+			if strconv.FormatInt(app.Id, 10) == path[1] {
+				r.ok(app)
+				return
+			}
+		}
+		r.notFound("Application not found")
+
+	case len(path) == 1 && path[0] == "transfers":
+		w.serveTransfers(r)
+
+	case len(path) == 2 && path[0] == "transfers":
+		if r.Method != http.MethodGet {
+			r.methodNotAllowed()
+			return
+		}
+		for _, t := range w.transfers {
+			if t.Id == path[1] {
+				r.ok(t)
+				return
+			}
+		}
+		r.notFound("Transfer not found")
+
+	default:
+		r.notFound("Not Found")
+	}
+}
+
+func (w *Workspace) serveTransfers(r *request) {
+	switch r.Method {
+	case http.MethodGet:
+		q := r.URL.Query()
+		var items []*datatransfer.DataTransfer
+		for _, t := range w.transfers {
+			if (q.Get("oldOwnerUserId") != "" && t.OldOwnerUserId != q.Get("oldOwnerUserId")) ||
+				(q.Get("newOwnerUserId") != "" && t.NewOwnerUserId != q.Get("newOwnerUserId")) ||
+				(q.Get("status") != "" && t.OverallTransferStatusCode != q.Get("status")) {
+				continue
+			}
+			items = append(items, t)
+		}
+		items, next, ok := page(r, items, w.PageSize)
+		if ok {
+			r.ok(&datatransfer.DataTransfersListResponse{Kind: "admin#datatransfer#dataTransfersList", DataTransfers: items, NextPageToken: next})
+		}
+
+	case http.MethodPost:
+		var t datatransfer.DataTransfer
+		if !r.decode(&t) {
+			return
+		}
+		if t.OldOwnerUserId == "" || t.NewOwnerUserId == "" || len(t.ApplicationDataTransfers) == 0 {
+			r.badRequest("Invalid Input: owners and applications are required")
+			return
+		}
+		t.Kind = "admin#datatransfer#DataTransfer"
+		t.Id = w.newID()
+		t.RequestTime = w.Now().UTC().Format(time.RFC3339)
+		t.OverallTransferStatusCode = "completed"
+		for _, app := range t.ApplicationDataTransfers {
+			app.ApplicationTransferStatus = "completed"
+		}
+		w.transfers = append(w.transfers, &t)
+		r.ok(&t)
+
+	default:
+		r.methodNotAllowed()
+	}
+}
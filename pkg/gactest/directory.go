@@ -0,0 +1,876 @@
+package gactest
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	admin "google.golang.org/api/admin/directory/v1"
+)
+
+// serveDirectory handles admin/directory/v1 requests
+func (w *Workspace) serveDirectory(r *request, path []string) {
+	switch {
+	case len(path) == 1 && path[0] == "users":
+		w.serveUsers(r)
+	case len(path) == 2 && path[0] == "users":
+		w.serveUser(r, path[1])
+	case len(path) == 3 && path[0] == "users" && path[2] == "aliases":
+		w.serveUserAliases(r, path[1])
+	case len(path) == 4 && path[0] == "users" && path[2] == "aliases":
+		w.serveUserAlias(r, path[1], path[3])
+	case len(path) == 3 && path[0] == "users" && path[2] == "signOut":
+		w.serveSignOut(r, path[1])
+	case len(path) == 1 && path[0] == "groups":
+		w.serveGroups(r)
+	case len(path) == 2 && path[0] == "groups":
+		w.serveGroup(r, path[1])
+	case len(path) == 3 && path[0] == "groups" && path[2] == "members":
+		w.serveMembers(r, path[1])
+	case len(path) == 4 && path[0] == "groups" && path[2] == "members":
+		w.serveMember(r, path[1], path[3])
+	case len(path) == 4 && path[0] == "groups" && path[2] == "hasMember":
+		w.serveHasMember(r, path[1], path[3])
+	case len(path) == 3 && path[0] == "customer" && path[2] == "orgunits":
+		w.serveOrgUnits(r)
+	case len(path) > 3 && path[0] == "customer" && path[2] == "orgunits":
+		w.serveOrgUnit(r, orgUnitKey(path[3:]))
+	case len(path) == 4 && path[0] == "customer" && path[2] == "resources" && path[3] == "calendars":
+		w.serveCalendarResources(r)
+	case len(path) == 5 && path[0] == "customer" && path[2] == "resources" && path[3] == "calendars":
+		w.serveCalendarResource(r, path[4])
+	case len(path) == 4 && path[0] == "customer" && path[2] == "resources" && path[3] == "buildings":
+		w.serveBuildings(r)
+	case len(path) == 5 && path[0] == "customer" && path[2] == "resources" && path[3] == "buildings":
+		w.serveBuilding(r, path[4])
+	default:
+		r.notFound("Not Found")
+	}
+}
+
+// Users
+
+// findUser looks a user up by primary email, alias or ID
+func (w *Workspace) findUser(key string) *admin.User {
+	if u, ok := w.users[strings.ToLower(key)]; ok {
+		return u
+	}
+	for _, u := range w.users {
+		if u.Id == key {
+			return u
+		}
+		for _, a := range u.Aliases {
+			if strings.EqualFold(a, key) {
+				return u
+			}
+		}
+	}
+	return nil
+}
+
+// emailInUse reports whether a user or group already owns an address
+func (w *Workspace) emailInUse(email string) bool {
+	return w.findUser(email) != nil || w.findGroup(email) != nil
+}
+
+func (w *Workspace) putUser(u *admin.User) *admin.User {
+	if u.Id == "" {
+		u.Id = w.newID()
+	}
+	if u.OrgUnitPath == "" {
+		u.OrgUnitPath = "/"
+	}
+	if u.CreationTime == "" {
+		u.CreationTime = w.Now().UTC().Format(time.RFC3339)
+	}
+	if u.Name != nil && u.Name.FullName == "" {
+		u.Name.FullName = strings.TrimSpace(u.Name.GivenName + " " + u.Name.FamilyName)
+	}
+	u.Password = ""
+	w.users[strings.ToLower(u.PrimaryEmail)] = u
+	return u
+}
+
+func (w *Workspace) serveUsers(r *request) {
+	switch r.Method {
+	case http.MethodGet:
+		domain := r.URL.Query().Get("domain")
+		var users []*admin.User
+		for _, k := range sortedKeys(w.users) {
+			if domain == "" || strings.HasSuffix(k, "@"+strings.ToLower(domain)) {
+				users = append(users, w.users[k])
+			}
+		}
+		items, next, ok := page(r, users, w.PageSize)
+		if ok {
+			r.ok(&admin.Users{Kind: "admin#directory#users", Users: items, NextPageToken: next})
+		}
+
+	case http.MethodPost:
+		var u admin.User
+		if !r.decode(&u) {
+			return
+		}
+		if u.PrimaryEmail == "" {
+			r.badRequest("Invalid Input: primary_user_email")
+			return
+		}
+		if w.emailInUse(u.PrimaryEmail) {
+			r.conflict("Entity already exists.")
+			return
+		}
+		if !w.validOrgUnit(u.OrgUnitPath) {
+			r.badRequest("Invalid Input: INVALID_OU_ID")
+			return
+		}
+		u.Id = ""
+		r.ok(w.putUser(&u))
+
+	default:
+		r.methodNotAllowed()
+	}
+}
+
+func (w *Workspace) serveUser(r *request, key string) {
+	u := w.findUser(key)
+	if u == nil {
+		r.notFound("Resource Not Found: userKey")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		r.ok(u)
+
+	case http.MethodPut, http.MethodPatch:
+		patch, ok := r.decodeMap()
+		if !ok {
+			return
+		}
+		updated, err := merge(u, patch)
+		if err != nil {
+			r.badRequest(err.Error())
+			return
+		}
+		if !w.validOrgUnit(updated.OrgUnitPath) {
+			r.badRequest("Invalid Input: INVALID_OU_ID")
+			return
+		}
+		if !strings.EqualFold(updated.PrimaryEmail, u.PrimaryEmail) {
+			if w.emailInUse(updated.PrimaryEmail) {
+				r.conflict("Entity already exists.")
+				return
+			}
+			delete(w.users, strings.ToLower(u.PrimaryEmail))
+		}
+		r.ok(w.putUser(updated))
+
+	case http.MethodDelete:
+		delete(w.users, strings.ToLower(u.PrimaryEmail))
+		for group := range w.members {
+			w.removeMember(group, u.PrimaryEmail)
+		}
+		r.noContent()
+
+	default:
+		r.methodNotAllowed()
+	}
+}
+
+func (w *Workspace) serveUserAliases(r *request, key string) {
+	u := w.findUser(key)
+	if u == nil {
+		r.notFound("Resource Not Found: userKey")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		aliases := &admin.Aliases{Kind: "admin#directory#aliases"}
+		for _, a := range u.Aliases {
+			aliases.Aliases = append(aliases.Aliases, &admin.Alias{
+				Kind:         "admin#directory#alias",
+				Alias:        a,
+				Id:           u.Id,
+				PrimaryEmail: u.PrimaryEmail,
+			})
+		}
+		r.ok(aliases)
+
+	case http.MethodPost:
+		var a admin.Alias
+		if !r.decode(&a) {
+			return
+		}
+		if a.Alias == "" {
+			r.badRequest("Invalid Input: alias")
+			return
+		}
+		if w.emailInUse(a.Alias) {
+			r.conflict("Entity already exists.")
+			return
+		}
+		u.Aliases = append(u.Aliases, a.Alias)
+		r.ok(&admin.Alias{Kind: "admin#directory#alias", Alias: a.Alias, Id: u.Id, PrimaryEmail: u.PrimaryEmail})
+
+	default:
+		r.methodNotAllowed()
+	}
+}
+
+func (w *Workspace) serveUserAlias(r *request, key, alias string) {
+	u := w.findUser(key)
+	if u == nil {
+		r.notFound("Resource Not Found: userKey")
+		return
+	}
+	if r.Method != http.MethodDelete {
+		r.methodNotAllowed()
+		return
+	}
+	for i, a := range u.Aliases {
+		if strings.EqualFold(a, alias) {
+			u.Aliases = append(u.Aliases[:i], u.Aliases[i+1:]...)
+			r.noContent()
+			return
+		}
+	}
+	r.notFound("Resource Not Found: alias")
+}
+
+func (w *Workspace) serveSignOut(r *request, key string) {
+	if w.findUser(key) == nil {
+		r.notFound("Resource Not Found: userKey")
+		return
+	}
+	if r.Method != http.MethodPost {
+		r.methodNotAllowed()
+		return
+	}
+	r.noContent()
+}
+
+// Groups and members
+
+// findGroup looks a group up by email, alias or ID
+func (w *Workspace) findGroup(key string) *admin.Group {
+	if g, ok := w.groups[strings.ToLower(key)]; ok {
+		return g
+	}
+	for _, g := range w.groups {
+		if g.Id == key {
+			return g
+		}
+		for _, a := range g.Aliases {
+			if strings.EqualFold(a, key) {
+				return g
+			}
+		}
+	}
+	return nil
+}
+
+func (w *Workspace) putGroup(g *admin.Group) *admin.Group {
+	if g.Id == "" {
+		g.Id = w.newID()
+	}
+	key := strings.ToLower(g.Email)
+	g.DirectMembersCount = int64(len(w.members[key]))
+	w.groups[key] = g
+	return g
+}
+
+func (w *Workspace) serveGroups(r *request) {
+	switch r.Method {
+	case http.MethodGet:
+		userKey := r.URL.Query().Get("userKey")
+		var member string
+		if userKey != "" {
+			member = userKey
+			if u := w.findUser(userKey); u != nil {
+				member = u.PrimaryEmail
+			}
+		}
+
+		var groups []*admin.Group
+		for _, k := range sortedKeys(w.groups) {
+			if member == "" || w.memberIndex(k, member) >= 0 {
+				groups = append(groups, w.groups[k])
+			}
+		}
+		items, next, ok := page(r, groups, w.PageSize)
+		if ok {
+			r.ok(&admin.Groups{Kind: "admin#directory#groups", Groups: items, NextPageToken: next})
+		}
+
+	case http.MethodPost:
+		var g admin.Group
+		if !r.decode(&g) {
+			return
+		}
+		if g.Email == "" {
+			r.badRequest("Invalid Input: email")
+			return
+		}
+		if w.emailInUse(g.Email) {
+			r.conflict("Entity already exists.")
+			return
+		}
+		g.Id = ""
+		r.ok(w.putGroup(&g))
+
+	default:
+		r.methodNotAllowed()
+	}
+}
+
+func (w *Workspace) serveGroup(r *request, key string) {
+	g := w.findGroup(key)
+	if g == nil {
+		r.notFound("Resource Not Found: groupKey")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		r.ok(g)
+
+	case http.MethodPut, http.MethodPatch:
+		patch, ok := r.decodeMap()
+		if !ok {
+			return
+		}
+		updated, err := merge(g, patch)
+		if err != nil {
+			r.badRequest(err.Error())
+			return
+		}
+		if !strings.EqualFold(updated.Email, g.Email) {
+			r.badRequest("Renaming groups is not supported by the fake")
+			return
+		}
+		r.ok(w.putGroup(updated))
+
+	case http.MethodDelete:
+		key := strings.ToLower(g.Email)
+		delete(w.groups, key)
+		delete(w.members, key)
+		delete(w.groupSettings, key)
+		r.noContent()
+
+	default:
+		r.methodNotAllowed()
+	}
+}
+
+// memberIndex returns the position of a member in a group, or -1
+func (w *Workspace) memberIndex(group, key string) int {
+	for i, m := range w.members[group] {
+		if strings.EqualFold(m.Email, key) || m.Id == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func (w *Workspace) removeMember(group, key string) bool {
+	i := w.memberIndex(group, key)
+	if i < 0 {
+		return false
+	}
+	w.members[group] = append(w.members[group][:i], w.members[group][i+1:]...)
+	if g := w.groups[group]; g != nil {
+		g.DirectMembersCount = int64(len(w.members[group]))
+	}
+	return true
+}
+
+// addMember fills in the fields the API derives and adds m to a group
+func (w *Workspace) addMember(group string, m *admin.Member) *admin.Member {
+	m.Kind = "admin#directory#member"
+	if m.Role == "" {
+		m.Role = "MEMBER"
+	}
+	if m.Status == "" {
+		m.Status = "ACTIVE"
+	}
+	if u := w.findUser(m.Email); u != nil {
+		m.Id, m.Type = u.Id, "USER"
+	} else if g := w.findGroup(m.Email); g != nil {
+		m.Id, m.Type = g.Id, "GROUP"
+	} else if m.Type == "" {
+		m.Type = "USER"
+	}
+	if m.Id == "" {
+		m.Id = w.newID()
+	}
+	w.members[group] = append(w.members[group], m)
+	if g := w.groups[group]; g != nil {
+		g.DirectMembersCount = int64(len(w.members[group]))
+	}
+	return m
+}
+
+func (w *Workspace) serveMembers(r *request, key string) {
+	g := w.findGroup(key)
+	if g == nil {
+		r.notFound("Resource Not Found: groupKey")
+		return
+	}
+	group := strings.ToLower(g.Email)
+
+	switch r.Method {
+	case http.MethodGet:
+		roles := map[string]bool{}
+		if v := r.URL.Query().Get("roles"); v != "" {
+			for _, role := range strings.Split(v, ",") {
+				roles[strings.ToUpper(strings.TrimSpace(role))] = true
+			}
+		}
+		var members []*admin.Member
+		for _, m := range w.members[group] {
+			if len(roles) == 0 || roles[m.Role] {
+				members = append(members, m)
+			}
+		}
+		items, next, ok := page(r, members, w.PageSize)
+		if ok {
+			r.ok(&admin.Members{Kind: "admin#directory#members", Members: items, NextPageToken: next})
+		}
+
+	case http.MethodPost:
+		var m admin.Member
+		if !r.decode(&m) {
+			return
+		}
+		if m.Email == "" {
+			r.badRequest("Invalid Input: memberKey")
+			return
+		}
+		if w.memberIndex(group, m.Email) >= 0 {
+			r.conflict("Member already exists.")
+			return
+		}
+		r.ok(w.addMember(group, &m))
+
+	default:
+		r.methodNotAllowed()
+	}
+}
+
+func (w *Workspace) serveMember(r *request, key, memberKey string) {
+	g := w.findGroup(key)
+	if g == nil {
+		r.notFound("Resource Not Found: groupKey")
+		return
+	}
+	group := strings.ToLower(g.Email)
+	i := w.memberIndex(group, memberKey)
+	if i < 0 {
+		r.notFound("Resource Not Found: memberKey")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		r.ok(w.members[group][i])
+
+	case http.MethodPut, http.MethodPatch:
+		patch, ok := r.decodeMap()
+		if !ok {
+			return
+		}
+		updated, err := merge(w.members[group][i], patch)
+		if err != nil {
+			r.badRequest(err.Error())
+			return
+		}
+		w.members[group][i] = updated
+		r.ok(updated)
+
+	case http.MethodDelete:
+		w.removeMember(group, memberKey)
+		r.noContent()
+
+	default:
+		r.methodNotAllowed()
+	}
+}
+
+func (w *Workspace) serveHasMember(r *request, key, memberKey string) {
+	g := w.findGroup(key)
+	if g == nil {
+		r.notFound("Resource Not Found: groupKey")
+		return
+	}
+	r.ok(&admin.MembersHasMember{IsMember: w.memberIndex(strings.ToLower(g.Email), memberKey) >= 0})
+}
+
+// Organizational units
+
+// orgUnitKey turns the path segments after orgunits/ into an OU path or ID
+func orgUnitKey(segments []string) string {
+	var parts []string
+	for _, s := range segments {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	key := strings.Join(parts, "/")
+	if strings.HasPrefix(key, "id:") {
+		return key
+	}
+	return "/" + key
+}
+
+func (w *Workspace) findOrgUnit(key string) *admin.OrgUnit {
+	if ou, ok := w.orgUnits[key]; ok {
+		return ou
+	}
+	for _, ou := range w.orgUnits {
+		if ou.OrgUnitId == key {
+			return ou
+		}
+	}
+	return nil
+}
+
+// validOrgUnit reports whether path is the root or an existing OU
+func (w *Workspace) validOrgUnit(path string) bool {
+	return path == "" || path == "/" || w.orgUnits[path] != nil
+}
+
+// joinOrgUnitPath joins a parent OU path and a name
+func joinOrgUnitPath(parent, name string) string {
+	return strings.TrimSuffix(parent, "/") + "/" + name
+}
+
+func (w *Workspace) putOrgUnit(ou *admin.OrgUnit) *admin.OrgUnit {
+	ou.Kind = "admin#directory#orgUnit"
+	if ou.OrgUnitId == "" {
+		ou.OrgUnitId = "id:" + w.newID()
+	}
+	if ou.ParentOrgUnitPath == "" {
+		ou.ParentOrgUnitPath = "/"
+	}
+	ou.OrgUnitPath = joinOrgUnitPath(ou.ParentOrgUnitPath, ou.Name)
+	if parent := w.orgUnits[ou.ParentOrgUnitPath]; parent != nil {
+		ou.ParentOrgUnitId = parent.OrgUnitId
+	}
+	w.orgUnits[ou.OrgUnitPath] = ou
+	return ou
+}
+
+func (w *Workspace) serveOrgUnits(r *request) {
+	switch r.Method {
+	case http.MethodGet:
+		parent := r.URL.Query().Get("orgUnitPath")
+		if parent == "" {
+			parent = "/"
+		} else if !strings.HasPrefix(parent, "/") && !strings.HasPrefix(parent, "id:") {
+			parent = "/" + parent
+		}
+		if parent != "/" {
+			ou := w.findOrgUnit(parent)
+			if ou == nil {
+				r.notFound("Org unit not found")
+				return
+			}
+			parent = ou.OrgUnitPath
+		}
+
+		listType := r.URL.Query().Get("type")
+		var units []*admin.OrgUnit
+		for _, k := range sortedKeys(w.orgUnits) {
+			ou := w.orgUnits[k]
+			switch {
+			case listType == "allIncludingParent" && (k == parent || strings.HasPrefix(k, strings.TrimSuffix(parent, "/")+"/")):
+			case listType == "all" && strings.HasPrefix(k, strings.TrimSuffix(parent, "/")+"/"):
+			case (listType == "" || listType == "children") && ou.ParentOrgUnitPath == parent:
+			default:
+				continue
+			}
+			units = append(units, ou)
+		}
+		r.ok(&admin.OrgUnits{Kind: "admin#directory#orgUnits", OrganizationUnits: units})
+
+	case http.MethodPost:
+		var ou admin.OrgUnit
+		if !r.decode(&ou) {
+			return
+		}
+		if ou.Name == "" {
+			r.badRequest("Invalid Input: name")
+			return
+		}
+		if ou.ParentOrgUnitPath == "" && ou.ParentOrgUnitId != "" {
+			if parent := w.findOrgUnit(ou.ParentOrgUnitId); parent != nil {
+				ou.ParentOrgUnitPath = parent.OrgUnitPath
+			}
+		}
+		if !w.validOrgUnit(ou.ParentOrgUnitPath) {
+			r.badRequest("Invalid Parent Orgunit Id")
+			return
+		}
+		if w.orgUnits[joinOrgUnitPath(ou.ParentOrgUnitPath, ou.Name)] != nil {
+			r.conflict("Invalid Ou Id")
+			return
+		}
+		ou.OrgUnitId = ""
+		r.ok(w.putOrgUnit(&ou))
+
+	default:
+		r.methodNotAllowed()
+	}
+}
+
+func (w *Workspace) serveOrgUnit(r *request, key string) {
+	ou := w.findOrgUnit(key)
+	if ou == nil {
+		r.notFound("Org unit not found")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		r.ok(ou)
+
+	case http.MethodPut, http.MethodPatch:
+		patch, ok := r.decodeMap()
+		if !ok {
+			return
+		}
+		updated, err := merge(ou, patch)
+		if err != nil {
+			r.badRequest(err.Error())
+			return
+		}
+		if !w.validOrgUnit(updated.ParentOrgUnitPath) {
+			r.badRequest("Invalid Parent Orgunit Id")
+			return
+		}
+		newPath := joinOrgUnitPath(updated.ParentOrgUnitPath, updated.Name)
+		if newPath != ou.OrgUnitPath && w.orgUnits[newPath] != nil {
+			r.conflict("Invalid Ou Id")
+			return
+		}
+		w.moveOrgUnit(ou.OrgUnitPath, newPath)
+		r.ok(w.putOrgUnit(updated))
+
+	case http.MethodDelete:
+		prefix := ou.OrgUnitPath + "/"
+		for k := range w.orgUnits {
+			if strings.HasPrefix(k, prefix) {
+				r.badRequest("Org unit has child org units")
+				return
+			}
+		}
+		for _, u := range w.users {
+			if u.OrgUnitPath == ou.OrgUnitPath {
+				r.badRequest("Org unit contains users")
+				return
+			}
+		}
+		delete(w.orgUnits, ou.OrgUnitPath)
+		r.noContent()
+
+	default:
+		r.methodNotAllowed()
+	}
+}
+
+// moveOrgUnit re-paths an OU's descendants and users after a rename or move
+func (w *Workspace) moveOrgUnit(oldPath, newPath string) {
+	delete(w.orgUnits, oldPath)
+	if oldPath == newPath {
+		return
+	}
+	rewrite := func(p string) string {
+		if p == oldPath {
+			return newPath
+		}
+		if strings.HasPrefix(p, oldPath+"/") {
+			return newPath + strings.TrimPrefix(p, oldPath)
+		}
+		return p
+	}
+
+	var children []*admin.OrgUnit
+	for k, ou := range w.orgUnits {
+		if strings.HasPrefix(k, oldPath+"/") {
+			delete(w.orgUnits, k)
+			children = append(children, ou)
+		}
+	}
+	sort.Slice(children, func(i, j int) bool { return children[i].OrgUnitPath < children[j].OrgUnitPath })
+	for _, ou := range children {
+		ou.ParentOrgUnitPath = rewrite(ou.ParentOrgUnitPath)
+		ou.OrgUnitPath = rewrite(ou.OrgUnitPath)
+		w.orgUnits[ou.OrgUnitPath] = ou
+	}
+	for _, u := range w.users {
+		u.OrgUnitPath = rewrite(u.OrgUnitPath)
+	}
+}
+
+// Calendar resources and buildings
+
+func (w *Workspace) putCalendarResource(res *admin.CalendarResource) *admin.CalendarResource {
+	res.Kind = "admin#directory#resources#calendars#CalendarResource"
+	if res.ResourceEmail == "" {
+		res.ResourceEmail = "c_" + w.newID() + "@resource.calendar.google.com"
+	}
+	if res.ResourceType == "" {
+		res.ResourceType = "OTHER"
+	}
+	w.resources[res.ResourceId] = res
+	return res
+}
+
+func (w *Workspace) serveCalendarResources(r *request) {
+	switch r.Method {
+	case http.MethodGet:
+		var items []*admin.CalendarResource
+		for _, k := range sortedKeys(w.resources) {
+			items = append(items, w.resources[k])
+		}
+		items, next, ok := page(r, items, w.PageSize)
+		if ok {
+			r.ok(&admin.CalendarResources{Kind: "admin#directory#resources#calendars#calendarResourcesList", Items: items, NextPageToken: next})
+		}
+
+	case http.MethodPost:
+		var res admin.CalendarResource
+		if !r.decode(&res) {
+			return
+		}
+		if res.ResourceId == "" || res.ResourceName == "" {
+			r.badRequest("Invalid Input: resourceId and resourceName are required")
+			return
+		}
+		if w.resources[res.ResourceId] != nil {
+			r.conflict("Entity already exists.")
+			return
+		}
+		if res.BuildingId != "" && w.buildings[res.BuildingId] == nil {
+			r.badRequest("Invalid Input: buildingId")
+			return
+		}
+		res.ResourceEmail = ""
+		r.ok(w.putCalendarResource(&res))
+
+	default:
+		r.methodNotAllowed()
+	}
+}
+
+func (w *Workspace) serveCalendarResource(r *request, id string) {
+	res := w.resources[id]
+	if res == nil {
+		r.notFound("Resource Not Found: calendarResourceId")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		r.ok(res)
+
+	case http.MethodPut, http.MethodPatch:
+		patch, ok := r.decodeMap()
+		if !ok {
+			return
+		}
+		updated, err := merge(res, patch)
+		if err != nil {
+			r.badRequest(err.Error())
+			return
+		}
+		updated.ResourceId, updated.ResourceEmail = res.ResourceId, res.ResourceEmail
+		if updated.BuildingId != "" && w.buildings[updated.BuildingId] == nil {
+			r.badRequest("Invalid Input: buildingId")
+			return
+		}
+		r.ok(w.putCalendarResource(updated))
+
+	case http.MethodDelete:
+		delete(w.resources, id)
+		r.noContent()
+
+	default:
+		r.methodNotAllowed()
+	}
+}
+
+func (w *Workspace) serveBuildings(r *request) {
+	switch r.Method {
+	case http.MethodGet:
+		var items []*admin.Building
+		for _, k := range sortedKeys(w.buildings) {
+			items = append(items, w.buildings[k])
+		}
+		items, next, ok := page(r, items, w.PageSize)
+		if ok {
+			r.ok(&admin.Buildings{Kind: "admin#directory#resources#buildings#buildingsList", Buildings: items, NextPageToken: next})
+		}
+
+	case http.MethodPost:
+		var b admin.Building
+		if !r.decode(&b) {
+			return
+		}
+		if b.BuildingId == "" {
+			r.badRequest("Invalid Input: buildingId")
+			return
+		}
+		if w.buildings[b.BuildingId] != nil {
+			r.conflict("Entity already exists.")
+			return
+		}
+		w.buildings[b.BuildingId] = &b
+		r.ok(&b)
+
+	default:
+		r.methodNotAllowed()
+	}
+}
+
+func (w *Workspace) serveBuilding(r *request, id string) {
+	b := w.buildings[id]
+	if b == nil {
+		r.notFound("Resource Not Found: buildingId")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		r.ok(b)
+
+	case http.MethodPut, http.MethodPatch:
+		patch, ok := r.decodeMap()
+		if !ok {
+			return
+		}
+		updated, err := merge(b, patch)
+		if err != nil {
+			r.badRequest(err.Error())
+			return
+		}
+		updated.BuildingId = id
+		w.buildings[id] = updated
+		r.ok(updated)
+
+	case http.MethodDelete:
+		for _, res := range w.resources {
+			if res.BuildingId == id {
+				r.badRequest("Building is in use by calendar resources")
+				return
+			}
+		}
+		delete(w.buildings, id)
+		r.noContent()
+
+	default:
+		r.methodNotAllowed()
+	}
+}
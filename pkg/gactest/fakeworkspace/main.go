@@ -0,0 +1,39 @@
+// Command fakeworkspace serves a gactest fake Workspace over HTTP, for
+// demos and manual testing without a tenant:
+//
+//	go run ./pkg/gactest/fakeworkspace --fixtures examples/fake-workspace.yaml
+//	gac --api-endpoint http://127.0.0.1:8080 --no-auth user list
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/acockrell/google-admin-client/pkg/gactest"
+)
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:8080", "address to listen on")
+	fixtures := flag.String("fixtures", "", "YAML fixture file to seed the workspace from")
+	pageSize := flag.Int("page-size", gactest.DefaultPageSize, "maximum items per page")
+	flag.Parse()
+
+	w := gactest.New()
+	w.PageSize = *pageSize
+	if *fixtures != "" {
+		if err := w.LoadFile(*fixtures); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	log.Printf("fake workspace listening on http://%s", *addr)
+	log.Printf("try: gac --api-endpoint http://%s --no-auth user list", *addr)
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           w,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	log.Fatal(srv.ListenAndServe())
+}
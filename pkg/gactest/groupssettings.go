@@ -0,0 +1,75 @@
+package gactest
+
+import (
+	"net/http"
+	"strings"
+
+	groupssettings "google.golang.org/api/groupssettings/v1"
+)
+
+// serveGroupSettings handles groups/v1/groups requests
+func (w *Workspace) serveGroupSettings(r *request, path []string) {
+	if len(path) != 1 {
+		r.notFound("Not Found")
+		return
+	}
+	g := w.findGroup(path[0])
+	if g == nil {
+		r.notFound("Resource Not Found: groupUniqueId")
+		return
+	}
+	key := strings.ToLower(g.Email)
+	settings := w.settingsFor(key)
+
+	switch r.Method {
+	case http.MethodGet:
+		r.ok(settings)
+
+	case http.MethodPut, http.MethodPatch:
+		patch, ok := r.decodeMap()
+		if !ok {
+			return
+		}
+		updated, err := merge(settings, patch)
+		if err != nil {
+			r.badRequest(err.Error())
+			return
+		}
+		updated.Email, updated.Kind = g.Email, settings.Kind
+		w.groupSettings[key] = updated
+		r.ok(updated)
+
+	default:
+		r.methodNotAllowed()
+	}
+}
+
+// settingsFor returns a group's settings, creating the defaults a new
+// Google group gets if none were stored
+func (w *Workspace) settingsFor(key string) *groupssettings.Groups {
+	if s, ok := w.groupSettings[key]; ok {
+		return s
+	}
+	g := w.groups[key]
+	s := &groupssettings.Groups{
+		Kind:                       "groupsSettings#groups",
+		Email:                      g.Email,
+		Name:                       g.Name,
+		Description:                g.Description,
+		WhoCanJoin:                 "CAN_REQUEST_TO_JOIN",
+		WhoCanViewMembership:       "ALL_MEMBERS_CAN_VIEW",
+		WhoCanViewGroup:            "ALL_MEMBERS_CAN_VIEW",
+		WhoCanPostMessage:          "ANYONE_CAN_POST",
+		AllowExternalMembers:       "false",
+		AllowWebPosting:            "true",
+		ArchiveOnly:                "false",
+		IsArchived:                 "false",
+		MessageModerationLevel:     "MODERATE_NONE",
+		ReplyTo:                    "REPLY_TO_IGNORE",
+		IncludeInGlobalAddressList: "true",
+		WhoCanContactOwner:         "ANYONE_CAN_CONTACT",
+		WhoCanModerateMembers:      "OWNERS_AND_MANAGERS",
+	}
+	w.groupSettings[key] = s
+	return s
+}
@@ -0,0 +1,82 @@
+package gactest
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	reports "google.golang.org/api/admin/reports/v1"
+)
+
+// serveReports handles admin/reports/v1 requests. Only the user activity
+// report is implemented.
+func (w *Workspace) serveReports(r *request, path []string) {
+	if len(path) != 5 || path[0] != "activity" || path[1] != "users" || path[3] != "applications" {
+		r.notFound("Not Found")
+		return
+	}
+	if r.Method != http.MethodGet {
+		r.methodNotAllowed()
+		return
+	}
+
+	userKey, app := path[2], path[4]
+	q := r.URL.Query()
+	start, ok := parseTime(r, "startTime")
+	if !ok {
+		return
+	}
+	end, ok := parseTime(r, "endTime")
+	if !ok {
+		return
+	}
+	eventName := q.Get("eventName")
+
+	var items []*reports.Activity
+	for _, a := range w.activities {
+		if a.Id == nil || !strings.EqualFold(a.Id.ApplicationName, app) {
+			continue
+		}
+		if userKey != "all" && (a.Actor == nil || !strings.EqualFold(a.Actor.Email, userKey)) {
+			continue
+		}
+		t, _ := time.Parse(time.RFC3339, a.Id.Time)
+		if (!start.IsZero() && t.Before(start)) || (!end.IsZero() && t.After(end)) {
+			continue
+		}
+		if eventName != "" && !hasEvent(a, eventName) {
+			continue
+		}
+		items = append(items, a)
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Id.Time > items[j].Id.Time })
+
+	items, next, ok := page(r, items, w.PageSize)
+	if ok {
+		r.ok(&reports.Activities{Kind: "admin#reports#activities", Items: items, NextPageToken: next})
+	}
+}
+
+// parseTime reads an optional RFC 3339 query parameter
+func parseTime(r *request, name string) (time.Time, bool) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return time.Time{}, true
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		r.badRequest("Invalid value for: " + name)
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+func hasEvent(a *reports.Activity, name string) bool {
+	for _, e := range a.Events {
+		if e.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,291 @@
+// Package gactest provides an in-memory fake of the Google Workspace APIs gac
+// uses: Directory, Groups Settings, Reports, Calendar and Data Transfer.
+//
+// A Workspace keeps its state in memory, so writes are visible to later
+// reads and multi-step flows behave like they do against a real tenant.
+// List endpoints honour maxResults and return page tokens. Errors use the
+// Google JSON error format, so client libraries surface them as
+// *googleapi.Error.
+//
+//	srv := gactest.NewServer(nil)
+//	defer srv.Close()
+//	if err := srv.LoadFile("testdata/workspace.yaml"); err != nil {
+//		t.Fatal(err)
+//	}
+//	// gac --api-endpoint srv.URL --no-auth user list
+//
+// Customer IDs are accepted but ignored: a Workspace is a single tenant.
+package gactest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	datatransfer "google.golang.org/api/admin/datatransfer/v1"
+	admin "google.golang.org/api/admin/directory/v1"
+	reports "google.golang.org/api/admin/reports/v1"
+	calendar "google.golang.org/api/calendar/v3"
+	groupssettings "google.golang.org/api/groupssettings/v1"
+)
+
+// DefaultPageSize is the page size used when a request sets no maxResults
+const DefaultPageSize = 100
+
+// Workspace is an in-memory Google Workspace tenant served over HTTP. The
+// zero value is not usable; create one with New.
+type Workspace struct {
+	mu sync.Mutex
+
+	// PageSize caps the items in each page, even if a request asks for
+	// more. Set it low to exercise pagination.
+	PageSize int
+
+	// Now returns the current time for generated timestamps
+	Now func() time.Time
+
+	users         map[string]*admin.User
+	groups        map[string]*admin.Group
+	members       map[string][]*admin.Member
+	groupSettings map[string]*groupssettings.Groups
+	orgUnits      map[string]*admin.OrgUnit
+	resources     map[string]*admin.CalendarResource
+	buildings     map[string]*admin.Building
+	events        map[string][]*calendar.Event
+	activities    []*reports.Activity
+	applications  []*datatransfer.Application
+	transfers     []*datatransfer.DataTransfer
+
+	requests []string
+	nextID   int
+}
+
+// New returns an empty Workspace
+func New() *Workspace {
+	return &Workspace{
+		PageSize:      DefaultPageSize,
+		Now:           time.Now,
+		users:         map[string]*admin.User{},
+		groups:        map[string]*admin.Group{},
+		members:       map[string][]*admin.Member{},
+		groupSettings: map[string]*groupssettings.Groups{},
+		orgUnits:      map[string]*admin.OrgUnit{},
+		resources:     map[string]*admin.CalendarResource{},
+		buildings:     map[string]*admin.Building{},
+		events:        map[string][]*calendar.Event{},
+	}
+}
+
+// Server is a Workspace listening on a local httptest server
+type Server struct {
+	*Workspace
+	*httptest.Server
+}
+
+// NewServer starts a server for w, or for an empty Workspace if w is nil.
+// Callers must Close it.
+func NewServer(w *Workspace) *Server {
+	if w == nil {
+		w = New()
+	}
+	return &Server{Workspace: w, Server: httptest.NewServer(w)}
+}
+
+// Requests returns "METHOD path" for every request served so far
+func (w *Workspace) Requests() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string(nil), w.requests...)
+}
+
+// ServeHTTP routes a request to the fake API it belongs to
+func (w *Workspace) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.requests = append(w.requests, r.Method+" "+r.URL.Path)
+
+	// Split the escaped path so encoded slashes stay inside a segment
+	segments := strings.Split(strings.Trim(r.URL.EscapedPath(), "/"), "/")
+	for i, s := range segments {
+		if u, err := url.PathUnescape(s); err == nil {
+			segments[i] = u
+		}
+	}
+
+	req := &request{Request: r, w: rw}
+	switch {
+	case hasPrefix(segments, "admin", "directory", "v1"):
+		w.serveDirectory(req, segments[3:])
+	case hasPrefix(segments, "groups", "v1", "groups"):
+		w.serveGroupSettings(req, segments[3:])
+	case hasPrefix(segments, "admin", "reports", "v1"):
+		w.serveReports(req, segments[3:])
+	case hasPrefix(segments, "calendar", "v3"):
+		w.serveCalendar(req, segments[2:])
+	case hasPrefix(segments, "admin", "datatransfer", "v1"):
+		w.serveDataTransfer(req, segments[3:])
+	default:
+		req.notFound("Not Found")
+	}
+}
+
+func hasPrefix(segments []string, prefix ...string) bool {
+	if len(segments) < len(prefix) {
+		return false
+	}
+	for i, p := range prefix {
+		if segments[i] != p {
+			return false
+		}
+	}
+	return true
+}
+
+// request wraps one API call with response helpers
+type request struct {
+	*http.Request
+	w http.ResponseWriter
+}
+
+// decode reads the JSON request body into v
+func (r *request) decode(v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		r.error(http.StatusBadRequest, "invalid", fmt.Sprintf("Invalid JSON payload received. %v", err))
+		return false
+	}
+	return true
+}
+
+// decodeMap reads the JSON request body as an object, for patch semantics
+func (r *request) decodeMap() (map[string]interface{}, bool) {
+	var m map[string]interface{}
+	if !r.decode(&m) {
+		return nil, false
+	}
+	return m, true
+}
+
+func (r *request) json(status int, v interface{}) {
+	r.w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	r.w.WriteHeader(status)
+	_ = json.NewEncoder(r.w).Encode(v)
+}
+
+func (r *request) ok(v interface{}) {
+	r.json(http.StatusOK, v)
+}
+
+func (r *request) noContent() {
+	r.w.WriteHeader(http.StatusNoContent)
+}
+
+// error writes an error in the format googleapi.CheckResponse parses
+func (r *request) error(status int, reason, message string) {
+	r.json(status, map[string]interface{}{
+		"error": map[string]interface{}{
+			"code":    status,
+			"message": message,
+			"errors": []map[string]string{{
+				"domain":  "global",
+				"reason":  reason,
+				"message": message,
+			}},
+		},
+	})
+}
+
+func (r *request) notFound(message string) {
+	r.error(http.StatusNotFound, "notFound", message)
+}
+
+func (r *request) conflict(message string) {
+	r.error(http.StatusConflict, "duplicate", message)
+}
+
+func (r *request) badRequest(message string) {
+	r.error(http.StatusBadRequest, "invalid", message)
+}
+
+func (r *request) methodNotAllowed() {
+	r.error(http.StatusMethodNotAllowed, "httpMethodNotAllowed", fmt.Sprintf("Method %s not allowed", r.Method))
+}
+
+// page returns the requested page of items and the token of the next one
+func page[T any](r *request, items []T, limit int) ([]T, string, bool) {
+	size := limit
+	if size <= 0 {
+		size = DefaultPageSize
+	}
+	if n, err := strconv.Atoi(r.URL.Query().Get("maxResults")); err == nil && n > 0 && n < size {
+		size = n
+	}
+
+	start := 0
+	if token := r.URL.Query().Get("pageToken"); token != "" {
+		n, err := strconv.Atoi(token)
+		if err != nil || n < 0 || n > len(items) {
+			r.badRequest("Invalid page token")
+			return nil, "", false
+		}
+		start = n
+	}
+
+	end := start + size
+	if end >= len(items) {
+		return items[start:], "", true
+	}
+	return items[start:end], strconv.Itoa(end), true
+}
+
+// convert copies v into out through JSON, the way the API would
+func convert(v, out interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, out)
+}
+
+// merge applies a patch to a stored resource: present fields replace the
+// stored ones and null fields clear them
+func merge[T any](existing *T, patch map[string]interface{}) (*T, error) {
+	var m map[string]interface{}
+	if err := convert(existing, &m); err != nil {
+		return nil, err
+	}
+	for k, v := range patch {
+		if v == nil {
+			delete(m, k)
+			continue
+		}
+		m[k] = v
+	}
+	out := new(T)
+	if err := convert(m, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// newID returns a numeric ID like the ones Google assigns
+func (w *Workspace) newID() string {
+	w.nextID++
+	return fmt.Sprintf("1%017d", w.nextID)
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,367 @@
+package gactest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	datatransfer "google.golang.org/api/admin/datatransfer/v1"
+	admin "google.golang.org/api/admin/directory/v1"
+	reports "google.golang.org/api/admin/reports/v1"
+	calendar "google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+	groupssettings "google.golang.org/api/groupssettings/v1"
+	"google.golang.org/api/option"
+)
+
+// newTestServer starts a server seeded from testdata/workspace.yaml
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	srv := NewServer(nil)
+	t.Cleanup(srv.Close)
+	srv.Now = func() time.Time { return time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC) }
+	if err := srv.LoadFile("testdata/workspace.yaml"); err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	return srv
+}
+
+// clientOptions points a Google API client at the server, the way gac does
+// with --api-endpoint and --no-auth
+func clientOptions(srv *Server, path string) []option.ClientOption {
+	return []option.ClientOption{
+		option.WithEndpoint(srv.URL + "/" + path),
+		option.WithHTTPClient(http.DefaultClient),
+	}
+}
+
+func newAdmin(t *testing.T, srv *Server) *admin.Service {
+	t.Helper()
+	svc, err := admin.NewService(context.Background(), clientOptions(srv, "")...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return svc
+}
+
+func statusCode(err error) int {
+	var gerr *googleapi.Error
+	if errors.As(err, &gerr) {
+		return gerr.Code
+	}
+	return 0
+}
+
+func TestUsers(t *testing.T) {
+	srv := newTestServer(t)
+	svc := newAdmin(t, srv)
+
+	u, err := svc.Users.Get("asmith@example.com").Do()
+	if err != nil {
+		t.Fatalf("Get(alias) error = %v", err)
+	}
+	if u.PrimaryEmail != "alice@example.com" || u.Id == "" || u.Name.FullName != "Alice Smith" {
+		t.Errorf("Get(alias) = %s/%s/%+v, want alice with an ID and full name", u.PrimaryEmail, u.Id, u.Name)
+	}
+
+	_, err = svc.Users.Get("nobody@example.com").Do()
+	if statusCode(err) != http.StatusNotFound {
+		t.Errorf("Get(missing) error = %v, want a 404 googleapi.Error", err)
+	}
+
+	created, err := svc.Users.Insert(&admin.User{
+		PrimaryEmail: "dave@example.com",
+		Name:         &admin.UserName{GivenName: "Dave", FamilyName: "Brown"},
+		Password:     "secret",
+	}).Do()
+	if err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+	if created.Password != "" || created.OrgUnitPath != "/" || created.CreationTime != "2026-01-10T12:00:00Z" {
+		t.Errorf("Insert() = %+v, want no password, root OU and creation time", created)
+	}
+	if _, err := svc.Users.Insert(&admin.User{PrimaryEmail: "asmith@example.com"}).Do(); statusCode(err) != http.StatusConflict {
+		t.Errorf("Insert(alias in use) error = %v, want 409", err)
+	}
+	if _, err := svc.Users.Insert(&admin.User{PrimaryEmail: "eve@example.com", OrgUnitPath: "/Nowhere"}).Do(); statusCode(err) != http.StatusBadRequest {
+		t.Errorf("Insert(unknown OU) error = %v, want 400", err)
+	}
+
+	// Suspending with a patch keeps the other fields
+	if _, err := svc.Users.Patch("bob@example.com", &admin.User{Suspended: true}).Do(); err != nil {
+		t.Fatalf("Patch() error = %v", err)
+	}
+	u, _ = svc.Users.Get("bob@example.com").Do()
+	if !u.Suspended || u.OrgUnitPath != "/Engineering/Backend" {
+		t.Errorf("after Patch() user = suspended %v in %s, want suspended in /Engineering/Backend", u.Suspended, u.OrgUnitPath)
+	}
+
+	if err := svc.Users.Delete("bob@example.com").Do(); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	has, err := svc.Members.HasMember("engineering@example.com", "bob@example.com").Do()
+	if err != nil || has.IsMember {
+		t.Errorf("HasMember() after Delete() = %v, %v, want false", has, err)
+	}
+}
+
+func TestPagination(t *testing.T) {
+	srv := newTestServer(t)
+	srv.PageSize = 2
+	svc := newAdmin(t, srv)
+
+	var emails []string
+	pages := 0
+	err := svc.Users.List().Customer("my_customer").Pages(context.Background(), func(users *admin.Users) error {
+		pages++
+		for _, u := range users.Users {
+			emails = append(emails, u.PrimaryEmail)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Pages() error = %v", err)
+	}
+	want := []string{"alice@example.com", "bob@example.com", "carol@example.com"}
+	if pages != 2 || len(emails) != len(want) {
+		t.Fatalf("listed %v in %d pages, want %v in 2", emails, pages, want)
+	}
+	for i := range want {
+		if emails[i] != want[i] {
+			t.Errorf("user %d = %s, want %s", i, emails[i], want[i])
+		}
+	}
+
+	// maxResults lowers the page size further
+	users, err := svc.Users.List().Customer("my_customer").MaxResults(1).Do()
+	if err != nil || len(users.Users) != 1 || users.NextPageToken == "" {
+		t.Errorf("List(maxResults=1) = %v, %v, want one user and a next page", users, err)
+	}
+
+	if _, err := svc.Users.List().Customer("my_customer").PageToken("bogus").Do(); statusCode(err) != http.StatusBadRequest {
+		t.Errorf("List(bad token) error = %v, want 400", err)
+	}
+}
+
+func TestGroupsAndMembers(t *testing.T) {
+	srv := newTestServer(t)
+	svc := newAdmin(t, srv)
+
+	groups, err := svc.Groups.List().UserKey("carol@example.com").Do()
+	if err != nil || len(groups.Groups) != 1 || groups.Groups[0].Email != "all@example.com" {
+		t.Errorf("List(userKey) = %v, %v, want all@example.com", groups, err)
+	}
+
+	members, err := svc.Members.List("all@example.com").Do()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members.Members) != 2 || members.Members[0].Type != "GROUP" || members.Members[1].Type != "USER" {
+		t.Errorf("List() = %+v, want a GROUP and a USER member", members.Members)
+	}
+
+	owners, _ := svc.Members.List("engineering@example.com").Roles("OWNER").Do()
+	if len(owners.Members) != 1 || owners.Members[0].Email != "alice@example.com" {
+		t.Errorf("List(roles=OWNER) = %+v, want alice", owners.Members)
+	}
+
+	if _, err := svc.Members.Insert("engineering@example.com", &admin.Member{Email: "alice@example.com"}).Do(); statusCode(err) != http.StatusConflict {
+		t.Errorf("Insert(existing member) error = %v, want 409", err)
+	}
+	if err := svc.Members.Delete("engineering@example.com", "alice@example.com").Do(); err != nil {
+		t.Fatal(err)
+	}
+	g, _ := svc.Groups.Get("engineering@example.com").Do()
+	if g.DirectMembersCount != 1 {
+		t.Errorf("DirectMembersCount = %d, want 1", g.DirectMembersCount)
+	}
+}
+
+func TestOrgUnits(t *testing.T) {
+	srv := newTestServer(t)
+	svc := newAdmin(t, srv)
+
+	tests := []struct {
+		name     string
+		path     string
+		listType string
+		want     []string
+	}{
+		{name: "root children", listType: "children", want: []string{"/Engineering", "/Sales"}},
+		{name: "all", listType: "all", want: []string{"/Engineering", "/Engineering/Backend", "/Sales"}},
+		{name: "subtree", path: "/Engineering", listType: "allIncludingParent", want: []string{"/Engineering", "/Engineering/Backend"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			call := svc.Orgunits.List("my_customer").Type(tt.listType)
+			if tt.path != "" {
+				call = call.OrgUnitPath(tt.path)
+			}
+			ous, err := call.Do()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, ou := range ous.OrganizationUnits {
+				got = append(got, ou.OrgUnitPath)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("List() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("List()[%d] = %s, want %s", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+
+	// Renaming an OU moves its children and users
+	if _, err := svc.Orgunits.Patch("my_customer", "/Engineering", &admin.OrgUnit{Name: "Eng"}).Do(); err != nil {
+		t.Fatalf("Patch() error = %v", err)
+	}
+	ou, err := svc.Orgunits.Get("my_customer", "/Eng/Backend").Do()
+	if err != nil || ou.ParentOrgUnitPath != "/Eng" {
+		t.Errorf("Get(moved child) = %v, %v, want parent /Eng", ou, err)
+	}
+	u, _ := svc.Users.Get("bob@example.com").Do()
+	if u.OrgUnitPath != "/Eng/Backend" {
+		t.Errorf("user OU = %s, want /Eng/Backend", u.OrgUnitPath)
+	}
+
+	if err := svc.Orgunits.Delete("my_customer", "/Eng").Do(); statusCode(err) != http.StatusBadRequest {
+		t.Errorf("Delete(OU with children) error = %v, want 400", err)
+	}
+	if _, err := svc.Orgunits.Insert("my_customer", &admin.OrgUnit{Name: "X", ParentOrgUnitPath: "/Missing"}).Do(); statusCode(err) != http.StatusBadRequest {
+		t.Errorf("Insert(unknown parent) error = %v, want 400", err)
+	}
+}
+
+func TestResourcesAndSettings(t *testing.T) {
+	srv := newTestServer(t)
+	svc := newAdmin(t, srv)
+
+	res, err := svc.Resources.Calendars.Get("my_customer", "room-1").Do()
+	if err != nil || res.ResourceEmail == "" || res.Capacity != 12 {
+		t.Errorf("Get() = %+v, %v, want a resource email and capacity 12", res, err)
+	}
+	if _, err := svc.Resources.Calendars.Insert("my_customer", &admin.CalendarResource{ResourceId: "room-2", ResourceName: "Annex", BuildingId: "nope"}).Do(); statusCode(err) != http.StatusBadRequest {
+		t.Errorf("Insert(unknown building) error = %v, want 400", err)
+	}
+
+	settings, err := groupssettings.NewService(context.Background(), clientOptions(srv, "groups/v1/groups/")...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := settings.Groups.Get("engineering@example.com").Do()
+	if err != nil || s.WhoCanJoin != "INVITED_CAN_JOIN" {
+		t.Errorf("Get(seeded) = %v, %v, want INVITED_CAN_JOIN", s, err)
+	}
+	s, err = settings.Groups.Patch("all@example.com", &groupssettings.Groups{WhoCanPostMessage: "ALL_MEMBERS_CAN_POST"}).Do()
+	if err != nil || s.WhoCanPostMessage != "ALL_MEMBERS_CAN_POST" || s.WhoCanJoin != "CAN_REQUEST_TO_JOIN" {
+		t.Errorf("Patch(defaults) = %v, %v, want the patch applied over defaults", s, err)
+	}
+}
+
+func TestReports(t *testing.T) {
+	srv := newTestServer(t)
+	svc, err := reports.NewService(context.Background(), clientOptions(srv, "")...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := svc.Activities.List("all", "login").Do()
+	if err != nil || len(all.Items) != 2 || all.Items[0].Actor.Email != "bob@example.com" {
+		t.Errorf("List(all) = %v, %v, want two login events, newest first", all, err)
+	}
+
+	alice, _ := svc.Activities.List("alice@example.com", "login").Do()
+	if len(alice.Items) != 1 {
+		t.Errorf("List(alice) returned %d events, want 1", len(alice.Items))
+	}
+
+	failures, _ := svc.Activities.List("all", "login").EventName("login_failure").StartTime("2026-01-06T00:00:00Z").Do()
+	if len(failures.Items) != 1 {
+		t.Errorf("List(eventName, startTime) returned %d events, want 1", len(failures.Items))
+	}
+}
+
+func TestCalendarEvents(t *testing.T) {
+	srv := newTestServer(t)
+	svc, err := calendar.NewService(context.Background(), clientOptions(srv, "calendar/v3/")...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := svc.Events.List("alice@example.com").TimeMin("2026-01-05T00:00:00Z").Do()
+	if err != nil || len(events.Items) != 2 || events.Items[0].Summary != "Standup" {
+		t.Fatalf("List() = %v, %v, want Standup then Planning", events, err)
+	}
+	afternoon, _ := svc.Events.List("alice@example.com").TimeMin("2026-01-05T12:00:00Z").Do()
+	if len(afternoon.Items) != 1 {
+		t.Errorf("List(timeMin) returned %d events, want 1", len(afternoon.Items))
+	}
+
+	e, err := svc.Events.Insert("primary", &calendar.Event{
+		Summary: "Review",
+		Start:   &calendar.EventDateTime{Date: "2026-01-07"},
+		End:     &calendar.EventDateTime{Date: "2026-01-08"},
+	}).Do()
+	if err != nil || e.Id == "" {
+		t.Fatalf("Insert() = %v, %v, want an event with an ID", e, err)
+	}
+	if _, err := svc.Events.Patch("primary", e.Id, &calendar.Event{Location: "Room 1"}).Do(); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := svc.Events.Get("primary", e.Id).Do()
+	if got.Location != "Room 1" || got.Summary != "Review" {
+		t.Errorf("Get() after Patch() = %+v, want location added and summary kept", got)
+	}
+}
+
+func TestDataTransfer(t *testing.T) {
+	srv := newTestServer(t)
+	svc, err := datatransfer.NewService(context.Background(), clientOptions(srv, "")...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	apps, err := svc.Applications.List().Do()
+	if err != nil || len(apps.Applications) != 1 || apps.Applications[0].Name != "Drive and Docs" {
+		t.Fatalf("Applications.List() = %v, %v", apps, err)
+	}
+
+	tr, err := svc.Transfers.Insert(&datatransfer.DataTransfer{
+		OldOwnerUserId:           "1",
+		NewOwnerUserId:           "2",
+		ApplicationDataTransfers: []*datatransfer.ApplicationDataTransfer{{ApplicationId: apps.Applications[0].Id}},
+	}).Do()
+	if err != nil || tr.OverallTransferStatusCode != "completed" {
+		t.Fatalf("Transfers.Insert() = %v, %v, want a completed transfer", tr, err)
+	}
+	got, err := svc.Transfers.Get(tr.Id).Do()
+	if err != nil || got.NewOwnerUserId != "2" {
+		t.Errorf("Transfers.Get() = %v, %v", got, err)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+	}{
+		{name: "invalid yaml", yaml: "users: ["},
+		{name: "user without email", yaml: "users:\n  - name: {givenName: X}"},
+		{name: "unknown org unit", yaml: "users:\n  - primaryEmail: x@example.com\n    orgUnitPath: /Missing"},
+		{name: "unknown parent", yaml: "orgUnits:\n  - name: Child\n    parentOrgUnitPath: /Missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := New().Load([]byte(tt.yaml)); err == nil {
+				t.Error("Load() succeeded, want error")
+			}
+		})
+	}
+}